@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// expandsAnnotation holds the command lines a user command runs, one per line.
+const expandsAnnotation = "craft:expands"
+
+// maxUserCommandDepth limits aliases and macros that call each other.
+const maxUserCommandDepth = 8
+
+var (
+	userCommandNameRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+	placeholderRe     = regexp.MustCompile(`\$(@|[1-9])`)
+
+	// userCommands are the alias and macro commands added from the config.
+	userCommands []*cobra.Command
+	// userCommandDepth counts the user commands currently running.
+	userCommandDepth int
+)
+
+// userCommand is an alias or a macro from the config file.
+type userCommand struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // alias or macro
+	Description string   `json:"description,omitempty"`
+	Steps       []string `json:"steps"`
+}
+
+// configUserCommands returns the aliases and macros of cfg, sorted by name.
+func configUserCommands(cfg *config.Config) []userCommand {
+	var ucs []userCommand
+	for name, expansion := range cfg.Aliases {
+		ucs = append(ucs, userCommand{Name: name, Type: "alias", Steps: []string{expansion}})
+	}
+	for name, macro := range cfg.Macros {
+		ucs = append(ucs, userCommand{Name: name, Type: "macro", Description: macro.Description, Steps: macro.Steps})
+	}
+	sort.Slice(ucs, func(i, j int) bool { return ucs[i].Name < ucs[j].Name })
+	return ucs
+}
+
+// registerUserCommands adds the aliases and macros of cfg as commands, so
+// they show up in help, 'craft schema' and shell completion. Names taken by
+// built-in commands are skipped.
+func registerUserCommands(cfg *config.Config) {
+	rootCmd.RemoveCommand(userCommands...)
+	userCommands = nil
+	for _, uc := range configUserCommands(cfg) {
+		if !userCommandNameRe.MatchString(uc.Name) || isBuiltinCommand(uc.Name) {
+			continue
+		}
+		c := newUserCommand(uc)
+		rootCmd.AddCommand(c)
+		userCommands = append(userCommands, c)
+	}
+}
+
+// isBuiltinCommand reports whether name is taken by a built-in command.
+func isBuiltinCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Annotations[expandsAnnotation] == "" && (c.Name() == name || c.HasAlias(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func newUserCommand(uc userCommand) *cobra.Command {
+	short := uc.Description
+	if short == "" && uc.Type == "alias" {
+		short = "Alias for: craft " + uc.Steps[0]
+	} else if short == "" {
+		short = fmt.Sprintf("Macro of %d steps", len(uc.Steps))
+	}
+	var long strings.Builder
+	fmt.Fprintf(&long, "%s\n\nRuns:\n", short)
+	for _, step := range uc.Steps {
+		fmt.Fprintf(&long, "  craft %s\n", step)
+	}
+	if uc.Type == "alias" {
+		long.WriteString("\nArguments are appended to the command unless it uses $1..$9 or $@.")
+	} else {
+		long.WriteString("\nArguments replace $1..$9 and $@ in the steps. Steps stop at the first error.")
+	}
+
+	return &cobra.Command{
+		Use:                uc.Name + " [args...]",
+		Short:              short,
+		Long:               long.String(),
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{expandsAnnotation: strings.Join(uc.Steps, "\n")},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUserCommand(cmd, uc, args)
+		},
+	}
+}
+
+// runUserCommand runs the steps of uc in this process. Global flags given to
+// the alias or macro apply to every step.
+func runUserCommand(cmd *cobra.Command, uc userCommand, args []string) error {
+	globals, rest := splitGlobalFlags(args)
+	if len(rest) > 0 && (rest[0] == "--help" || rest[0] == "-h") {
+		return cmd.Help()
+	}
+	steps, err := expandSteps(uc, rest)
+	if err != nil {
+		return err
+	}
+	if userCommandDepth >= maxUserCommandDepth {
+		return fmt.Errorf("%s: aliases and macros nest more than %d deep (does one call itself?)", uc.Name, maxUserCommandDepth)
+	}
+	userCommandDepth++
+	defer func() { userCommandDepth-- }()
+
+	for i, step := range steps {
+		if err := execInProcess(append(append([]string{}, globals...), step...)); err != nil {
+			if len(steps) == 1 {
+				return err
+			}
+			return fmt.Errorf("%s step %d (craft %s): %w", uc.Name, i+1, strings.Join(step, " "), err)
+		}
+	}
+	return nil
+}
+
+// expandSteps splits the steps of uc into arguments and substitutes args
+// for $1..$9 and $@. An alias that uses no placeholders gets args appended.
+func expandSteps(uc userCommand, args []string) ([][]string, error) {
+	var steps [][]string
+	used, all := 0, false
+	for _, line := range uc.Steps {
+		tokens, err := splitCommandLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", uc.Type, uc.Name, err)
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%s %s has an empty step", uc.Type, uc.Name)
+		}
+		var step []string
+		for _, tok := range tokens {
+			if tok == "$@" {
+				step = append(step, args...)
+				all = true
+				continue
+			}
+			step = append(step, placeholderRe.ReplaceAllStringFunc(tok, func(p string) string {
+				if p == "$@" {
+					all = true
+					return strings.Join(args, " ")
+				}
+				n := int(p[1] - '0')
+				used = max(used, n)
+				if n > len(args) {
+					return ""
+				}
+				return args[n-1]
+			}))
+		}
+		steps = append(steps, step)
+	}
+
+	switch {
+	case used > len(args):
+		return nil, fmt.Errorf("%s needs %d argument(s), got %d", uc.Name, used, len(args))
+	case all:
+	case used == 0 && uc.Type == "alias":
+		steps[0] = append(steps[0], args...)
+	case len(args) > used:
+		return nil, fmt.Errorf("%s takes %d argument(s), got %d", uc.Name, used, len(args))
+	}
+	return steps, nil
+}
+
+// splitGlobalFlags separates root persistent flags (and their values) from
+// the other arguments of a command that does not parse its own flags.
+func splitGlobalFlags(args []string) (globals, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return globals, append(rest, args[i:]...)
+		}
+		var name string
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name, _, _ = strings.Cut(arg[2:], "=")
+		case len(arg) == 2 && arg[0] == '-':
+			if f := rootCmd.PersistentFlags().ShorthandLookup(arg[1:]); f != nil {
+				name = f.Name
+			}
+		}
+		f := rootCmd.PersistentFlags().Lookup(name)
+		if f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+		globals = append(globals, arg)
+		if !strings.Contains(arg, "=") && f.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			globals = append(globals, args[i])
+		}
+	}
+	return globals, rest
+}
+
+// splitCommandLine splits a command line into arguments the way a POSIX
+// shell would, honoring single quotes, double quotes and backslashes. It
+// performs no other expansion.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage command aliases and macros",
+	Long: `Define your own craft commands.
+
+An alias names a craft command line. Arguments given to the alias are
+appended to it:
+
+  craft alias set standup 'blocks add --date today --markdown'
+  craft standup "Shipped the importer"
+
+A macro runs several command lines in order, stopping at the first error.
+Macros are defined in the config file; their steps refer to arguments as
+$1..$9, or to all of them as $@:
+
+  "macros": {
+    "sprint": {
+      "description": "Start a sprint page and plan its first task",
+      "steps": [
+        "create --title 'Sprint $1' --folder f1",
+        "tasks add 'Plan sprint $1' --location inbox"
+      ]
+    }
+  }
+
+Aliases may use the same placeholders. Both run in-process, show up in help,
+'craft schema' (with the commands they expand to) and shell completion.
+Names of built-in commands cannot be used.`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <command line>",
+	Short: "Add or replace an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, expansion := args[0], strings.TrimSpace(args[1])
+		if !userCommandNameRe.MatchString(name) {
+			return fmt.Errorf("invalid alias name %q (use lowercase letters, digits and dashes)", name)
+		}
+		if isBuiltinCommand(name) {
+			return fmt.Errorf("'%s' is a built-in command", name)
+		}
+		tokens, err := splitCommandLine(strings.TrimPrefix(expansion, "craft "))
+		if err != nil {
+			return err
+		}
+		if target, _, err := rootCmd.Find(tokens); err != nil || target == rootCmd {
+			return fmt.Errorf("'%s' does not start with a craft command", expansion)
+		}
+		if err := cfgManager.SetAlias(name, strings.TrimPrefix(expansion, "craft ")); err != nil {
+			return fmt.Errorf("failed to set alias: %w", err)
+		}
+		fmt.Printf("Alias '%s' set\n", name)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases and macros",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := cfgManager.Load()
+		if err != nil {
+			return err
+		}
+		ucs := configUserCommands(cfg)
+		if isJSONFormat(getOutputFormat()) {
+			if ucs == nil {
+				ucs = []userCommand{}
+			}
+			return outputJSON(ucs)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !hasNoHeaders() {
+			fmt.Fprintln(w, "NAME\tTYPE\tRUNS")
+			fmt.Fprintln(w, "----\t----\t----")
+		}
+		for _, uc := range ucs {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", uc.Name, uc.Type, strings.Join(uc.Steps, "; "))
+		}
+		return w.Flush()
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an alias",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfgManager.RemoveAlias(args[0]); err != nil {
+			return fmt.Errorf("failed to remove alias: %w", err)
+		}
+		fmt.Printf("Alias '%s' removed\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"blocks add --date today", []string{"blocks", "add", "--date", "today"}},
+		{`create --title "Sprint $1"  --markdown 'it''s'`, []string{"create", "--title", "Sprint $1", "--markdown", "its"}},
+		{`search a\ b ""`, []string{"search", "a b", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.line)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommandLine(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := splitCommandLine(`create --title "open`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestExpandSteps(t *testing.T) {
+	alias := userCommand{Name: "standup", Type: "alias", Steps: []string{"blocks add --date today --markdown"}}
+	macro := userCommand{Name: "sprint", Type: "macro", Steps: []string{"create --title 'Sprint $1'", "search $2"}}
+	tests := []struct {
+		uc      userCommand
+		args    []string
+		want    [][]string
+		wantErr string
+	}{
+		{alias, []string{"did X"}, [][]string{{"blocks", "add", "--date", "today", "--markdown", "did X"}}, ""},
+		{macro, []string{"12", "goals"}, [][]string{{"create", "--title", "Sprint 12"}, {"search", "goals"}}, ""},
+		{macro, []string{"12"}, nil, "needs 2 argument(s), got 1"},
+		{macro, []string{"12", "goals", "extra"}, nil, "takes 2 argument(s), got 3"},
+		{userCommand{Name: "s", Type: "macro", Steps: []string{"search $@"}}, []string{"a", "b"}, [][]string{{"search", "a", "b"}}, ""},
+	}
+	for _, tt := range tests {
+		got, err := expandSteps(tt.uc, tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s %q: error = %v, want %q", tt.uc.Name, tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q = %q, %v; want %q", tt.uc.Name, tt.args, got, err, tt.want)
+		}
+	}
+}
+
+// useUserCommands registers the aliases and macros of the current config
+// until the test ends.
+func useUserCommands(t *testing.T) {
+	t.Helper()
+	cfg, err := cfgManager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerUserCommands(cfg)
+	t.Cleanup(func() { registerUserCommands(&config.Config{}) })
+}
+
+func TestAliasesAndMacrosRunInProcess(t *testing.T) {
+	useTempConfig(t)
+	ps := &policyServer{}
+	server := httptest.NewServer(ps)
+	defer server.Close()
+	if err := cfgManager.AddProfile("work", server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runInProcess([]string{"alias", "set", "list", "search x"}, ""); err == nil {
+		t.Error("expected an error for a built-in command name")
+	}
+	if _, err := runInProcess([]string{"alias", "set", "oops", "frobnicate now"}, ""); err == nil {
+		t.Error("expected an error for an unknown target command")
+	}
+	if _, err := runInProcess([]string{"alias", "set", "standup", "blocks add --date today --markdown"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := cfgManager.Load()
+	cfg.Macros = map[string]config.Macro{"sprint": {
+		Description: "Start a sprint",
+		Steps:       []string{"create --title 'Sprint $1'", "standup 'Started sprint $1'"},
+	}}
+	cfg.Aliases["loop"] = "loop"
+	if err := cfgManager.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	useUserCommands(t)
+
+	if _, err := runInProcess([]string{"standup", "did X"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runInProcess([]string{"--dry-run", "standup", "did Y"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runInProcess([]string{"sprint", "12"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(ps.mutations, ", "); got != "POST /blocks, POST /documents, POST /blocks" {
+		t.Errorf("mutations = %s", got)
+	}
+
+	if _, err := runInProcess([]string{"loop"}, ""); err == nil || !strings.Contains(err.Error(), "nest more than") {
+		t.Errorf("recursive alias: error = %v", err)
+	}
+
+	out, err := runInProcess([]string{"schema", "--command", "sprint"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"craft create --title 'Sprint $1'"`) {
+		t.Errorf("schema does not show the expansion:\n%s", out)
+	}
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// auditFileName is the append-only log of mutating requests, kept next to the config.
+	auditFileName = "audit.jsonl"
+	// auditMaxArgLen truncates long argument values (such as inline markdown) in the log.
+	auditMaxArgLen = 80
+)
+
+var (
+	// auditCommand is the redacted command line of the running command.
+	auditCommand string
+
+	auditSince string
+	auditLimit int
+)
+
+// auditSecretFlags are flags whose values are never written to the audit log.
+var auditSecretFlags = map[string]bool{"api-key": true, "api-url": true, "key": true}
+
+// auditEntry is one line of the audit log.
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	Profile  string    `json:"profile,omitempty"`
+	Command  string    `json:"command"`
+	Reason   string    `json:"reason,omitempty"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	IDs      []string  `json:"ids,omitempty"`
+	Status   int       `json:"status"`
+	Result   string    `json:"result"` // ok, failed or denied
+	Error    string    `json:"error,omitempty"`
+}
+
+// auditPath returns the location of the audit log.
+func auditPath() string {
+	if cfgManager == nil {
+		return ""
+	}
+	return filepath.Join(cfgManager.Dir(), auditFileName)
+}
+
+// auditCommandLine renders the command line of cmd for the audit log, with
+// secret flag values redacted and long values truncated.
+func auditCommandLine(cmd *cobra.Command, args []string) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		parts = append(parts, auditValue(arg))
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if auditSecretFlags[f.Name] {
+			value = "[REDACTED]"
+		} else if sv, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(sv.GetSlice(), ",")
+		}
+		parts = append(parts, "--"+f.Name+"="+auditValue(value))
+	})
+	return strings.Join(parts, " ")
+}
+
+// auditValue redacts URLs (Craft API links embed their secret in the path)
+// and truncates long values.
+func auditValue(v string) string {
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Scheme + "://" + u.Host + "/[REDACTED]"
+	}
+	v = oneLine(v)
+	if r := []rune(v); len(r) > auditMaxArgLen {
+		return strconv.Quote(string(r[:auditMaxArgLen])) + fmt.Sprintf("…(%d bytes)", len(v))
+	}
+	if strings.ContainsAny(v, " \t\"") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// installAuditLog makes client append an entry to the audit log after every
+// mutating request.
+func installAuditLog(client *api.Client) {
+	path := auditPath()
+	if path == "" {
+		return
+	}
+	profile := ""
+	if apiURL == "" {
+		profile, _ = cfgManager.ActiveProfile()
+	}
+	command, reason := auditCommand, reasonFlag
+	client.AddObserver(func(o api.Outcome) {
+		entry := auditEntry{
+			Time:     time.Now().UTC(),
+			Profile:  profile,
+			Command:  command,
+			Reason:   reason,
+			Method:   o.Method,
+			Endpoint: o.Path,
+			IDs:      auditIDs(o),
+			Status:   o.Status,
+			Result:   "ok",
+		}
+		if o.Err != nil {
+			entry.Result = "failed"
+			if isPolicyError(o.Err) {
+				entry.Result = "denied"
+			}
+			entry.Error = o.Err.Error()
+		}
+		if err := appendAuditEntry(path, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	})
+}
+
+// appendAuditEntry writes entry as one line at the end of the log.
+func appendAuditEntry(path string, entry auditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	defer f.Close()
+	// A single write keeps concurrent appends from interleaving.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditIDs collects the IDs a request touched: those named in its query and
+// body, and those returned in its response (such as created blocks).
+func auditIDs(o api.Outcome) []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, key := range sortedKeys(o.Query) {
+		if isIDKey(key) {
+			for _, v := range o.Query[key] {
+				add(v)
+			}
+		}
+	}
+	for _, data := range [][]byte{o.Body, o.Response} {
+		var v interface{}
+		if len(data) > 0 && json.Unmarshal(data, &v) == nil {
+			collectIDs(v, "", add)
+		}
+	}
+	return ids
+}
+
+// isIDKey reports whether a JSON or query key holds IDs ("id", "pageId", "blockIds").
+func isIDKey(key string) bool {
+	return key == "id" || key == "ids" || strings.HasSuffix(key, "Id") || strings.HasSuffix(key, "Ids")
+}
+
+func collectIDs(v interface{}, key string, add func(string)) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(val) {
+			collectIDs(val[k], k, add)
+		}
+	case []interface{}:
+		for _, child := range val {
+			collectIDs(child, key, add)
+		}
+	case string:
+		if isIDKey(key) {
+			add(val)
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in order, so IDs are logged deterministically.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readAuditLog returns the entries at or after since for profile ("" = any),
+// oldest first. A missing log has no entries; malformed lines are skipped.
+func readAuditLog(path string, since time.Time, profile string) ([]auditEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []auditEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	entries := []auditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e auditEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if e.Time.Before(since) || (profile != "" && e.Profile != profile) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// parseSince parses a relative age ("90m", "12h", "1d", "2w") or an
+// absolute date or RFC 3339 time into the earliest time to include.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if n := len(s) - 1; n > 0 && (s[n] == 'd' || s[n] == 'w') {
+		if days, err := strconv.Atoi(s[:n]); err == nil && days >= 0 {
+			if s[n] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use an age like 12h, 1d or 2w, or a date like 2025-01-31", s)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the local log of changes made through craft",
+	Long: `Every POST, PUT and DELETE sent to the Craft API is appended to
+~/.craft-cli/audit.jsonl with its time, profile, command line, endpoint,
+affected IDs and result. API keys and API URLs are never written to it.`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit log entries",
+	Long: `List audit log entries, oldest first. With --profile, only entries made
+with that profile are shown.`,
+	Example: `  craft audit list --since 1d
+  craft audit list --since 2025-01-31 --profile work --format table`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseSince(auditSince, time.Now())
+		if err != nil {
+			return err
+		}
+		entries, err := readAuditLog(auditPath(), since, profileName)
+		if err != nil {
+			return err
+		}
+		if auditLimit > 0 && len(entries) > auditLimit {
+			entries = entries[len(entries)-auditLimit:]
+		}
+
+		switch format := getOutputFormat(); format {
+		case FormatJSON, FormatCompact:
+			return outputJSON(entries)
+		case "table", "markdown":
+			return outputAuditTable(entries)
+		default:
+			return fmt.Errorf("unsupported format: %s", format)
+		}
+	},
+}
+
+// outputAuditTable prints audit entries as a table
+func outputAuditTable(entries []auditEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if !hasNoHeaders() {
+		fmt.Fprintln(w, "TIME\tPROFILE\tREQUEST\tSTATUS\tIDS\tCOMMAND")
+		fmt.Fprintln(w, "----\t-------\t-------\t------\t---\t-------")
+	}
+
+	for _, e := range entries {
+		status := e.Result
+		if e.Status != 0 {
+			status = fmt.Sprintf("%s (%d)", e.Result, e.Status)
+		}
+		ids := strings.Join(e.IDs, ",")
+		if len(e.IDs) > 3 {
+			ids = fmt.Sprintf("%s,… (%d)", strings.Join(e.IDs[:3], ","), len(e.IDs))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s %s\t%s\t%s\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04:05"), e.Profile, e.Method, e.Endpoint, status, ids, e.Command)
+	}
+
+	return w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditListCmd.Flags().StringVar(&auditSince, "since", "", "Only entries newer than an age (12h, 1d, 2w) or a date")
+	auditListCmd.Flags().IntVar(&auditLimit, "limit", 0, "Show at most the N most recent entries (0 = all)")
+}
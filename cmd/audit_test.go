@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogRecordsMutations(t *testing.T) {
+	useTempConfig(t)
+	server := httptest.NewServer(&flakyServer{})
+	defer server.Close()
+
+	create := []string{"--api-url", server.URL, "--api-key", "sk-secret", "create", "--title", "Report", "--markdown", "Body",
+		"--reason", "weekly report"}
+	if _, err := runInProcess(create, ""); err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	usePolicy(t, "deny_destructive: true")
+	if _, err := runInProcess([]string{"--api-url", server.URL, "update", "doc1", "--mode", "replace", "--markdown", "x"}, ""); err == nil {
+		t.Fatal("expected the policy to deny the replace")
+	}
+
+	data, err := os.ReadFile(auditPath())
+	if err != nil {
+		t.Fatalf("audit log not written: %v", err)
+	}
+	if strings.Contains(string(data), "sk-secret") || strings.Contains(string(data), server.URL[len("http://"):]) {
+		t.Errorf("audit log leaks secrets:\n%s", data)
+	}
+
+	out, err := runInProcess([]string{"audit", "list", "--since", "1h"}, "")
+	if err != nil {
+		t.Fatalf("audit list error = %v", err)
+	}
+	var entries []auditEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Method+" "+e.Endpoint+" "+e.Result+" "+strings.Join(e.IDs, ","))
+	}
+	want := []string{"POST /documents ok doc1", "POST /blocks ok doc1,b", "DELETE /blocks denied old"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+	if len(entries) > 0 && (!strings.HasPrefix(entries[0].Command, "craft create") || entries[0].Reason != "weekly report" || entries[0].Status != 200) {
+		t.Errorf("first entry = %+v", entries[0])
+	}
+
+	out, _ = runInProcess([]string{"audit", "list", "--profile", "work"}, "")
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("entries for another profile = %s", out)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"1d", now.AddDate(0, 0, -1)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"2025-03-01T08:00:00Z", time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"yesterday", "-1d", "d"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
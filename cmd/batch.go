@@ -0,0 +1,814 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchFile        string
+	batchConcurrency int
+	batchRate        float64
+	batchStopOnError bool
+)
+
+// batchOp is one input line: an operation name, its arguments, and an
+// optional id that later lines can reference with {"$ref": "<id>"}.
+type batchOp struct {
+	ID   string          `json:"id,omitempty"`
+	Op   string          `json:"op"`
+	Args json.RawMessage `json:"args"`
+
+	line int
+}
+
+// batchResult is one output line, in the same order as the input.
+type batchResult struct {
+	Line       int         `json:"line"`
+	ID         string      `json:"id,omitempty"`
+	Op         string      `json:"op"`
+	Status     string      `json:"status"` // ok, error, skipped
+	CreatedIDs []string    `json:"created_ids,omitempty"`
+	DeletedIDs []string    `json:"deleted_ids,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      *batchError `json:"error,omitempty"`
+}
+
+// batchError describes a failed line using the same codes as --json-errors.
+type batchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"status,omitempty"`
+}
+
+// batchOutcome is what an operation reports on success.
+type batchOutcome struct {
+	CreatedIDs []string
+	DeletedIDs []string
+	Result     interface{}
+}
+
+// batchOpFunc executes one operation with its decoded arguments.
+type batchOpFunc func(client *api.Client, args json.RawMessage) (*batchOutcome, error)
+
+// batchOps is the registry of operations accepted by `craft batch`.
+// Names match the action names used in mutation envelopes.
+var batchOps = map[string]batchOpFunc{
+	"documents.create":   batchDocumentsCreate,
+	"documents.append":   batchDocumentsAppend,
+	"documents.move":     batchDocumentsMove,
+	"documents.delete":   batchDocumentsDelete,
+	"blocks.add":         batchBlocksAdd,
+	"blocks.update":      batchBlocksUpdate,
+	"blocks.move":        batchBlocksMove,
+	"blocks.delete":      batchBlocksDelete,
+	"tasks.add":          batchTasksAdd,
+	"tasks.update":       batchTasksUpdate,
+	"tasks.delete":       batchTasksDelete,
+	"collections.add":    batchCollectionsAdd,
+	"collections.update": batchCollectionsUpdate,
+	"collections.delete": batchCollectionsDelete,
+	"folders.create":     batchFoldersCreate,
+	"folders.move":       batchFoldersMove,
+	"folders.delete":     batchFoldersDelete,
+	"comments.add":       batchCommentsAdd,
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Run many operations from NDJSON in one process",
+	Long: `Execute operations read as NDJSON (one JSON object per line) through a
+single API client, writing one NDJSON result per input line, in input order.
+
+Each line has an "op", its "args", and an optional "id". Later lines can use
+{"$ref": "<id>"} in place of a value to get the first ID created by that line,
+or {"$ref": "<id>.<field>"} for a field of its result.
+
+Operations:
+  documents.create   title, markdown, parent
+  documents.append   id, markdown
+  documents.move     id, folder, location
+  documents.delete   id
+  blocks.add         markdown, page | date | sibling (+ relative), position
+  blocks.update      id, markdown
+  blocks.move        id, to, position
+  blocks.delete      id
+  tasks.add          markdown, location, document, schedule, deadline
+  tasks.update       id, state, schedule, deadline
+  tasks.delete       id
+  collections.add    collection, title, properties, allow_new_options
+  collections.update collection, item, properties, allow_new_options
+  collections.delete collection, item
+  folders.create     name, parent
+  folders.move       id, to
+  folders.delete     id
+  comments.add       block, content
+
+Lines that reference a failed line are skipped. Failed lines carry the same
+error codes as --json-errors (NOT_FOUND, RATE_LIMIT, ...). The command
+exits non-zero if any line failed or was skipped.
+
+Examples:
+  craft batch < ops.ndjson
+  craft batch -f ops.ndjson --concurrency 4 --rate 5
+
+  {"id":"doc","op":"documents.create","args":{"title":"Sprint 12"}}
+  {"op":"blocks.add","args":{"page":{"$ref":"doc"},"markdown":"## Goals"}}
+  {"op":"tasks.add","args":{"location":"document","document":{"$ref":"doc"},"markdown":"Plan"}}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = os.Stdin
+		if batchFile != "" && batchFile != "-" {
+			f, err := os.Open(batchFile)
+			if err != nil {
+				return fmt.Errorf("failed to open batch file: %w", err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		ops, err := readBatchOps(in)
+		if err != nil {
+			return err
+		}
+		if batchConcurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+
+		if isDryRun() {
+			counts := map[string]int{}
+			for _, op := range ops {
+				counts[op.Op]++
+			}
+			return dryRunOutput("batch", nil, map[string]interface{}{
+				"operations": len(ops),
+				"by_op":      counts,
+			})
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		client.SetRateLimit(batchRate)
+
+		failed := 0
+		err = runBatch(client, ops, batchConcurrency, batchStopOnError, func(r *batchResult) error {
+			if r.Status != "ok" {
+				failed++
+			}
+			return writeNDJSON(r)
+		})
+		if err != nil {
+			return err
+		}
+		printStatus("%d operation(s), %d ok, %d not ok\n", len(ops), len(ops)-failed, failed)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d operation(s) failed or were skipped", failed, len(ops))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVarP(&batchFile, "file", "f", "", "Read operations from file instead of stdin")
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", 1, "Number of operations to run in parallel")
+	batchCmd.Flags().Float64Var(&batchRate, "rate", 0, "Maximum API requests per second (0 = unlimited)")
+	batchCmd.Flags().BoolVar(&batchStopOnError, "stop-on-error", false, "Skip remaining operations after the first failure")
+}
+
+// readBatchOps parses NDJSON operations and checks names and references.
+// References must point to an id defined on an earlier line.
+func readBatchOps(r io.Reader) ([]batchOp, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var ops []batchOp
+	ids := map[string]bool{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		op := batchOp{line: line}
+		if err := json.Unmarshal([]byte(text), &op); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+		}
+		if _, ok := batchOps[op.Op]; !ok {
+			return nil, fmt.Errorf("line %d: unknown op %q (supported: %s)", line, op.Op, strings.Join(batchOpNames(), ", "))
+		}
+		for _, ref := range batchRefs(op.Args) {
+			if !ids[ref] {
+				return nil, fmt.Errorf("line %d: $ref %q does not match the id of an earlier line", line, ref)
+			}
+		}
+		if op.ID != "" {
+			if ids[op.ID] {
+				return nil, fmt.Errorf("line %d: duplicate id %q", line, op.ID)
+			}
+			ids[op.ID] = true
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read operations: %w", err)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no operations provided")
+	}
+	return ops, nil
+}
+
+// batchOpNames returns the supported operation names, sorted.
+func batchOpNames() []string {
+	names := make([]string, 0, len(batchOps))
+	for name := range batchOps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// batchRefs returns the ids referenced by {"$ref": ...} objects in args.
+func batchRefs(args json.RawMessage) []string {
+	var v interface{}
+	if len(args) == 0 || json.Unmarshal(args, &v) != nil {
+		return nil
+	}
+	var refs []string
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := t["$ref"].(string); ok && len(t) == 1 {
+				refs = append(refs, strings.SplitN(ref, ".", 2)[0])
+				return
+			}
+			for _, child := range t {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	return refs
+}
+
+// runBatch executes ops with up to concurrency in flight. Operations start
+// in input order and wait for the lines they reference; emit receives the
+// results in input order.
+func runBatch(client *api.Client, ops []batchOp, concurrency int, stopOnError bool, emit func(*batchResult) error) error {
+	results := make([]*batchResult, len(ops))
+	done := make([]chan struct{}, len(ops))
+	byID := map[string]int{}
+	for i, op := range ops {
+		done[i] = make(chan struct{})
+		if op.ID != "" {
+			byID[op.ID] = i
+		}
+	}
+
+	var mu sync.Mutex
+	failed := false
+	sem := make(chan struct{}, concurrency)
+
+	go func() {
+		for i := range ops {
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() { <-sem; close(done[i]) }()
+
+				mu.Lock()
+				stop := stopOnError && failed
+				mu.Unlock()
+
+				var r *batchResult
+				if stop {
+					r = batchSkipped(ops[i], "skipped after an earlier failure")
+				} else {
+					r = runBatchOp(client, ops[i], results, done, byID)
+				}
+				results[i] = r
+
+				if r.Status == "error" {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}(i)
+		}
+	}()
+
+	for i := range ops {
+		<-done[i]
+		if err := emit(results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runBatchOp waits for referenced lines, substitutes their IDs, and runs op.
+func runBatchOp(client *api.Client, op batchOp, results []*batchResult, done []chan struct{}, byID map[string]int) *batchResult {
+	for _, ref := range batchRefs(op.Args) {
+		dep := byID[ref]
+		<-done[dep]
+		if results[dep].Status != "ok" {
+			return batchSkipped(op, fmt.Sprintf("depends on %q (line %d), which did not succeed", ref, results[dep].Line))
+		}
+	}
+
+	args, err := substituteBatchRefs(op.Args, func(ref string) (interface{}, error) {
+		parts := strings.SplitN(ref, ".", 2)
+		dep := results[byID[parts[0]]]
+		if len(parts) == 1 {
+			if len(dep.CreatedIDs) == 0 {
+				return nil, fmt.Errorf("$ref %q: that line created nothing", ref)
+			}
+			return dep.CreatedIDs[0], nil
+		}
+		var fields map[string]interface{}
+		data, _ := json.Marshal(dep.Result)
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("$ref %q: result has no fields", ref)
+		}
+		v, ok := fields[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("$ref %q: result has no field %q", ref, parts[1])
+		}
+		return v, nil
+	})
+
+	if err == nil {
+		args, err = resolveBatchIDArgs(op.Op, args)
+	}
+
+	r := &batchResult{Line: op.line, ID: op.ID, Op: op.Op}
+	var out *batchOutcome
+	if err == nil {
+		out, err = batchOps[op.Op](client, args)
+	}
+	if err != nil {
+		r.Status = "error"
+		r.Error = &batchError{Code: categorizeError(err), Message: err.Error()}
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) {
+			r.Error.Status = apiErr.StatusCode
+		}
+		return r
+	}
+
+	r.Status = "ok"
+	r.CreatedIDs = out.CreatedIDs
+	r.DeletedIDs = out.DeletedIDs
+	r.Result = out.Result
+	return r
+}
+
+// batchSkipped builds the result for a line that was not run.
+func batchSkipped(op batchOp, reason string) *batchResult {
+	return &batchResult{
+		Line: op.line, ID: op.ID, Op: op.Op, Status: "skipped",
+		Error: &batchError{Code: "SKIPPED", Message: reason},
+	}
+}
+
+// substituteBatchRefs replaces every {"$ref": ...} object in args using lookup.
+func substituteBatchRefs(args json.RawMessage, lookup func(ref string) (interface{}, error)) (json.RawMessage, error) {
+	if len(args) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(args, &v); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+
+	var walk func(interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := t["$ref"].(string); ok && len(t) == 1 {
+				return lookup(ref)
+			}
+			for k, child := range t {
+				nv, err := walk(child)
+				if err != nil {
+					return nil, err
+				}
+				t[k] = nv
+			}
+		case []interface{}:
+			for i, child := range t {
+				nv, err := walk(child)
+				if err != nil {
+					return nil, err
+				}
+				t[i] = nv
+			}
+		}
+		return v, nil
+	}
+
+	nv, err := walk(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(nv)
+}
+
+// decodeBatchArgs decodes args strictly so typos in field names are reported.
+func decodeBatchArgs(args json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid args: %w", err)
+	}
+	return nil
+}
+
+// requireArgs returns an error naming the first empty required argument.
+func requireArgs(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			return fmt.Errorf("invalid args: %s is required", pairs[i])
+		}
+	}
+	return nil
+}
+
+func batchDocumentsCreate(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Title    string `json:"title"`
+		Markdown string `json:"markdown"`
+		Parent   string `json:"parent"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("title", a.Title); err != nil {
+		return nil, err
+	}
+	doc, err := client.CreateDocument(&models.CreateDocumentRequest{Title: a.Title, Markdown: a.Markdown, ParentID: a.Parent})
+	if err != nil {
+		return nil, err
+	}
+	return &batchOutcome{CreatedIDs: []string{doc.ID}, Result: doc}, nil
+}
+
+func batchDocumentsAppend(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID       string `json:"id"`
+		Markdown string `json:"markdown"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID, "markdown", a.Markdown); err != nil {
+		return nil, err
+	}
+	if _, err := client.AppendMarkdown(a.ID, a.Markdown, 0); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{}, nil
+}
+
+func batchDocumentsMove(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID       string `json:"id"`
+		Folder   string `json:"folder"`
+		Location string `json:"location"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if a.Folder == "" && a.Location == "" {
+		return nil, fmt.Errorf("invalid args: folder or location is required")
+	}
+	return &batchOutcome{}, client.MoveDocument(a.ID, a.Folder, a.Location)
+}
+
+func batchDocumentsDelete(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID string `json:"id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if err := client.DeleteDocument(a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{DeletedIDs: []string{a.ID}}, nil
+}
+
+func batchBlocksAdd(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Markdown string `json:"markdown"`
+		Page     string `json:"page"`
+		Date     string `json:"date"`
+		Sibling  string `json:"sibling"`
+		Relative string `json:"relative"`
+		Position string `json:"position"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("markdown", a.Markdown); err != nil {
+		return nil, err
+	}
+	if err := resolveBlockRefs(&a.Page, &a.Sibling); err != nil {
+		return nil, err
+	}
+	if a.Position == "" {
+		a.Position = "end"
+	}
+
+	var block *models.Block
+	var err error
+	switch {
+	case a.Sibling != "":
+		if a.Relative == "" {
+			a.Relative = "after"
+		}
+		block, err = client.AddBlockRelative(a.Sibling, a.Markdown, a.Relative)
+	case a.Date != "":
+		block, err = client.AddBlockToDate(a.Date, a.Markdown, a.Position)
+	case a.Page != "":
+		block, err = client.AddBlock(a.Page, a.Markdown, a.Position)
+	default:
+		return nil, fmt.Errorf("invalid args: one of page, date, sibling is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batchOutcome{CreatedIDs: []string{block.ID}, Result: block}, nil
+}
+
+func batchBlocksUpdate(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID       string `json:"id"`
+		Markdown string `json:"markdown"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID, "markdown", a.Markdown); err != nil {
+		return nil, err
+	}
+	if err := resolveBlockRefs(&a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{}, client.UpdateBlockMarkdown(a.ID, a.Markdown)
+}
+
+func batchBlocksMove(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID       string `json:"id"`
+		To       string `json:"to"`
+		Position string `json:"position"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID, "to", a.To); err != nil {
+		return nil, err
+	}
+	if err := resolveBlockRefs(&a.ID, &a.To); err != nil {
+		return nil, err
+	}
+	if a.Position == "" {
+		a.Position = "end"
+	}
+	return &batchOutcome{}, client.MoveBlock(a.ID, a.To, a.Position)
+}
+
+func batchBlocksDelete(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID string `json:"id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if err := resolveBlockRefs(&a.ID); err != nil {
+		return nil, err
+	}
+	if err := client.DeleteBlock(a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{DeletedIDs: []string{a.ID}}, nil
+}
+
+func batchTasksAdd(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Markdown string `json:"markdown"`
+		Location string `json:"location"`
+		Document string `json:"document"`
+		Schedule string `json:"schedule"`
+		Deadline string `json:"deadline"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("markdown", a.Markdown); err != nil {
+		return nil, err
+	}
+	if a.Location == "" {
+		a.Location = "inbox"
+	}
+	task, err := client.AddTask(a.Markdown, a.Location, a.Document, a.Schedule, a.Deadline)
+	if err != nil {
+		return nil, err
+	}
+	return &batchOutcome{CreatedIDs: []string{task.ID}, Result: task}, nil
+}
+
+func batchTasksUpdate(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID       string `json:"id"`
+		State    string `json:"state"`
+		Schedule string `json:"schedule"`
+		Deadline string `json:"deadline"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{}, client.UpdateTask(a.ID, a.State, a.Schedule, a.Deadline)
+}
+
+func batchTasksDelete(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID string `json:"id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if err := client.DeleteTask(a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{DeletedIDs: []string{a.ID}}, nil
+}
+
+func batchCollectionsAdd(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Collection      string                 `json:"collection"`
+		Title           string                 `json:"title"`
+		Properties      map[string]interface{} `json:"properties"`
+		AllowNewOptions bool                   `json:"allow_new_options"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("collection", a.Collection, "title", a.Title); err != nil {
+		return nil, err
+	}
+	items, err := client.AddCollectionItem(a.Collection, a.Title, a.Properties, a.AllowNewOptions)
+	if err != nil {
+		return nil, err
+	}
+	out := &batchOutcome{CreatedIDs: []string{}, Result: items}
+	for _, it := range items.Items {
+		out.CreatedIDs = append(out.CreatedIDs, it.ID)
+	}
+	return out, nil
+}
+
+func batchCollectionsUpdate(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Collection      string                 `json:"collection"`
+		Item            string                 `json:"item"`
+		Properties      map[string]interface{} `json:"properties"`
+		AllowNewOptions bool                   `json:"allow_new_options"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("collection", a.Collection, "item", a.Item); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{}, client.UpdateCollectionItem(a.Collection, a.Item, a.Properties, a.AllowNewOptions)
+}
+
+func batchCollectionsDelete(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Collection string `json:"collection"`
+		Item       string `json:"item"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("collection", a.Collection, "item", a.Item); err != nil {
+		return nil, err
+	}
+	if err := client.DeleteCollectionItem(a.Collection, a.Item); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{DeletedIDs: []string{a.Item}}, nil
+}
+
+func batchFoldersCreate(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Name   string `json:"name"`
+		Parent string `json:"parent"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("name", a.Name); err != nil {
+		return nil, err
+	}
+	folder, err := client.CreateFolder(a.Name, a.Parent)
+	if err != nil {
+		return nil, err
+	}
+	return &batchOutcome{CreatedIDs: []string{folder.ID}, Result: folder}, nil
+}
+
+func batchFoldersMove(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID string `json:"id"`
+		To string `json:"to"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if a.To == "root" {
+		a.To = ""
+	}
+	return &batchOutcome{}, client.MoveFolder(a.ID, a.To)
+}
+
+func batchFoldersDelete(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		ID string `json:"id"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("id", a.ID); err != nil {
+		return nil, err
+	}
+	if err := client.DeleteFolder(a.ID); err != nil {
+		return nil, err
+	}
+	return &batchOutcome{DeletedIDs: []string{a.ID}}, nil
+}
+
+func batchCommentsAdd(client *api.Client, raw json.RawMessage) (*batchOutcome, error) {
+	var a struct {
+		Block   string `json:"block"`
+		Content string `json:"content"`
+	}
+	if err := decodeBatchArgs(raw, &a); err != nil {
+		return nil, err
+	}
+	if err := requireArgs("block", a.Block, "content", a.Content); err != nil {
+		return nil, err
+	}
+	if err := resolveBlockRefs(&a.Block); err != nil {
+		return nil, err
+	}
+	resp, err := client.AddComment(a.Block, a.Content)
+	if err != nil {
+		return nil, err
+	}
+	out := &batchOutcome{CreatedIDs: []string{}, Result: resp}
+	for _, it := range resp.Items {
+		out.CreatedIDs = append(out.CreatedIDs, it.CommentID)
+	}
+	return out, nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+)
+
+func TestReadBatchOpsValidation(t *testing.T) {
+	tests := []struct {
+		name, input, wantErr string
+	}{
+		{"unknown op", `{"op":"nope.run","args":{}}`, "unknown op"},
+		{"forward ref", `{"op":"blocks.add","args":{"page":{"$ref":"doc"},"markdown":"x"}}` + "\n" +
+			`{"id":"doc","op":"documents.create","args":{"title":"T"}}`, "does not match"},
+		{"duplicate id", `{"id":"a","op":"tasks.delete","args":{"id":"1"}}` + "\n" +
+			`{"id":"a","op":"tasks.delete","args":{"id":"2"}}`, "duplicate id"},
+		{"empty", "\n\n", "no operations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readBatchOps(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readBatchOps() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunBatchRefsAndErrors(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"doc-1","title":"Sprint"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/blocks":
+			w.Write([]byte(`{"items":[{"id":"blk-1","type":"text","markdown":"x"}]}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not Found","message":"block not found"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	input := strings.Join([]string{
+		`{"id":"doc","op":"documents.create","args":{"title":"Sprint"}}`,
+		``,
+		`{"id":"blk","op":"blocks.add","args":{"page":{"$ref":"doc"},"markdown":"## Goals"}}`,
+		`{"id":"gone","op":"blocks.delete","args":{"id":"missing"}}`,
+		`{"op":"blocks.update","args":{"id":{"$ref":"gone"},"markdown":"x"}}`,
+		`{"op":"blocks.update","args":{"id":"b1","markdwn":"typo"}}`,
+	}, "\n")
+	ops, err := readBatchOps(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchOps() error = %v", err)
+	}
+
+	var results []*batchResult
+	err = runBatch(api.NewClient(server.URL), ops, 3, false, func(r *batchResult) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	want := []struct {
+		line   int
+		status string
+		code   string
+	}{
+		{1, "ok", ""},
+		{3, "ok", ""},
+		{4, "error", "NOT_FOUND"},
+		{5, "skipped", "SKIPPED"},
+		{6, "error", "USER_ERROR"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		code := ""
+		if r.Error != nil {
+			code = r.Error.Code
+		}
+		if r.Line != w.line || r.Status != w.status || code != w.code {
+			t.Errorf("result %d = line %d %s %s, want line %d %s %s", i, r.Line, r.Status, code, w.line, w.status, w.code)
+		}
+	}
+	if results[2].Error.Status != http.StatusNotFound {
+		t.Errorf("expected HTTP status on API errors, got %d", results[2].Error.Status)
+	}
+
+	found := false
+	for _, b := range bodies {
+		if strings.Contains(b, `"pageId":"doc-1"`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("$ref was not substituted; requests: %v", bodies)
+	}
+}
+
+func TestRunBatchStopOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"boom"}`))
+	}))
+	defer server.Close()
+
+	ops, _ := readBatchOps(strings.NewReader(
+		`{"op":"tasks.delete","args":{"id":"1"}}` + "\n" + `{"op":"tasks.delete","args":{"id":"2"}}`))
+
+	var statuses []string
+	runBatch(api.NewClient(server.URL), ops, 1, true, func(r *batchResult) error {
+		statuses = append(statuses, r.Status)
+		return nil
+	})
+	if strings.Join(statuses, ",") != "error,skipped" {
+		t.Errorf("statuses = %v", statuses)
+	}
+}
+
+func TestBatchCommandFailsWhenALineFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "missing") {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	in := `{"op":"tasks.delete","args":{"id":"t1"}}` + "\n" + `{"op":"tasks.delete","args":{"id":"missing"}}`
+	out, err := runInProcess([]string{"--api-url", server.URL, "batch"}, in)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 operation(s)") {
+		t.Errorf("error = %v, want the failed line reported", err)
+	}
+	if strings.Count(out, "\n") != 2 {
+		t.Errorf("expected a result line per operation:\n%s", out)
+	}
+}
+
+func TestSubstituteBatchRefsField(t *testing.T) {
+	got, err := substituteBatchRefs([]byte(`{"a":{"$ref":"x.title"},"b":[{"$ref":"x"}]}`), func(ref string) (interface{}, error) {
+		return "id-" + ref, nil
+	})
+	if err != nil {
+		t.Fatalf("substituteBatchRefs() error = %v", err)
+	}
+	if string(got) != `{"a":"id-x.title","b":["id-x"]}` {
+		t.Errorf("got %s", got)
+	}
+}
@@ -53,10 +53,16 @@ Examples:
 			return err
 		}
 
+		if len(args) > 0 {
+			if err := resolveBlockRefs(&args[0]); err != nil {
+				return err
+			}
+		}
+
 		var block *models.Block
 
 		if blockDate != "" {
-			block, err = client.GetBlockByDate(blockDate, blockDepth, blockMetadata)
+			block, err = client.GetBlockByDate(resolveDay(blockDate), blockDepth, blockMetadata)
 		} else {
 			if len(args) == 0 {
 				return fmt.Errorf("block-id is required when not using --date")
@@ -79,8 +85,20 @@ Examples:
 		case FormatRich:
 			var sb strings.Builder
 			renderBlockRich(&sb, block, 0)
-			fmt.Print(sb.String())
-			return nil
+			return writeTerminal(sb.String())
+		case FormatPandocJSON:
+			return json.NewEncoder(os.Stdout).Encode(blocksToPandoc(block))
+		case FormatLLM:
+			store, err := loadHandleStore(handlesPath())
+			if err != nil {
+				return err
+			}
+			out, err := renderLLM(block, store, llmMaxTokens, llmCursor)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return store.save()
 		default:
 			fmt.Println(block.Markdown)
 			return nil
@@ -186,31 +204,42 @@ JSON Examples:
 			blocks = []map[string]interface{}{block}
 		}
 
+		if len(args) > 0 {
+			if err := resolveBlockRefs(&args[0]); err != nil {
+				return err
+			}
+		}
+		if err := resolveBlockRefs(&blockSiblingID); err != nil {
+			return err
+		}
+
 		position, err := buildAddPosition(cmd, args)
 		if err != nil {
 			return err
 		}
 
+		if isDryRun() {
+			return dryRunOutput("blocks.add", []string{addPositionTarget(position)}, map[string]interface{}{
+				"count": len(blocks), "position": position["position"],
+			})
+		}
+
 		result, err := client.AddBlocksJSON(blocks, position)
 		if err != nil {
 			return err
 		}
 
-		if isQuiet() {
+		res := newMutation("blocks.add", addPositionTarget(position))
+		for _, b := range result {
+			res.CreatedIDs = append(res.CreatedIDs, b.ID)
+		}
+		res.Result = result
+		return outputMutation(res, func() error {
 			for _, b := range result {
-				fmt.Println(b.ID)
+				fmt.Printf("Block created: %s\n", b.ID)
 			}
 			return nil
-		}
-
-		format := getOutputFormat()
-		if isJSONFormat(format) {
-			return outputJSON(result)
-		}
-		for _, b := range result {
-			fmt.Printf("Block created: %s\n", b.ID)
-		}
-		return nil
+		})
 	},
 }
 
@@ -264,6 +293,9 @@ JSON Examples:
 			if len(args) == 0 {
 				return fmt.Errorf("block-id argument is required when not using --json or --stdin")
 			}
+			if err := resolveBlockRefs(&args[0]); err != nil {
+				return err
+			}
 			block := buildUpdateFromFlags(cmd, args[0])
 			if len(block) <= 1 { // only "id" present
 				return fmt.Errorf("at least one property to update is required (e.g. --markdown, --color, --text-style)")
@@ -271,6 +303,16 @@ JSON Examples:
 			blocks = []map[string]interface{}{block}
 		}
 
+		for _, b := range blocks {
+			if id, ok := b["id"].(string); ok {
+				resolved, err := resolveBlockRef(id)
+				if err != nil {
+					return err
+				}
+				b["id"] = resolved
+			}
+		}
+
 		if isDryRun() {
 			ids := []string{}
 			for _, b := range blocks {
@@ -278,8 +320,8 @@ JSON Examples:
 					ids = append(ids, id)
 				}
 			}
-			return dryRunOutput("update blocks", map[string]interface{}{
-				"block_ids": ids, "count": len(blocks),
+			return dryRunOutput("blocks.update", ids, map[string]interface{}{
+				"count": len(blocks),
 			})
 		}
 
@@ -292,26 +334,35 @@ JSON Examples:
 			return err
 		}
 
-		if !isQuiet() {
-			for _, b := range blocks {
-				if id, ok := b["id"].(string); ok {
-					fmt.Printf("Block %s updated\n", id)
-				}
+		res := newMutation("blocks.update")
+		for _, b := range blocks {
+			if id, ok := b["id"].(string); ok {
+				res.Targets = append(res.Targets, id)
 			}
 		}
-		return nil
+		return outputMutation(res, func() error {
+			for _, id := range res.Targets {
+				fmt.Printf("Block %s updated\n", id)
+			}
+			return nil
+		})
 	},
 }
 
 var blocksDeleteCmd = &cobra.Command{
-	Use:   "delete [block-id]",
-	Short: "Delete a block",
-	Long:  "Delete a specific block from a document",
-	Args:  cobra.ExactArgs(1),
+	Use:         "delete [block-id]",
+	Short:       "Delete a block",
+	Long:        "Delete a specific block from a document",
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveBlockRefs(&args[0]); err != nil {
+			return err
+		}
+
 		if isDryRun() {
-			return dryRunOutput("delete block", map[string]interface{}{
-				"id": args[0], "destructive": true,
+			return dryRunOutput("blocks.delete", []string{args[0]}, map[string]interface{}{
+				"destructive": true,
 			})
 		}
 
@@ -325,10 +376,12 @@ var blocksDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("blocks.delete", blockID)
+		res.DeletedIDs = []string{blockID}
+		return outputMutation(res, func() error {
 			fmt.Printf("Block %s deleted\n", blockID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -348,10 +401,13 @@ Examples:
 		if blockPosition == "" {
 			blockPosition = "end"
 		}
+		if err := resolveBlockRefs(&args[0], &blockTargetPage); err != nil {
+			return err
+		}
 
 		if isDryRun() {
-			return dryRunOutput("move block", map[string]interface{}{
-				"id": args[0], "target_page": blockTargetPage, "position": blockPosition,
+			return dryRunOutput("blocks.move", []string{args[0]}, map[string]interface{}{
+				"target_page": blockTargetPage, "position": blockPosition,
 			})
 		}
 
@@ -365,10 +421,12 @@ Examples:
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("blocks.move", blockID)
+		res.Details = map[string]interface{}{"target_page": blockTargetPage, "position": blockPosition}
+		return outputMutation(res, func() error {
 			fmt.Printf("Block %s moved to %s\n", blockID, blockTargetPage)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -419,15 +477,12 @@ func buildAddPosition(cmd *cobra.Command, args []string) (map[string]interface{}
 	pos["position"] = position
 
 	if blockSiblingID != "" {
-		if position != "before" && position != "after" {
-			return nil, fmt.Errorf("--position must be 'before' or 'after' when using --sibling")
-		}
 		pos["siblingId"] = blockSiblingID
 		return pos, nil
 	}
 
 	if blockDate != "" {
-		pos["date"] = blockDate
+		pos["date"] = resolveDay(blockDate)
 		return pos, nil
 	}
 
@@ -438,6 +493,16 @@ func buildAddPosition(cmd *cobra.Command, args []string) (map[string]interface{}
 	return pos, nil
 }
 
+// addPositionTarget returns the page, sibling, or daily note date a block add targets.
+func addPositionTarget(pos map[string]interface{}) string {
+	for _, key := range []string{"pageId", "siblingId", "date"} {
+		if v, ok := pos[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // buildBlockFromFlags constructs a block map from individual CLI flags.
 func buildBlockFromFlags(cmd *cobra.Command) map[string]interface{} {
 	block := make(map[string]interface{})
@@ -596,6 +661,8 @@ func init() {
 	blocksGetCmd.Flags().StringVar(&blockDate, "date", "", "Daily note date (today, tomorrow, yesterday, YYYY-MM-DD)")
 	blocksGetCmd.Flags().IntVar(&blockDepth, "depth", -1, "Max depth (-1 for all, 0 for block only)")
 	blocksGetCmd.Flags().BoolVar(&blockMetadata, "metadata", false, "Include metadata (created/modified info)")
+	blocksGetCmd.Flags().IntVar(&llmMaxTokens, "max-tokens", 0, "With --format llm: truncate at about this many tokens (0 = no limit)")
+	blocksGetCmd.Flags().StringVar(&llmCursor, "cursor", "", "With --format llm: resume output at this block handle")
 
 	blocksCmd.AddCommand(blocksAddCmd)
 	blocksAddCmd.Flags().StringVarP(&blockMarkdown, "markdown", "m", "", "Markdown content for the block")
@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Prefixes of references accepted wherever an ID is expected.
+const (
+	// bookmarkPrefix marks a bookmark name (e.g. @roadmap).
+	bookmarkPrefix = "@"
+	// titlePrefix marks a document title or folder name (e.g. title:"Weekly Sync").
+	titlePrefix = "title:"
+	// maxTitleCandidates bounds the candidates listed when a title is ambiguous.
+	maxTitleCandidates = 10
+	// maxTitleLookups bounds the documents found by search whose titles are
+	// fetched to resolve a title reference.
+	maxTitleLookups = 25
+)
+
+// What an ID parameter refers to, which decides what a title: reference
+// is matched against.
+const (
+	idDocument = "document" // documents and blocks: document titles
+	idFolder   = "folder"   // folder names
+	idPlain    = "plain"    // collections, tasks, whiteboards: bookmarks only
+)
+
+var bookmarkNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// idParam lists the parameters of a command that take IDs: the first
+// positional argument and flags, each with its kind.
+type idParam struct {
+	Arg   string
+	Flags map[string]string
+}
+
+// idFlagUsageRe finds the noun before "ID" in a flag's help, as in
+// "Parent folder ID" or "Document/block ID for block-level search".
+var idFlagUsageRe = regexp.MustCompile(`^(.*?)\bID\b`)
+
+// idParamsOf derives the ID parameters of cmd from its metadata: the
+// first positional argument of its usage line when it names an ID
+// (<document-id>, [folder-id], WHITEBOARD_ID), and the flags whose help
+// names one. Their @bookmark and title: references are resolved before the
+// command runs. Block handles (^3fa2) are resolved by the commands themselves.
+func idParamsOf(cmd *cobra.Command) idParam {
+	var p idParam
+	if fields := strings.Fields(cmd.Use); len(fields) > 1 {
+		arg := strings.ReplaceAll(strings.ToLower(strings.Trim(fields[1], "<>[]")), "_", "-")
+		if stem, ok := strings.CutSuffix(arg, "id"); ok && strings.TrimSuffix(stem, "-") != "" {
+			p.Arg = idKindOf(stem)
+		}
+	}
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if m := idFlagUsageRe.FindStringSubmatch(f.Usage); m != nil {
+			if p.Flags == nil {
+				p.Flags = map[string]string{}
+			}
+			p.Flags[f.Name] = idKindOf(strings.ToLower(m[1]))
+		}
+	})
+	return p
+}
+
+// idKindOf maps the noun naming an ID to its kind.
+func idKindOf(noun string) string {
+	switch {
+	case strings.Contains(noun, "folder"):
+		return idFolder
+	case strings.Contains(noun, "document"), strings.Contains(noun, "page"), strings.Contains(noun, "block"):
+		return idDocument
+	default:
+		return idPlain
+	}
+}
+
+// batchIDArgs maps a batch op to its ID fields that are not block
+// references (those go through resolveBlockRefs), each with its kind.
+var batchIDArgs = map[string]map[string]string{
+	"documents.create":   {"parent": idDocument},
+	"documents.append":   {"id": idDocument},
+	"documents.move":     {"id": idDocument, "folder": idFolder},
+	"documents.delete":   {"id": idDocument},
+	"tasks.add":          {"document": idDocument},
+	"tasks.update":       {"id": idPlain},
+	"tasks.delete":       {"id": idPlain},
+	"collections.add":    {"collection": idPlain},
+	"collections.update": {"collection": idPlain, "item": idPlain},
+	"collections.delete": {"collection": idPlain, "item": idPlain},
+	"folders.create":     {"parent": idFolder},
+	"folders.move":       {"id": idFolder, "to": idFolder},
+	"folders.delete":     {"id": idFolder},
+}
+
+// resolveBatchIDArgs replaces references in the ID fields of a batch op's
+// args with the IDs they name.
+func resolveBatchIDArgs(op string, args json.RawMessage) (json.RawMessage, error) {
+	fields := batchIDArgs[op]
+	if len(fields) == 0 {
+		return args, nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(args, &m); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	changed := false
+	for name, kind := range fields {
+		ref, ok := m[name].(string)
+		if !ok || !isIDRef(ref) {
+			continue
+		}
+		id, err := resolveIDRef(ref, kind)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		m[name] = id
+		changed = true
+	}
+	if !changed {
+		return args, nil
+	}
+	return json.Marshal(m)
+}
+
+// isIDRef reports whether ref is a bookmark or title reference.
+func isIDRef(ref string) bool {
+	return (strings.HasPrefix(ref, bookmarkPrefix) && len(ref) > len(bookmarkPrefix)) || strings.HasPrefix(ref, titlePrefix)
+}
+
+// resolveIDParams replaces references in the ID arguments and flags of cmd
+// with the IDs they name.
+func resolveIDParams(cmd *cobra.Command, args []string) error {
+	params := idParamsOf(cmd)
+	if isFanOut() {
+		// A bookmark or title names an item in one space only.
+		for name := range params.Flags {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Changed && isIDRef(f.Value.String()) {
+				return fmt.Errorf("--%s: bookmark and title references cannot be combined with --profiles or --all-profiles", name)
+			}
+		}
+		return nil
+	}
+	if params.Arg != "" && len(args) > 0 && isIDRef(args[0]) {
+		id, err := resolveIDRef(args[0], params.Arg)
+		if err != nil {
+			return err
+		}
+		args[0] = id
+	}
+	for name, kind := range params.Flags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || !f.Changed || !isIDRef(f.Value.String()) {
+			continue
+		}
+		id, err := resolveIDRef(f.Value.String(), kind)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+		if err := f.Value.Set(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveIDRef turns @name into the bookmarked ID and title:"..." into the
+// ID of the only document (or folder) with that title. Anything else is
+// returned unchanged.
+func resolveIDRef(ref, kind string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, bookmarkPrefix) && len(ref) > len(bookmarkPrefix):
+		return lookupBookmark(strings.TrimPrefix(ref, bookmarkPrefix))
+	case strings.HasPrefix(ref, titlePrefix):
+		title := strings.Trim(strings.TrimSpace(strings.TrimPrefix(ref, titlePrefix)), `"'`)
+		if title == "" {
+			return "", fmt.Errorf("empty title in %q", ref)
+		}
+		switch kind {
+		case idDocument:
+			return resolveDocumentTitle(title)
+		case idFolder:
+			return resolveFolderName(title)
+		default:
+			return "", fmt.Errorf("title references only work for documents, blocks and folders; use an ID or a bookmark")
+		}
+	}
+	return ref, nil
+}
+
+func lookupBookmark(name string) (string, error) {
+	profile, err := cfgManager.ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	bookmarks, err := cfgManager.Bookmarks(profile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	id, ok := bookmarks[name]
+	if !ok {
+		return "", fmt.Errorf("bookmark @%s not found. Use 'craft bookmark list' to see bookmarks", name)
+	}
+	return id, nil
+}
+
+// titleMatch is a document or folder considered for a title reference.
+type titleMatch struct {
+	ID, Title string
+}
+
+// resolveDocumentTitle searches for title and matches it against the
+// titles of the documents found, fetching at most maxTitleLookups of them.
+func resolveDocumentTitle(title string) (string, error) {
+	client, err := getAPIClient()
+	if err != nil {
+		return "", err
+	}
+	hits, err := client.SearchDocuments(title)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up title %q: %w", title, err)
+	}
+	var all []titleMatch
+	seen := map[string]bool{}
+	for _, hit := range hits.Items {
+		if seen[hit.DocumentID] {
+			continue
+		}
+		seen[hit.DocumentID] = true
+		if len(seen) > maxTitleLookups {
+			return "", fmt.Errorf("title %q is found in more than %d documents; use an ID or a bookmark", title, maxTitleLookups)
+		}
+		page, err := client.GetDocumentBlocksWithDepth(hit.DocumentID, 0)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up title %q: %w", title, err)
+		}
+		all = append(all, titleMatch{hit.DocumentID, page.Markdown})
+	}
+	return matchTitle("document", title, all)
+}
+
+func resolveFolderName(name string) (string, error) {
+	client, err := getAPIClient()
+	if err != nil {
+		return "", err
+	}
+	folders, err := client.GetFolders()
+	if err != nil {
+		return "", fmt.Errorf("failed to look up folder %q: %w", name, err)
+	}
+	var all []titleMatch
+	for _, f := range folders.Items {
+		all = append(all, titleMatch{f.ID, f.Name})
+	}
+	return matchTitle("folder", name, all)
+}
+
+// matchTitle returns the ID of the only item whose title equals title,
+// ignoring case. Otherwise the error lists the candidates: every exact
+// match when there are several, else titles that contain it.
+func matchTitle(kind, title string, items []titleMatch) (string, error) {
+	var exact, partial []titleMatch
+	for _, it := range items {
+		switch {
+		case strings.EqualFold(strings.TrimSpace(it.Title), title):
+			exact = append(exact, it)
+		case strings.Contains(strings.ToLower(it.Title), strings.ToLower(title)):
+			partial = append(partial, it)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return exact[0].ID, nil
+	case len(exact) > 1:
+		return "", fmt.Errorf("%d %ss are titled %q; use an ID or a bookmark:%s", len(exact), kind, title, listCandidates(exact))
+	case len(partial) > 0:
+		return "", fmt.Errorf("no %s titled %q; did you mean:%s", kind, title, listCandidates(partial))
+	}
+	return "", fmt.Errorf("no %s titled %q", kind, title)
+}
+
+func listCandidates(items []titleMatch) string {
+	var sb strings.Builder
+	for i, it := range items {
+		if i == maxTitleCandidates {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(items)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  %s  %s", it.ID, it.Title)
+	}
+	return sb.String()
+}
+
+// bookmark is one entry of craft bookmark list.
+type bookmark struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// bookmarkProfile returns the profile whose bookmarks are in use.
+func bookmarkProfile() (string, error) {
+	profile, err := cfgManager.ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return "", fmt.Errorf("no active profile. Run 'craft config add <name> <url>' first")
+	}
+	return profile, nil
+}
+
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark",
+	Short: "Name IDs to use as @name",
+	Long: `Give documents, blocks, folders and other items names, so commands can
+take @name wherever they expect an ID:
+
+  craft bookmark add roadmap 5F3A...
+  craft get @roadmap
+  craft blocks add @roadmap --markdown "- New idea"
+
+Bookmarks belong to the profile in effect, since IDs differ between spaces.
+
+Documents and folders can also be named by title, without a bookmark:
+
+  craft get 'title:"Weekly Sync"'
+  craft create --title Notes --folder title:Projects
+
+A title must match exactly one document (or folder), ignoring case;
+otherwise the error lists the candidates.`,
+}
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add <name> <id>",
+	Short: "Add or replace a bookmark",
+	Example: `  craft bookmark add roadmap 5F3A9C2E-...
+  craft bookmark add weekly 'title:"Weekly Sync"'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimPrefix(args[0], bookmarkPrefix)
+		if !bookmarkNameRe.MatchString(name) {
+			return fmt.Errorf("invalid bookmark name %q (use letters, digits, dots, dashes and underscores)", name)
+		}
+		id, err := resolveIDRef(args[1], idDocument)
+		if err != nil {
+			return err
+		}
+		if err := validateResourceID(id, "id"); err != nil {
+			return err
+		}
+		profile, err := bookmarkProfile()
+		if err != nil {
+			return err
+		}
+		if err := cfgManager.SetBookmark(profile, name, id); err != nil {
+			return fmt.Errorf("failed to add bookmark: %w", err)
+		}
+		fmt.Printf("Bookmark @%s → %s\n", name, id)
+		return nil
+	},
+}
+
+var bookmarkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the bookmarks of the profile in effect",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := bookmarkProfile()
+		if err != nil {
+			return err
+		}
+		bookmarks, err := cfgManager.Bookmarks(profile)
+		if err != nil {
+			return err
+		}
+		list := []bookmark{}
+		for name, id := range bookmarks {
+			list = append(list, bookmark{Name: name, ID: id})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		if isJSONFormat(getOutputFormat()) {
+			return outputJSON(list)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !hasNoHeaders() {
+			fmt.Fprintln(w, "NAME\tID")
+			fmt.Fprintln(w, "----\t--")
+		}
+		for _, b := range list {
+			fmt.Fprintf(w, "@%s\t%s\n", b.Name, b.ID)
+		}
+		return w.Flush()
+	},
+}
+
+var bookmarkRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a bookmark",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimPrefix(args[0], bookmarkPrefix)
+		profile, err := bookmarkProfile()
+		if err != nil {
+			return err
+		}
+		if err := cfgManager.RemoveBookmark(profile, name); err != nil {
+			return fmt.Errorf("failed to remove bookmark: %w", err)
+		}
+		fmt.Printf("Bookmark @%s removed\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bookmarkCmd)
+	bookmarkCmd.AddCommand(bookmarkAddCmd)
+	bookmarkCmd.AddCommand(bookmarkListCmd)
+	bookmarkCmd.AddCommand(bookmarkRemoveCmd)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIDReferences(t *testing.T) {
+	useTempConfig(t)
+	titles := map[string]string{"doc-1": "Roadmap", "doc-2": "Roadmap 2027", "w1": "Weekly Sync", "w2": "weekly sync"}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/documents/search":
+			var items []string
+			for _, id := range []string{"doc-1", "doc-2", "w1", "w2"} {
+				if strings.Contains(strings.ToLower(titles[id]), strings.ToLower(r.URL.Query().Get("include"))) {
+					items = append(items, `{"documentId":"`+id+`","markdown":"..."}`, `{"documentId":"`+id+`","markdown":"..."}`)
+				}
+			}
+			w.Write([]byte(`{"items":[` + strings.Join(items, ",") + `]}`))
+		case r.URL.Path == "/blocks" && r.URL.Query().Get("maxDepth") == "0":
+			w.Write([]byte(`{"id":"` + r.URL.Query().Get("id") + `","type":"page","markdown":"` + titles[r.URL.Query().Get("id")] + `"}`))
+		case r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"doc-1","title":"Roadmap"},{"id":"doc-2","title":"Roadmap 2027"}]}`))
+		case r.URL.Path == "/folders":
+			w.Write([]byte(`{"items":[{"id":"f1","name":"Projects"}]}`))
+		default:
+			w.Write([]byte(`{"id":"x","type":"page","markdown":"Doc","content":[]}`))
+		}
+	}))
+	defer server.Close()
+	if err := cfgManager.AddProfile("work", server.URL); err != nil {
+		t.Fatal(err)
+	}
+	requested := func(want string) bool {
+		for _, r := range requests {
+			if strings.Contains(r, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, err := runInProcess([]string{"bookmark", "add", "roadmap", "title:Roadmap"}, ""); err != nil || !requested("/documents/search?include=Roadmap") {
+		t.Fatalf("bookmark add: %v, requests %v", err, requests)
+	}
+	out, err := runInProcess([]string{"bookmark", "list", "--format", "json"}, "")
+	var list []bookmark
+	if err != nil || json.Unmarshal([]byte(out), &list) != nil || len(list) != 1 || list[0] != (bookmark{"roadmap", "doc-1"}) {
+		t.Fatalf("bookmark list = %q, %v", out, err)
+	}
+
+	if _, err := runInProcess([]string{"get", "@roadmap"}, ""); err != nil || !requested("/blocks?id=doc-1") {
+		t.Errorf("get @roadmap: %v, requests %v", err, requests)
+	}
+	if _, err := runInProcess([]string{"list", "--folder", "title:Projects"}, ""); err != nil || !requested("folderId=f1") {
+		t.Errorf("list --folder title:Projects: %v, requests %v", err, requests)
+	}
+
+	batch := `{"op":"documents.move","args":{"id":"@roadmap","folder":"title:Projects"}}`
+	if _, err := runInProcess([]string{"batch"}, batch); err != nil || !requested(`PUT /documents {"documents":[{"id":"doc-1","folderId":"f1"`) {
+		t.Errorf("batch with references: %v, requests %v", err, requests)
+	}
+
+	changes := filepath.Join(t.TempDir(), "changes.yaml")
+	os.WriteFile(changes, []byte("operations:\n  - op: sections.append\n    document: \"@roadmap\"\n    markdown: more\n  - op: documents.create\n    title: New\n    folder: title:Projects\n"), 0644)
+	planOut := filepath.Join(t.TempDir(), "plan.json")
+	if _, err := runInProcess([]string{"plan", "-f", changes, "--out", planOut}, ""); err != nil {
+		t.Fatalf("plan with references: %v", err)
+	}
+	plan, err := readSavedPlan(planOut)
+	if err != nil || plan.Steps[0].Params.Document != "doc-1" || plan.Steps[1].Params.Folder != "f1" {
+		t.Errorf("plan = %+v, %v", plan, err)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"get", `title:"Weekly Sync"`}, []string{`2 documents are titled "Weekly Sync"`, "w1  Weekly Sync", "w2  weekly sync"}},
+		{[]string{"get", "title:Road"}, []string{"did you mean", "doc-1  Roadmap", "doc-2  Roadmap 2027"}},
+		{[]string{"get", "@nope"}, []string{"bookmark @nope not found"}},
+		{[]string{"tasks", "update", "title:Roadmap", "--state", "done"}, []string{"only work for documents"}},
+	} {
+		_, err := runInProcess(tt.args, "")
+		for _, want := range tt.want {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%v: error = %v, want %q", tt.args, err, want)
+			}
+		}
+	}
+
+	if _, err := runInProcess([]string{"bookmark", "remove", "@roadmap"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runInProcess([]string{"get", "@roadmap"}, ""); err == nil {
+		t.Error("expected a removed bookmark to fail")
+	}
+}
+
+// TestIDParamsOf checks the ID parameters derived from command metadata.
+func TestIDParamsOf(t *testing.T) {
+	for path, want := range map[string]idParam{
+		"get":                {Arg: idDocument},
+		"move":               {Arg: idDocument, Flags: map[string]string{"to-folder": idFolder}},
+		"create":             {Flags: map[string]string{"parent": idDocument, "folder": idFolder}},
+		"search":             {Flags: map[string]string{"document": idDocument, "folder": idFolder}},
+		"context":            {Flags: map[string]string{"folder": idFolder}},
+		"upload":             {Flags: map[string]string{"page": idDocument, "sibling": idDocument}},
+		"blocks move":        {Arg: idDocument, Flags: map[string]string{"to": idDocument}},
+		"collections update": {Arg: idPlain, Flags: map[string]string{"item": idPlain}},
+		"folders move":       {Arg: idFolder, Flags: map[string]string{"to": idFolder}},
+		"tasks update":       {Arg: idPlain},
+		"whiteboards create": {Arg: idDocument},
+		"whiteboards delete": {Arg: idPlain},
+		"local append":       {Arg: idDocument},
+		"bookmark add":       {},
+		"plan":               {},
+	} {
+		cmd, _, err := rootCmd.Find(strings.Fields(path))
+		if err != nil || commandKey(cmd) != path {
+			t.Fatalf("%s: no such command", path)
+		}
+		if got := idParamsOf(cmd); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: idParamsOf = %+v, want %+v", path, got, want)
+		}
+	}
+}
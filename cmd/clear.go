@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/ashrafali/craft-cli/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -14,7 +16,8 @@ This does NOT delete the document itself.
 Use craft delete to move the document to trash.
 
 WARNING: This operation is destructive. Use --dry-run to preview first.`,
-	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		docID := args[0]
 		client, err := getAPIClient()
@@ -37,8 +40,8 @@ WARNING: This operation is destructive. Use --dry-run to preview first.`,
 				return n
 			}
 			count := countBlocks(blocks.Content)
-			return dryRunOutput("clear", map[string]interface{}{
-				"id": docID, "block_count": count, "destructive": true,
+			return dryRunOutput("documents.clear", []string{docID}, map[string]interface{}{
+				"block_count": count, "destructive": true,
 			})
 		}
 
@@ -46,8 +49,13 @@ WARNING: This operation is destructive. Use --dry-run to preview first.`,
 		if err != nil {
 			return err
 		}
-		outputCleared(docID, deleted)
-		return nil
+		res := newMutation("documents.clear", docID)
+		res.DeletedIDs = deleted
+		res.Details = map[string]interface{}{"deleted_blocks": len(deleted)}
+		return outputMutation(res, func() error {
+			fmt.Printf("Document %s cleared (%d blocks deleted)\n", docID, len(deleted))
+			return nil
+		})
 	},
 }
 
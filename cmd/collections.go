@@ -47,6 +47,10 @@ Use --document to filter collections by document ID.`,
 			return err
 		}
 
+		if isStreaming() {
+			return finishStream(client.StreamCollections(collectionDocumentID, ndjsonSink[models.Collection](0)))
+		}
+
 		collections, err := client.GetCollections(collectionDocumentID)
 		if err != nil {
 			return err
@@ -99,6 +103,10 @@ Default depth is -1 (no limit).`,
 		}
 
 		collectionID := args[0]
+		if isStreaming() {
+			return finishStream(client.StreamCollectionItems(collectionID, collectionItemDepth, ndjsonSink[models.CollectionItem](0)))
+		}
+
 		items, err := client.GetCollectionItems(collectionID, collectionItemDepth)
 		if err != nil {
 			return err
@@ -124,8 +132,8 @@ Examples:
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if isDryRun() {
-			return dryRunOutput("add collection item", map[string]interface{}{
-				"collection_id": args[0], "title": collectionItemTitle,
+			return dryRunOutput("collections.add_item", []string{args[0]}, map[string]interface{}{
+				"title": collectionItemTitle,
 			})
 		}
 
@@ -148,24 +156,19 @@ Examples:
 			return err
 		}
 
-		if isQuiet() {
+		res := newMutation("collections.add_item", collectionID)
+		for _, item := range result.Items {
+			res.CreatedIDs = append(res.CreatedIDs, item.ID)
+		}
+		res.Result = result
+		return outputMutation(res, func() error {
 			if len(result.Items) > 0 {
-				fmt.Println(result.Items[0].ID)
+				fmt.Printf("Item added: %s (ID: %s)\n", result.Items[0].Title, result.Items[0].ID)
+			} else {
+				fmt.Println("Item added")
 			}
 			return nil
-		}
-
-		format := getOutputFormat()
-		if isJSONFormat(format) {
-			return outputJSON(result)
-		}
-
-		if len(result.Items) > 0 {
-			fmt.Printf("Item added: %s (ID: %s)\n", result.Items[0].Title, result.Items[0].ID)
-		} else {
-			fmt.Println("Item added")
-		}
-		return nil
+		})
 	},
 }
 
@@ -180,8 +183,8 @@ Examples:
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if isDryRun() {
-			return dryRunOutput("update collection item", map[string]interface{}{
-				"collection_id": args[0], "item_id": collectionItemID,
+			return dryRunOutput("collections.update_item", []string{collectionItemID}, map[string]interface{}{
+				"collection_id": args[0],
 			})
 		}
 
@@ -201,22 +204,25 @@ Examples:
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("collections.update_item", collectionItemID)
+		res.Details = map[string]interface{}{"collection_id": collectionID}
+		return outputMutation(res, func() error {
 			fmt.Printf("Item %s updated in collection %s\n", collectionItemID, collectionID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
 var collectionsDeleteCmd = &cobra.Command{
-	Use:   "delete [collection-id]",
-	Short: "Delete an item from a collection",
-	Long:  "Delete an item from a collection by its ID.",
-	Args:  cobra.ExactArgs(1),
+	Use:         "delete [collection-id]",
+	Short:       "Delete an item from a collection",
+	Long:        "Delete an item from a collection by its ID.",
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if isDryRun() {
-			return dryRunOutput("delete collection item", map[string]interface{}{
-				"collection_id": args[0], "item_id": collectionItemID, "destructive": true,
+			return dryRunOutput("collections.delete_item", []string{collectionItemID}, map[string]interface{}{
+				"collection_id": args[0], "destructive": true,
 			})
 		}
 
@@ -230,10 +236,13 @@ var collectionsDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("collections.delete_item", collectionItemID)
+		res.DeletedIDs = []string{collectionItemID}
+		res.Details = map[string]interface{}{"collection_id": collectionID}
+		return outputMutation(res, func() error {
 			fmt.Printf("Item %s deleted from collection %s\n", collectionItemID, collectionID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -32,8 +32,8 @@ Examples:
 		blockID := args[0]
 
 		if isDryRun() {
-			return dryRunOutput("add comment", map[string]interface{}{
-				"block_id": blockID, "content": commentContent,
+			return dryRunOutput("comments.add", []string{blockID}, map[string]interface{}{
+				"content": commentContent,
 			})
 		}
 
@@ -47,24 +47,19 @@ Examples:
 			return err
 		}
 
-		if isQuiet() {
+		res := newMutation("comments.add", blockID)
+		for _, item := range result.Items {
+			res.CreatedIDs = append(res.CreatedIDs, item.CommentID)
+		}
+		res.Result = result
+		return outputMutation(res, func() error {
 			if len(result.Items) > 0 {
-				fmt.Println(result.Items[0].CommentID)
+				fmt.Printf("Comment added: %s\n", result.Items[0].CommentID)
+			} else {
+				fmt.Println("Comment added")
 			}
 			return nil
-		}
-
-		format := getOutputFormat()
-		if isJSONFormat(format) {
-			return outputJSON(result)
-		}
-
-		if len(result.Items) > 0 {
-			fmt.Printf("Comment added: %s\n", result.Items[0].CommentID)
-		} else {
-			fmt.Println("Comment added")
-		}
-		return nil
+		})
 	},
 }
 
@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/ashrafali/craft-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +26,7 @@ Examples:
   # Add a profile with API key authentication
   craft config add secure https://connect.craft.do/.../api/v1 --key pdk_xxx
 
-  # List all profiles (* = active, [key] = has API key)
+  # List all profiles (* = active, [key] = has API key, [secret] = kept in secret store)
   craft config list
 
   # Switch active profile
@@ -122,12 +126,154 @@ var listProfilesCmd = &cobra.Command{
 			if p.HasAPIKey {
 				keyIndicator = " [key]"
 			}
-			fmt.Printf("%s%-12s %s%s\n", marker, p.Name, p.URL, keyIndicator)
+			url := p.URL
+			if p.Secret {
+				keyIndicator += " [secret]"
+				if url == "" {
+					url = "(in secret store)"
+				}
+			}
+			if p.Policy != "" {
+				keyIndicator += " [policy]"
+			}
+			fmt.Printf("%s%-12s %s%s\n", marker, p.Name, url, keyIndicator)
 		}
 		return nil
 	},
 }
 
+var (
+	secretsBackend string
+	secretsGet     string
+	secretsSet     string
+	secretsDelete  string
+)
+
+var migrateSecretsCmd = &cobra.Command{
+	Use:   "migrate-secrets",
+	Short: "Move profile URLs and API keys out of the plaintext config",
+	Long: `Move the URL and API key of every profile into a secret store, leaving
+only references in config.json. Profiles added later are stored there too.
+
+Backends:
+  file     AES-256-GCM encrypted ~/.craft-cli/secrets.enc (default). The key is
+           derived from $CRAFT_SECRET_PASSPHRASE when it is set at migration
+           time (and then required on every run), otherwise from this machine
+           and user, so a copied file cannot be read elsewhere.
+  command  External commands such as pass or secret-tool. {ref} is replaced by
+           the secret's name; --set receives the secret on stdin.
+
+Running it again with another backend moves the secrets over.`,
+	Example: `  craft config migrate-secrets
+  CRAFT_SECRET_PASSPHRASE=... craft config migrate-secrets
+  craft config migrate-secrets --backend command \
+    --get "pass show craft-cli/{ref}" --set "pass insert -m -f craft-cli/{ref}" --delete "pass rm -f craft-cli/{ref}"
+  craft config migrate-secrets --backend command \
+    --get "secret-tool lookup service craft-cli ref {ref}" \
+    --set "secret-tool store --label=craft-cli service craft-cli ref {ref}" \
+    --delete "secret-tool clear service craft-cli ref {ref}"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc := config.SecretsConfig{Backend: secretsBackend, Get: secretsGet, Set: secretsSet, Delete: secretsDelete}
+		moved, err := cfgManager.MigrateSecrets(sc)
+		if err != nil {
+			return fmt.Errorf("failed to migrate secrets: %w", err)
+		}
+		fmt.Printf("Moved secrets of %d profile(s) to the %s backend\n", moved, sc.Backend)
+		return nil
+	},
+}
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file for unknown keys and invalid values",
+	Long: `Check config.json without changing it: unknown keys (such as misspelled
+fields), profiles without a URL, broken secret references, and invalid
+output formats, profile defaults, policy files, aliases and macros.
+
+Files written by older versions of craft are upgraded automatically when they
+are loaded; the original is kept as config.json.v<N>.bak.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, cfg, err := cfgManager.Validate()
+		if err != nil {
+			return err
+		}
+		if cfg != nil {
+			report.Issues = append(report.Issues, validateCLISettings(cfg)...)
+			sort.Strings(report.Issues)
+		}
+
+		if isJSONFormat(getOutputFormat()) {
+			if err := outputJSON(report); err != nil {
+				return err
+			}
+		} else if !report.Exists {
+			fmt.Printf("%s does not exist; defaults are in use\n", report.Path)
+		} else if len(report.Issues) == 0 {
+			fmt.Printf("%s is valid (version %d)\n", report.Path, report.Version)
+		} else {
+			fmt.Printf("%s (version %d):\n", report.Path, report.Version)
+			for _, issue := range report.Issues {
+				fmt.Printf("  - %s\n", issue)
+			}
+		}
+		if len(report.Issues) > 0 {
+			return fmt.Errorf("config file has %d problem(s)", len(report.Issues))
+		}
+		return nil
+	},
+}
+
+// validateCLISettings checks the config values whose valid forms are
+// defined by the CLI rather than the config package.
+func validateCLISettings(cfg *config.Config) []string {
+	var issues []string
+	if !slices.Contains(ValidOutputFormats, cfg.DefaultFormat) {
+		issues = append(issues, fmt.Sprintf("default_format: %q is not one of %s", cfg.DefaultFormat, strings.Join(ValidOutputFormats, ", ")))
+	}
+	for name, profile := range cfg.Profiles {
+		at := "profiles." + name
+		for key, value := range profile.Defaults {
+			d, err := lookupProfileDefault(key)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s.defaults.%s: %v", at, key, err))
+			} else if _, err := d.Parse(value); err != nil {
+				issues = append(issues, fmt.Sprintf("%s.defaults.%s: %v", at, key, err))
+			}
+		}
+		if profile.Policy != "" {
+			if _, err := readPolicyFile(profile.Policy); err != nil {
+				issues = append(issues, fmt.Sprintf("%s.policy: %v", at, err))
+			}
+		}
+		for name, id := range profile.Bookmarks {
+			if !bookmarkNameRe.MatchString(name) {
+				issues = append(issues, fmt.Sprintf("%s.bookmarks.%s: invalid name", at, name))
+			} else if err := validateResourceID(id, "id"); err != nil {
+				issues = append(issues, fmt.Sprintf("%s.bookmarks.%s: %v", at, name, err))
+			}
+		}
+	}
+	for _, uc := range configUserCommands(cfg) {
+		at := "aliases." + uc.Name
+		if uc.Type == "macro" {
+			at = "macros." + uc.Name
+		}
+		if !userCommandNameRe.MatchString(uc.Name) {
+			issues = append(issues, at+": invalid name (use lowercase letters, digits and dashes)")
+		} else if isBuiltinCommand(uc.Name) {
+			issues = append(issues, at+": is a built-in command and is ignored")
+		}
+		for _, step := range uc.Steps {
+			if _, err := splitCommandLine(step); err != nil {
+				issues = append(issues, fmt.Sprintf("%s: %v", at, err))
+			}
+		}
+	}
+	return issues
+}
+
 var forceReset bool
 
 var resetCmd = &cobra.Command{
@@ -170,7 +316,13 @@ func init() {
 	configCmd.AddCommand(useProfileCmd)
 	configCmd.AddCommand(listProfilesCmd)
 	configCmd.AddCommand(resetCmd)
+	configCmd.AddCommand(migrateSecretsCmd)
+	configCmd.AddCommand(validateConfigCmd)
 
 	addProfileCmd.Flags().StringVarP(&profileAPIKey, "key", "k", "", "API key for authentication")
 	resetCmd.Flags().BoolVarP(&forceReset, "force", "f", false, "Skip confirmation prompt")
+	migrateSecretsCmd.Flags().StringVar(&secretsBackend, "backend", config.SecretBackendFile, "Secret backend (file, command)")
+	migrateSecretsCmd.Flags().StringVar(&secretsGet, "get", "", "Command printing the secret {ref} (command backend)")
+	migrateSecretsCmd.Flags().StringVar(&secretsSet, "set", "", "Command storing stdin as the secret {ref} (command backend)")
+	migrateSecretsCmd.Flags().StringVar(&secretsDelete, "delete", "", "Command deleting the secret {ref} (command backend, optional)")
 }
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportWithoutSecrets bool
+	importOnConflict     string
+	importNoVerify       bool
+)
+
+var configExportCmd = &cobra.Command{
+	Use:   "export [profile...]",
+	Short: "Write profiles as a shareable JSON bundle",
+	Long: `Print the named profiles (all by default) as JSON with their URLs, API
+keys, policy paths and defaults, for 'craft config import' on another machine.
+
+With --without-secrets, API keys are left out and the profiles that need one
+are marked, so the bundle can be shared with a team. Public link URLs grant
+access by themselves: share bundles only with people who may use them.`,
+	Example: `  craft config export --without-secrets > team.json
+  craft config export work personal > backup.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundle, err := cfgManager.Export(args, !exportWithoutSecrets)
+		if err != nil {
+			return fmt.Errorf("failed to export profiles: %w", err)
+		}
+		return outputJSON(bundle)
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Add the profiles of a bundle written by config export",
+	Long: `Add every profile of a bundle written by 'craft config export' (- reads
+stdin). Each profile's URL, defaults and policy file are checked, and its
+connection verified, before any profile is saved.
+
+A profile exported without its API key takes it from the configured secret
+backend, stored as <profile>/api_key, or asks for it when run in a terminal.
+
+--on-conflict decides what happens to a profile whose name already exists:
+  skip       keep the existing profile (default)
+  overwrite  replace it
+  rename     import it as <name>-2, <name>-3, ...`,
+	Example: `  craft config import team.json
+  craft config import team.json --on-conflict rename
+  curl -s https://intranet.example.com/craft-team.json | craft config import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importBundle(args[0], importOnConflict, !importNoVerify)
+	},
+}
+
+// importBundle checks every profile of the bundle at path (- for stdin),
+// then saves them all, resolving name clashes per onConflict.
+func importBundle(path, onConflict string, verify bool) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var bundle config.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(bundle.Profiles) == 0 {
+		return fmt.Errorf("%s has no profiles", path)
+	}
+
+	names := make([]string, 0, len(bundle.Profiles))
+	for name := range bundle.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	connections := map[string]*models.ConnectionInfo{}
+	reader := bufio.NewReader(os.Stdin)
+	for _, name := range names {
+		p := bundle.Profiles[name]
+		if p.Policy != "" {
+			if _, err := os.Stat(p.Policy); errors.Is(err, fs.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Warning: profile '%s': policy file %s does not exist here; importing without a policy\n", name, p.Policy)
+				p.Policy = ""
+				bundle.Profiles[name] = p
+			}
+		}
+		if err := checkImportedProfile(p); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		key := p.APIKey
+		if p.NeedsAPIKey && key == "" && onConflict != config.ConflictRename {
+			// An existing profile keeps its own key.
+			key = localAPIKey(name)
+		}
+		if p.NeedsAPIKey && key == "" {
+			if p.APIKey = importAPIKey(reader, name); p.APIKey == "" {
+				problems = append(problems, fmt.Sprintf("%s: needs an API key; store it in the secret backend as %s/api_key, or import in a terminal", name, name))
+				continue
+			}
+			key = p.APIKey
+			bundle.Profiles[name] = p
+		}
+		if verify {
+			info, err := verifyConnection(p.URL, key)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			connections[name] = info
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("nothing imported:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	for _, name := range names {
+		saved, err := cfgManager.ImportProfile(name, bundle.Profiles[name], onConflict)
+		if err != nil {
+			return fmt.Errorf("failed to import profile %s: %w", name, err)
+		}
+		switch {
+		case saved == "":
+			fmt.Printf("Profile '%s' skipped: it already exists\n", name)
+			continue
+		case saved != name:
+			fmt.Printf("Profile '%s' imported as '%s'", name, saved)
+		default:
+			fmt.Printf("Profile '%s' imported", name)
+		}
+		if info := connections[name]; info != nil {
+			fmt.Printf(" (%s)\n", describeConnection(info))
+		} else {
+			fmt.Println(" (not verified)")
+		}
+	}
+	return nil
+}
+
+// checkImportedProfile rejects profiles without a URL and defaults or
+// policy files that craft config set would refuse. Policy files missing
+// on this machine are dropped by the caller first.
+func checkImportedProfile(p config.BundleProfile) error {
+	if p.URL == "" {
+		return fmt.Errorf("url is missing")
+	}
+	if problem := craftURLProblem(p.URL); problem != "" {
+		return fmt.Errorf("%s", problem)
+	}
+	for key, value := range p.Defaults {
+		d, err := lookupProfileDefault(key)
+		if err != nil {
+			return err
+		}
+		if _, err := d.Parse(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	if p.Policy != "" {
+		if _, err := readPolicyFile(p.Policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localAPIKey returns the API key of the existing profile name, if any.
+func localAPIKey(name string) string {
+	bundle, err := cfgManager.Export([]string{name}, true)
+	if err != nil {
+		return ""
+	}
+	return bundle.Profiles[name].APIKey
+}
+
+// importAPIKey finds the API key of an imported profile in the secret
+// backend, or asks for it when stdin is a terminal.
+func importAPIKey(reader *bufio.Reader, name string) string {
+	if key, err := cfgManager.BackendAPIKey(name); err == nil && key != "" {
+		return key
+	}
+	if !isTerminal(os.Stdin) {
+		return ""
+	}
+	fmt.Fprintf(os.Stderr, "API key for profile '%s': ", name)
+	key, _ := reader.ReadString('\n')
+	return strings.TrimSpace(key)
+}
+
+func init() {
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configExportCmd.Flags().BoolVar(&exportWithoutSecrets, "without-secrets", false, "Leave out API keys")
+	configImportCmd.Flags().StringVar(&importOnConflict, "on-conflict", config.ConflictSkip, "What to do with existing profiles: skip, overwrite or rename")
+	configImportCmd.Flags().BoolVar(&importNoVerify, "no-verify", false, "Import without checking the connections")
+}
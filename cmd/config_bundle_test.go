@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+)
+
+func TestConfigExportImport(t *testing.T) {
+	useTempConfig(t)
+	url := connectionServer(t, "space-1", "pdk_secret")
+	for _, args := range [][]string{
+		{"config", "add", "work", url, "--key", "pdk_secret"},
+		{"config", "set", "work", "format", "table"},
+		{"config", "migrate-secrets"},
+	} {
+		if out, err := runInProcess(args, ""); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+	}
+
+	bundle, err := runInProcess([]string{"config", "export", "--without-secrets"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(bundle, "pdk_secret") || !strings.Contains(bundle, `"needs_api_key": true`) || !strings.Contains(bundle, url) {
+		t.Fatalf("bundle:\n%s", bundle)
+	}
+	team := filepath.Join(t.TempDir(), "team.json")
+	os.WriteFile(team, []byte(bundle), 0600)
+
+	// The key is found in the secret backend under work/api_key.
+	out, err := runInProcess([]string{"config", "import", team, "--on-conflict", "rename"}, "")
+	if err != nil || !strings.Contains(out, "'work' imported as 'work-2' (space space-1") {
+		t.Fatalf("import = %q, %v", out, err)
+	}
+	if defaults, _ := cfgManager.ProfileDefaults("work-2"); defaults["format"] != "table" {
+		t.Errorf("work-2 defaults = %v", defaults)
+	}
+	if out, err := runInProcess([]string{"config", "import", team}, ""); err != nil || !strings.Contains(out, "skipped") {
+		t.Errorf("default import of an existing profile = %q, %v", out, err)
+	}
+
+	// Elsewhere, without a terminal or the key in a backend, nothing is imported.
+	useTempConfig(t)
+	if _, err := runInProcess([]string{"config", "import", team}, ""); err == nil || !strings.Contains(err.Error(), "needs an API key") {
+		t.Errorf("error = %v, want the missing key reported", err)
+	}
+	if profiles, _ := cfgManager.ListProfiles(); len(profiles) != 0 {
+		t.Errorf("profiles = %+v, want none", profiles)
+	}
+
+	// Overwriting a local profile keeps its key; a policy path missing
+	// here is dropped rather than failing the import.
+	if _, err := runInProcess([]string{"config", "add", "work", url, "--key", "pdk_secret"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	var b config.Bundle
+	json.Unmarshal([]byte(bundle), &b)
+	work := b.Profiles["work"]
+	work.Policy = filepath.Join(t.TempDir(), "missing.yaml")
+	b.Profiles["work"] = work
+	data, _ := json.Marshal(b)
+	elsewhere := filepath.Join(t.TempDir(), "elsewhere.json")
+	os.WriteFile(elsewhere, data, 0600)
+	if out, err := runInProcess([]string{"config", "import", elsewhere, "--on-conflict", "overwrite"}, ""); err != nil || !strings.Contains(out, "'work' imported (space space-1") {
+		t.Fatalf("overwrite import = %q, %v", out, err)
+	}
+	if key, _ := cfgManager.GetActiveAPIKey(); key != "pdk_secret" {
+		t.Errorf("API key after overwrite = %q", key)
+	}
+	if policy, _ := cfgManager.GetActivePolicy(); policy != "" {
+		t.Errorf("policy = %q, want the missing file dropped", policy)
+	}
+
+	// --no-verify skips the connection, not the URL check.
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	os.WriteFile(bad, []byte(strings.ReplaceAll(bundle, url, "https://example.com/links/x")), 0600)
+	if _, err := runInProcess([]string{"config", "import", bad, "--no-verify"}, ""); err == nil || !strings.Contains(err.Error(), "/api/v1") {
+		t.Errorf("--no-verify with a malformed URL error = %v", err)
+	}
+}
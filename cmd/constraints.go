@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Value formats checked by flag constraints.
+const (
+	flagFormatDate  = "date"  // YYYY-MM-DD
+	flagFormatDay   = "day"   // YYYY-MM-DD, today, yesterday or tomorrow
+	flagFormatColor = "color" // #RRGGBB
+)
+
+// flagCondition is satisfied when Flag is set and, if Values is non-empty,
+// holds one of Values.
+type flagCondition struct {
+	Flag   string
+	Values []string
+}
+
+func (c flagCondition) String() string {
+	if len(c.Values) == 0 {
+		return "--" + c.Flag
+	}
+	return "--" + c.Flag + "=" + strings.Join(c.Values, "|")
+}
+
+// flagConstraint restricts the value of a flag and how it combines with
+// other flags. Constraints are only checked for flags given on the command
+// line, so defaults never trip them.
+type flagConstraint struct {
+	Enum []string
+	// List means the value is comma-separated and each item must be in Enum.
+	List      bool
+	Format    string
+	Conflicts []string
+	Requires  []flagCondition
+	// RequiresWhen lists conditions that must hold when the flag has a
+	// particular value.
+	RequiresWhen map[string][]flagCondition
+}
+
+// stylingConstraints apply to the styling flags shared by blocks add and
+// update (--type is only registered on add).
+var stylingConstraints = map[string]flagConstraint{
+	"type":              {Enum: []string{"text", "page", "code", "line", "richUrl", "image", "file"}},
+	"text-style":        {Enum: []string{"h1", "h2", "h3", "h4", "caption", "body", "page", "card"}},
+	"list-style":        {Enum: []string{"none", "bullet", "numbered", "task", "toggle"}},
+	"decorations":       {Enum: []string{"callout", "quote"}, List: true},
+	"color":             {Format: flagFormatColor},
+	"font":              {Enum: []string{"system", "serif", "mono", "rounded"}},
+	"text-alignment":    {Enum: []string{"left", "center", "right", "justify"}},
+	"indentation-level": {Enum: []string{"0", "1", "2", "3", "4", "5"}},
+	"line-style":        {Enum: []string{"strong", "regular", "light", "extraLight", "pageBreak"}},
+	"layout":            {Enum: []string{"small", "regular", "card"}},
+	"block-layout":      {Enum: []string{"small", "regular", "card"}},
+	"card-layout":       {Enum: []string{"small", "square", "regular", "large"}},
+	"task-state":        {Enum: []string{"todo", "done", "canceled"}},
+	"schedule-date":     {Format: flagFormatDate},
+	"deadline-date":     {Format: flagFormatDate},
+}
+
+var (
+	locationEnum  = []string{"unsorted", "trash", "templates", "daily_notes"}
+	dateRangeKeys = []string{"created-after", "created-before", "modified-after", "modified-before"}
+)
+
+// flagConstraints maps a command path (as in commandOutputs; "" for the
+// root's global flags) to constraints on its flags.
+var flagConstraints = map[string]map[string]flagConstraint{
+	"": {
+		"format": {Enum: ValidOutputFormats},
+		"color":  {Enum: []string{ColorAuto, ColorAlways, ColorNever}},
+	},
+	"list": withDateRange(map[string]flagConstraint{
+		"location": {Enum: locationEnum},
+	}),
+	"search": withDateRange(map[string]flagConstraint{
+		"location": {Enum: locationEnum},
+	}),
+	"context": {
+		"location": {Enum: locationEnum},
+	},
+	"config migrate-secrets": {
+		"backend": {
+			Enum:         []string{"file", "command"},
+			RequiresWhen: map[string][]flagCondition{"command": {{Flag: "get"}, {Flag: "set"}}},
+		},
+		"get":    {Requires: []flagCondition{{Flag: "backend", Values: []string{"command"}}}},
+		"set":    {Requires: []flagCondition{{Flag: "backend", Values: []string{"command"}}}},
+		"delete": {Requires: []flagCondition{{Flag: "backend", Values: []string{"command"}}}},
+	},
+	"config import": {
+		"on-conflict": {Enum: []string{"skip", "overwrite", "rename"}},
+	},
+	"setup": {
+		"import":    {Conflicts: []string{"name", "url", "key-stdin"}},
+		"name":      {Requires: []flagCondition{{Flag: "non-interactive"}}},
+		"url":       {Requires: []flagCondition{{Flag: "non-interactive"}}},
+		"key-stdin": {Requires: []flagCondition{{Flag: "non-interactive"}}},
+	},
+	"create": {
+		"stdin":           {Conflicts: []string{"file", "batch"}},
+		"idempotency-key": {Conflicts: []string{"batch"}},
+	},
+	"update": {
+		"mode":    {Enum: []string{"append", "replace"}},
+		"section": {Requires: []flagCondition{{Flag: "mode", Values: []string{"replace"}}}},
+		"stdin":   {Conflicts: []string{"file"}},
+	},
+	"move": {
+		"to-location": {Enum: []string{"unsorted", "trash"}, Conflicts: []string{"to-folder"}},
+	},
+	"blocks get": {
+		"date": {Format: flagFormatDay},
+	},
+	"blocks add": withStyling(map[string]flagConstraint{
+		"position": {
+			Enum: []string{"start", "end", "before", "after"},
+			RequiresWhen: map[string][]flagCondition{
+				"before": {{Flag: "sibling"}},
+				"after":  {{Flag: "sibling"}},
+			},
+		},
+		"sibling": {
+			Conflicts: []string{"date"},
+			Requires:  []flagCondition{{Flag: "position", Values: []string{"before", "after"}}},
+		},
+		"date": {Format: flagFormatDay},
+		"json": {Conflicts: []string{"markdown"}},
+	}),
+	"blocks update": withoutFlag(withStyling(map[string]flagConstraint{
+		"json": {Conflicts: []string{"markdown"}},
+	}), "type"),
+	"blocks move": {
+		"position": {Enum: []string{"start", "end"}},
+	},
+	"tasks list": {
+		"scope": {Enum: []string{"active", "upcoming", "inbox", "logbook"}},
+	},
+	"tasks add": {
+		"location": {
+			Enum:         []string{"inbox", "document"},
+			RequiresWhen: map[string][]flagCondition{"document": {{Flag: "document"}}},
+		},
+		"schedule": {Format: flagFormatDate},
+		"deadline": {Format: flagFormatDate},
+	},
+	"tasks update": {
+		"state":    {Enum: []string{"todo", "done", "canceled"}},
+		"schedule": {Format: flagFormatDate},
+		"deadline": {Format: flagFormatDate},
+	},
+	"collections schema": {
+		"schema-format": {Enum: []string{"schema", "json-schema-items"}},
+	},
+	"upload": {
+		"position": {Enum: []string{"start", "end", "before", "after"}},
+		"date":     {Format: flagFormatDay},
+	},
+	"local space": {
+		"tab": {Enum: []string{"calendar", "search", "documents"}},
+	},
+}
+
+func withStyling(m map[string]flagConstraint) map[string]flagConstraint {
+	for name, c := range stylingConstraints {
+		if _, ok := m[name]; !ok {
+			m[name] = c
+		}
+	}
+	return m
+}
+
+func withoutFlag(m map[string]flagConstraint, name string) map[string]flagConstraint {
+	delete(m, name)
+	return m
+}
+
+func withDateRange(m map[string]flagConstraint) map[string]flagConstraint {
+	for _, name := range dateRangeKeys {
+		m[name] = flagConstraint{Format: flagFormatDate}
+	}
+	return m
+}
+
+// lookupFlagConstraint returns the constraint on flag name for cmd, falling
+// back to the global constraints for persistent root flags.
+func lookupFlagConstraint(cmd *cobra.Command, name string) (flagConstraint, bool) {
+	if c, ok := flagConstraints[commandKey(cmd)][name]; ok {
+		return c, true
+	}
+	if cmd.Root().PersistentFlags().Lookup(name) != nil {
+		c, ok := flagConstraints[""][name]
+		return c, ok
+	}
+	return flagConstraint{}, false
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// checkFlagFormat validates value against a flag format.
+func checkFlagFormat(format, value string) error {
+	switch format {
+	case flagFormatDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("expected a date as YYYY-MM-DD")
+		}
+	case flagFormatDay:
+		switch value {
+		case "today", "yesterday", "tomorrow":
+			return nil
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("expected YYYY-MM-DD, today, yesterday or tomorrow")
+		}
+	case flagFormatColor:
+		if !colorPattern.MatchString(value) {
+			return fmt.Errorf("expected a #RRGGBB hex color")
+		}
+	}
+	return nil
+}
+
+// conditionHolds reports whether cond is met by the flags of cmd.
+func conditionHolds(cmd *cobra.Command, cond flagCondition) bool {
+	f := cmd.Flags().Lookup(cond.Flag)
+	if f == nil {
+		return false
+	}
+	if len(cond.Values) == 0 {
+		return f.Changed && f.Value.String() != ""
+	}
+	return containsString(cond.Values, f.Value.String())
+}
+
+// validateFlagConstraints checks the flags set on cmd against the registry.
+func validateFlagConstraints(cmd *cobra.Command) error {
+	var err error
+	// VisitAll with Changed rather than Visit: in-process runs reset Changed
+	// but cannot clear pflag's record of previously set flags.
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || !f.Changed {
+			return
+		}
+		c, ok := lookupFlagConstraint(cmd, f.Name)
+		if !ok {
+			return
+		}
+		err = checkFlagConstraint(cmd, f, c)
+	})
+	return err
+}
+
+func checkFlagConstraint(cmd *cobra.Command, f *pflag.Flag, c flagConstraint) error {
+	value := f.Value.String()
+
+	if len(c.Enum) > 0 {
+		items := []string{value}
+		if c.List {
+			items = strings.Split(value, ",")
+		}
+		for _, item := range items {
+			item = strings.TrimSpace(item)
+			if !containsString(c.Enum, item) {
+				return fmt.Errorf("invalid --%s value %q (expected one of: %s)", f.Name, item, strings.Join(c.Enum, ", "))
+			}
+		}
+	}
+
+	if c.Format != "" {
+		if err := checkFlagFormat(c.Format, value); err != nil {
+			return fmt.Errorf("invalid --%s value %q: %w", f.Name, value, err)
+		}
+	}
+
+	for _, other := range c.Conflicts {
+		if o := cmd.Flags().Lookup(other); o != nil && o.Changed {
+			return fmt.Errorf("--%s cannot be used with --%s", f.Name, other)
+		}
+	}
+
+	for _, cond := range c.Requires {
+		if !conditionHolds(cmd, cond) {
+			return fmt.Errorf("--%s requires %s", f.Name, cond)
+		}
+	}
+	for _, cond := range c.RequiresWhen[value] {
+		if !conditionHolds(cmd, cond) {
+			return fmt.Errorf("--%s=%s requires %s", f.Name, value, cond)
+		}
+	}
+	return nil
+}
+
+// isRequiredFlag reports whether f was marked with MarkFlagRequired.
+func isRequiredFlag(f *pflag.Flag) bool {
+	req := f.Annotations[cobra.BashCompOneRequiredFlag]
+	return len(req) > 0 && req[0] == "true"
+}
+
+// describeConditions renders conditions for schema output.
+func describeConditions(conds []flagCondition) []string {
+	var out []string
+	for _, c := range conds {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+// describeRequiresWhen renders value-dependent requirements for schema output.
+func describeRequiresWhen(m map[string][]flagCondition) map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := map[string][]string{}
+	for v, conds := range m {
+		out[v] = describeConditions(conds)
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlagConstraintsReferToRealFlags(t *testing.T) {
+	for key, flags := range flagConstraints {
+		target := rootCmd
+		if key != "" {
+			var err error
+			target, _, err = rootCmd.Find(strings.Fields(key))
+			if err != nil || commandKey(target) != key {
+				t.Errorf("flagConstraints has %q but no such command", key)
+				continue
+			}
+		}
+		for name, c := range flags {
+			if target.Flags().Lookup(name) == nil && target.PersistentFlags().Lookup(name) == nil {
+				t.Errorf("%q: no flag --%s", key, name)
+			}
+			var refs []string
+			refs = append(refs, c.Conflicts...)
+			for _, cond := range c.Requires {
+				refs = append(refs, cond.Flag)
+			}
+			for _, conds := range c.RequiresWhen {
+				for _, cond := range conds {
+					refs = append(refs, cond.Flag)
+				}
+			}
+			for _, ref := range refs {
+				if target.Flags().Lookup(ref) == nil {
+					t.Errorf("%q --%s refers to unknown flag --%s", key, name, ref)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateFlagConstraints(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"update", "d1", "--mode", "merge"}, `invalid --mode value "merge" (expected one of: append, replace)`},
+		{[]string{"update", "d1", "--section", "Intro", "--markdown", "x"}, "--section requires --mode=replace"},
+		{[]string{"update", "d1", "--stdin", "--file", "a.md"}, "--stdin cannot be used with --file"},
+		{[]string{"blocks", "add", "d1", "-m", "x", "--sibling", "b1"}, "--sibling requires --position=before|after"},
+		{[]string{"blocks", "add", "d1", "-m", "x", "--position", "after"}, "--position=after requires --sibling"},
+		{[]string{"blocks", "update", "b1", "--color", "red"}, "expected a #RRGGBB hex color"},
+		{[]string{"blocks", "update", "b1", "--decorations", "callout,box"}, `invalid --decorations value "box"`},
+		{[]string{"tasks", "add", "x", "--location", "document"}, "--location=document requires --document"},
+		{[]string{"tasks", "update", "t1", "--deadline", "tomorrow"}, "expected a date as YYYY-MM-DD"},
+		{[]string{"list", "--location", "archive"}, `invalid --location value "archive"`},
+		{[]string{"list", "--format", "yaml"}, `invalid --format value "yaml"`},
+		{[]string{"list", "--color", "sometimes"}, `invalid --color value "sometimes"`},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, err := runInProcess(tt.args, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := runInProcess([]string{"--api-url", "http://127.0.0.1:1", "blocks", "update", "b1",
+		"--decorations", "callout,quote", "--color", "#ef052a", "--dry-run"}, ""); err != nil {
+		t.Errorf("valid flags rejected: %v", err)
+	}
+}
+
+func TestSchemaReportsFlagConstraints(t *testing.T) {
+	flag := func(path, name string) FlagSchema {
+		target, _, _ := rootCmd.Find(strings.Fields(path))
+		for _, f := range buildSchema(target).Flags {
+			if f.Name == name {
+				return f
+			}
+		}
+		t.Fatalf("%s has no flag %s", path, name)
+		return FlagSchema{}
+	}
+
+	if f := flag("update", "--mode"); strings.Join(f.Enum, ",") != "append,replace" {
+		t.Errorf("--mode enum = %v", f.Enum)
+	}
+	if f := flag("update", "--section"); strings.Join(f.Requires, ",") != "--mode=replace" {
+		t.Errorf("--section requires = %v", f.Requires)
+	}
+	if f := flag("collections add", "--title"); !f.Required {
+		t.Error("collections add --title should be required")
+	}
+	if f := flag("tasks add", "--location"); strings.Join(f.RequiresWhen["document"], ",") != "--document" {
+		t.Errorf("--location requires_when = %v", f.RequiresWhen)
+	}
+	if f := flag("blocks add", "--color"); f.Format != flagFormatColor {
+		t.Errorf("--color format = %q", f.Format)
+	}
+}
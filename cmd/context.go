@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextFolder   string
+	contextLocation string
+	contextQuery    string
+	contextBudget   int
+	contextLimit    int
+)
+
+// minContextSourceTokens is the smallest share worth spending on a source;
+// below it the remaining candidates are listed as omitted.
+const minContextSourceTokens = 64
+
+const contextOmittedPrefix = "\nOmitted to fit the budget: "
+
+// contextSource is one document in a context pack.
+type contextSource struct {
+	Index          int    `json:"index"`
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Link           string `json:"link,omitempty"`
+	LastModifiedAt string `json:"lastModifiedAt,omitempty"`
+	Hits           int    `json:"hits,omitempty"`
+	Tokens         int    `json:"tokens"`
+	Truncated      bool   `json:"truncated"`
+	Markdown       string `json:"markdown"`
+}
+
+// contextPack is the JSON form of craft context.
+type contextPack struct {
+	Query   string          `json:"query,omitempty"`
+	Folder  string          `json:"folder,omitempty"`
+	Budget  int             `json:"budget"`
+	Tokens  int             `json:"tokens"`
+	Sources []contextSource `json:"sources"`
+	Omitted []string        `json:"omitted"`
+}
+
+// contextCandidate is a document considered for the pack.
+type contextCandidate struct {
+	doc  models.Document
+	hits int
+}
+
+// contextLine is one block of document markdown.
+type contextLine struct {
+	text    string
+	heading bool
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Build a prompt-ready context pack within a token budget",
+	Long: `Pick relevant documents, fetch their markdown and trim it to fit an
+estimated token budget, printing one bundle with numbered sources for citation.
+
+With --query, documents are ranked by the number of search hits, then by most
+recent modification; without it, the most recently modified documents are used.
+Each source gets a fair share of the budget (unused tokens roll over to the
+next). Headings are kept when a document is trimmed so its structure survives;
+trimmed passages are marked with "…".
+
+Tokens are estimated at about 4 characters per token.
+
+Prints markdown by default; --format json or compact prints the pack as JSON.
+
+Examples:
+  craft context --query "roadmap" --budget 8000
+  craft context --folder abc123 --query "roadmap" --limit 5
+  craft context --location daily_notes --budget 4000
+  craft context --query "launch" --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if contextBudget <= 0 {
+			return fmt.Errorf("--budget must be positive")
+		}
+		if contextLimit <= 0 {
+			return fmt.Errorf("--limit must be positive")
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+
+		candidates, err := contextCandidates(client)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			printStatus("No matching documents found\n")
+		}
+
+		pack, err := buildContextPack(client, candidates, contextLinkTemplate(client))
+		if err != nil {
+			return err
+		}
+
+		if cmd.Root().PersistentFlags().Changed("format") && isJSONFormat(getOutputFormat()) {
+			return outputJSON(pack)
+		}
+		fmt.Print(renderContextPack(pack))
+		return nil
+	},
+}
+
+// contextCandidates ranks documents by search hits and recency, keeping
+// at most --limit.
+func contextCandidates(client *api.Client) ([]contextCandidate, error) {
+	docs, err := client.GetDocumentsAdvanced(api.ListDocumentsOptions{
+		FolderID:      contextFolder,
+		Location:      contextLocation,
+		FetchMetadata: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []models.SearchItem
+	if contextQuery != "" {
+		result, err := client.SearchDocumentsAdvanced(contextQuery, api.SearchOptions{
+			Location:  contextLocation,
+			FolderIDs: contextFolder,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hits = result.Items
+	}
+
+	candidates := rankContextCandidates(docs.Items, hits, contextQuery != "")
+	if len(candidates) > contextLimit {
+		candidates = candidates[:contextLimit]
+	}
+	return candidates, nil
+}
+
+// rankContextCandidates orders documents by search hits (when searched),
+// then by most recent modification. When searched, documents without hits
+// are dropped; hits on documents missing from docs are kept by ID.
+func rankContextCandidates(docs []models.Document, hits []models.SearchItem, searched bool) []contextCandidate {
+	var candidates []contextCandidate
+	index := map[string]int{}
+	for _, d := range docs {
+		index[d.ID] = len(candidates)
+		candidates = append(candidates, contextCandidate{doc: d})
+	}
+	for _, h := range hits {
+		i, ok := index[h.DocumentID]
+		if !ok {
+			i = len(candidates)
+			index[h.DocumentID] = i
+			candidates = append(candidates, contextCandidate{doc: models.Document{ID: h.DocumentID}})
+		}
+		candidates[i].hits++
+	}
+
+	if searched {
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if c.hits > 0 {
+				kept = append(kept, c)
+			}
+		}
+		candidates = kept
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].hits != candidates[j].hits {
+			return candidates[i].hits > candidates[j].hits
+		}
+		return candidates[i].doc.LastModifiedAt.After(candidates[j].doc.LastModifiedAt)
+	})
+	return candidates
+}
+
+// contextLinkTemplate returns the space's deep link template with the space
+// ID filled in, or "" if the connection cannot be read.
+func contextLinkTemplate(client *api.Client) string {
+	info, err := client.GetConnection()
+	if err != nil || info.URLTemplates.App == "" {
+		return ""
+	}
+	return strings.ReplaceAll(info.URLTemplates.App, "{spaceId}", info.Space.ID)
+}
+
+// buildContextPack fetches candidates in order, giving each an equal share
+// of the budget left and rolling unused tokens over to the next.
+func buildContextPack(client *api.Client, candidates []contextCandidate, linkTemplate string) (*contextPack, error) {
+	pack := &contextPack{
+		Query:   contextQuery,
+		Folder:  contextFolder,
+		Budget:  contextBudget,
+		Sources: []contextSource{},
+		Omitted: []string{},
+	}
+	// Reserve the header at its widest: every candidate included, budget used.
+	remaining := contextBudget - estimateTokens(packHeader(pack.Query, len(candidates), contextBudget, contextBudget))
+
+	for i, c := range candidates {
+		share := remaining / (len(candidates) - i)
+		if share < minContextSourceTokens {
+			if len(pack.Omitted) == 0 {
+				remaining -= estimateTokens(contextOmittedPrefix)
+			}
+			remaining -= estimateTokens(c.doc.ID + ", ")
+			pack.Omitted = append(pack.Omitted, c.doc.ID)
+			continue
+		}
+
+		resp, err := client.GetDocumentBlocks(c.doc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch document %s: %w", c.doc.ID, err)
+		}
+
+		src := contextSource{
+			Index: len(pack.Sources) + 1,
+			ID:    c.doc.ID,
+			Title: c.doc.Title,
+			Link:  c.doc.ClickableLink,
+			Hits:  c.hits,
+		}
+		if src.Title == "" {
+			src.Title = oneLine(resp.Markdown)
+		}
+		if src.Link == "" && linkTemplate != "" {
+			src.Link = strings.ReplaceAll(linkTemplate, "{blockId}", c.doc.ID)
+		}
+		if !c.doc.LastModifiedAt.IsZero() {
+			src.LastModifiedAt = c.doc.LastModifiedAt.UTC().Format(time.RFC3339)
+		}
+
+		headerTokens := estimateTokens(contextSourceHeader(src))
+		src.Markdown, src.Truncated = fitContextLines(contextLines(&resp), share-headerTokens)
+		src.Tokens = headerTokens + estimateTokens(src.Markdown+"\n")
+		remaining -= src.Tokens
+		pack.Sources = append(pack.Sources, src)
+	}
+
+	pack.Tokens = estimateTokens(renderContextPack(pack))
+	return pack, nil
+}
+
+// contextLines flattens a document's blocks into compacted markdown lines.
+func contextLines(resp *models.BlocksResponse) []contextLine {
+	var lines []contextLine
+	var walk func(b *models.Block)
+	walk = func(b *models.Block) {
+		if text := compactMarkdown(b.Markdown); text != "" {
+			heading := strings.HasPrefix(text, "#")
+			switch b.TextStyle {
+			case "h1", "h2", "h3", "h4":
+				heading = true
+			}
+			lines = append(lines, contextLine{text: text, heading: heading})
+		}
+		for i := range b.Content {
+			walk(&b.Content[i])
+		}
+	}
+	for i := range resp.Content {
+		walk(&resp.Content[i])
+	}
+	return lines
+}
+
+// compactMarkdown trims trailing spaces and collapses blank lines.
+func compactMarkdown(s string) string {
+	var out []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// fitContextLines joins lines within budget tokens. When they do not fit,
+// headings are kept and each section's body gets an equal share of what is
+// left (unused tokens roll over); omitted passages become "…".
+func fitContextLines(lines []contextLine, budget int) (string, bool) {
+	cost := func(l contextLine) int { return estimateTokens(l.text + "\n") }
+
+	total := 0
+	for _, l := range lines {
+		total += cost(l)
+	}
+	if total <= budget {
+		return joinContextLines(lines, nil), false
+	}
+
+	// Group lines into sections, each starting at a heading.
+	var sections [][]int
+	headingCost := 0
+	for i, l := range lines {
+		if l.heading || len(sections) == 0 {
+			sections = append(sections, nil)
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], i)
+		if l.heading {
+			headingCost += cost(l)
+		}
+	}
+
+	keep := make([]bool, len(lines))
+	left := budget
+	if headingCost > budget {
+		// Not even the outline fits: keep leading headings only.
+		for i, l := range lines {
+			if l.heading && cost(l) <= left {
+				keep[i] = true
+				left -= cost(l)
+			} else if l.heading {
+				break
+			}
+		}
+		return joinContextLines(lines, keep), true
+	}
+
+	left -= headingCost
+	for s, section := range sections {
+		share := left / (len(sections) - s)
+		for _, i := range section {
+			if lines[i].heading {
+				keep[i] = true
+				continue
+			}
+			// Reserve room for the "…" marker.
+			if c := cost(lines[i]); c+1 <= share {
+				keep[i] = true
+				share -= c
+				left -= c
+			} else {
+				break
+			}
+		}
+	}
+	return joinContextLines(lines, keep), true
+}
+
+// joinContextLines joins the kept lines (all if keep is nil), replacing each
+// run of dropped lines with "…".
+func joinContextLines(lines []contextLine, keep []bool) string {
+	var out []string
+	dropped := false
+	for i, l := range lines {
+		if keep != nil && !keep[i] {
+			dropped = true
+			continue
+		}
+		if dropped {
+			out = append(out, "…")
+			dropped = false
+		}
+		out = append(out, l.text)
+	}
+	if dropped {
+		out = append(out, "…")
+	}
+	return strings.Join(out, "\n")
+}
+
+func contextPackHeader(pack *contextPack) string {
+	return packHeader(pack.Query, len(pack.Sources), pack.Tokens, pack.Budget)
+}
+
+func packHeader(query string, sources, tokens, budget int) string {
+	title := "# Context pack"
+	if query != "" {
+		title += fmt.Sprintf(": %q", query)
+	}
+	return fmt.Sprintf("%s\n%d source(s), ~%d of %d tokens. Cite sources as [n].\n",
+		title, sources, tokens, budget)
+}
+
+func contextSourceHeader(src contextSource) string {
+	meta := []string{"id " + src.ID}
+	if src.LastModifiedAt != "" {
+		meta = append(meta, "modified "+src.LastModifiedAt)
+	}
+	if src.Link != "" {
+		meta = append(meta, src.Link)
+	}
+	return fmt.Sprintf("\n## [%d] %s\nSource: %s\n\n", src.Index, src.Title, strings.Join(meta, " · "))
+}
+
+// renderContextPack prints the pack as prompt-ready markdown.
+func renderContextPack(pack *contextPack) string {
+	var sb strings.Builder
+	sb.WriteString(contextPackHeader(pack))
+	for _, src := range pack.Sources {
+		sb.WriteString(contextSourceHeader(src))
+		if src.Markdown != "" {
+			sb.WriteString(src.Markdown)
+			sb.WriteString("\n")
+		}
+	}
+	if len(pack.Omitted) > 0 {
+		fmt.Fprintf(&sb, "%s%s\n", contextOmittedPrefix, strings.Join(pack.Omitted, ", "))
+	}
+	return sb.String()
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.Flags().StringVar(&contextFolder, "folder", "", "Folder ID to take documents from")
+	contextCmd.Flags().StringVar(&contextLocation, "location", "", "Only use documents in a location: unsorted, trash, templates, daily_notes")
+	contextCmd.Flags().StringVar(&contextQuery, "query", "", "Rank documents by search hits for this query")
+	contextCmd.Flags().IntVar(&contextBudget, "budget", 8000, "Estimated token budget for the whole pack")
+	contextCmd.Flags().IntVar(&contextLimit, "limit", 10, "Maximum number of documents to consider")
+}
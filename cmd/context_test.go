@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/jsonschema"
+)
+
+// contextServer serves three documents; "roadmap" hits d2 twice and d1 once.
+func contextServer(bodyLines int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/documents":
+			w.Write([]byte(`{"items":[
+				{"id":"d1","title":"Old plan","lastModifiedAt":"2025-01-01T00:00:00Z"},
+				{"id":"d2","title":"Roadmap","lastModifiedAt":"2025-02-01T00:00:00Z"},
+				{"id":"d3","title":"Groceries","lastModifiedAt":"2025-03-01T00:00:00Z"}],"total":3}`))
+		case "/documents/search":
+			w.Write([]byte(`{"items":[{"documentId":"d1","markdown":"roadmap"},{"documentId":"d2","markdown":"roadmap"},{"documentId":"d2","markdown":"roadmap q2"}],"total":3}`))
+		case "/connection":
+			w.Write([]byte(`{"space":{"id":"s1"},"urlTemplates":{"app":"craftdocs://open?spaceId={spaceId}&blockId={blockId}"}}`))
+		case "/blocks":
+			id := r.URL.Query().Get("id")
+			var blocks []string
+			for s := 1; s <= 2; s++ {
+				blocks = append(blocks, fmt.Sprintf(`{"id":"%s-h%d","type":"text","textStyle":"h2","markdown":"## Section %d"}`, id, s, s))
+				for i := 0; i < bodyLines; i++ {
+					blocks = append(blocks, fmt.Sprintf(`{"id":"%s-%d-%d","type":"text","markdown":"Paragraph %d of section %d in %s, with some filler words."}`, id, s, i, i, s, id))
+				}
+			}
+			fmt.Fprintf(w, `{"id":"%s","type":"page","markdown":"Doc %s","content":[%s]}`, id, id, strings.Join(blocks, ","))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+}
+
+func TestContextRanksBySearchHitsThenRecency(t *testing.T) {
+	server := contextServer(1)
+	defer server.Close()
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "context", "--query", "roadmap"}, "")
+	if err != nil {
+		t.Fatalf("context error = %v", err)
+	}
+	first := strings.Index(out, "## [1] Roadmap")
+	second := strings.Index(out, "## [2] Old plan")
+	if first < 0 || second < first {
+		t.Errorf("sources not ranked by hits:\n%s", out)
+	}
+	if strings.Contains(out, "Groceries") {
+		t.Errorf("document without hits was included:\n%s", out)
+	}
+	if !strings.Contains(out, "craftdocs://open?spaceId=s1&blockId=d2") {
+		t.Errorf("missing source link:\n%s", out)
+	}
+
+	out, err = runInProcess([]string{"--api-url", server.URL, "context", "--limit", "1"}, "")
+	if err != nil {
+		t.Fatalf("context error = %v", err)
+	}
+	if !strings.Contains(out, "## [1] Groceries") || strings.Contains(out, "## [2]") {
+		t.Errorf("without --query the most recent document should be used:\n%s", out)
+	}
+}
+
+func TestContextStaysWithinBudgetAndKeepsHeadings(t *testing.T) {
+	server := contextServer(60)
+	defer server.Close()
+
+	for _, budget := range []int{300, 1200, 4000} {
+		out, err := runInProcess([]string{"--api-url", server.URL, "context", "--budget", fmt.Sprint(budget)}, "")
+		if err != nil {
+			t.Fatalf("context error = %v", err)
+		}
+		if got := estimateTokens(out); got > budget {
+			t.Errorf("budget %d: pack is ~%d tokens", budget, got)
+		}
+		if !strings.Contains(out, "## Section 1\n") || !strings.Contains(out, "## Section 2\n") {
+			t.Errorf("budget %d: headings were dropped:\n%s", budget, out)
+		}
+		if !strings.Contains(out, "…") {
+			t.Errorf("budget %d: expected trimmed passages to be marked", budget)
+		}
+	}
+}
+
+func TestContextJSONMatchesSchema(t *testing.T) {
+	server := contextServer(3)
+	defer server.Close()
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "--format", "json", "context", "--query", "roadmap"}, "")
+	if err != nil {
+		t.Fatalf("context error = %v", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(out), &value); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	schema, err := buildOutputSchema(contextCmd, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jsonschema.Validate(schema, schema.Defs, value); err != nil {
+		t.Errorf("output does not match schema: %v", err)
+	}
+
+	var pack contextPack
+	json.Unmarshal([]byte(out), &pack)
+	if len(pack.Sources) != 2 || pack.Sources[0].ID != "d2" || pack.Sources[0].Hits != 2 || pack.Tokens == 0 {
+		t.Errorf("unexpected pack: %+v", pack)
+	}
+}
+
+func TestFitContextLines(t *testing.T) {
+	lines := []contextLine{
+		{text: "# A", heading: true},
+		{text: strings.Repeat("a", 400)},
+		{text: "short a"},
+		{text: "# B", heading: true},
+		{text: "short b"},
+		{text: strings.Repeat("b", 400)},
+	}
+	got, truncated := fitContextLines(lines, 30)
+	want := "# A\n…\n# B\nshort b\n…"
+	if got != want || !truncated {
+		t.Errorf("fitContextLines() = %q, %v; want %q", got, truncated, want)
+	}
+
+	got, truncated = fitContextLines(lines[:1], 30)
+	if got != "# A" || truncated {
+		t.Errorf("fitContextLines() = %q, %v", got, truncated)
+	}
+}
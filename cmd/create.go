@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/ashrafali/craft-cli/internal/api"
 	"github.com/ashrafali/craft-cli/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ var (
 	createFile     string
 	createMarkdown string
 	createParentID string
+	createFolder   string
 	batchCreate    bool
 	createStdin    bool
 )
@@ -36,18 +38,22 @@ Examples:
   echo "# Hello" | craft create --title "Note"      # Pipe content
   cat doc.md | craft create --title "Imported"
 
+  # Create in a folder (a profile can set a default: craft config set work folder ID)
+  craft create --title "Note" --folder FOLDER_ID
+
   # Batch create
   echo '[{"title":"Doc1"},{"title":"Doc2"}]' | craft create --batch
 
   # Chain-friendly (returns just the ID)
   ID=$(craft create -q --title "Note")
-  craft update $ID --file content.md`,
+  craft update $ID --file content.md
+
+  # Safe to re-run: repeats return the original document, and an interrupted
+  # content upload resumes from the last inserted chunk
+  craft create --title "Report" --file report.md --idempotency-key report-2025-06`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Handle batch mode
 		if batchCreate {
-			if createStdin {
-				return fmt.Errorf("--stdin cannot be used with --batch")
-			}
 			return runBatchCreate()
 		}
 
@@ -62,9 +68,6 @@ Examples:
 		}
 
 		if createStdin {
-			if createFile != "" {
-				return fmt.Errorf("--stdin cannot be used with --file")
-			}
 			createFile = "-"
 		}
 		// Read content from various sources
@@ -77,12 +80,20 @@ Examples:
 		if req.Title == "" {
 			return fmt.Errorf("title is required (use --title)")
 		}
+		// A profile's default folder does not apply to nested pages.
+		folder := createFolder
+		if req.ParentID != "" && !cmd.Flags().Changed("folder") {
+			folder = ""
+		}
 
 		if isDryRun() {
 			target := map[string]interface{}{"title": req.Title}
 			if req.ParentID != "" {
 				target["parent"] = req.ParentID
 			}
+			if folder != "" {
+				target["folder"] = folder
+			}
 			if req.Markdown != "" {
 				preview := req.Markdown
 				if len(preview) > 100 {
@@ -90,21 +101,27 @@ Examples:
 				}
 				target["content_preview"] = preview
 			}
-			return dryRunOutput("create", target)
+			return dryRunOutput("documents.create", nil, target)
 		}
 
-		doc, err := client.CreateDocument(req)
+		if idempotencyKey != "" {
+			return runIdempotentCreate(client, req, folder)
+		}
+
+		doc, err := client.InFolder(folder).CreateDocument(req)
 		if err != nil {
 			return err
 		}
-
-		if isQuiet() {
-			fmt.Println(doc.ID)
-			return nil
+		if err := moveIntoFolder(client, doc.ID, folder); err != nil {
+			return err
 		}
 
-		format := getOutputFormat()
-		return outputCreated(doc, format)
+		res := newMutation("documents.create", doc.ID)
+		res.CreatedIDs = []string{doc.ID}
+		res.Result = doc
+		return outputMutation(res, func() error {
+			return outputDocument(doc, getOutputFormat())
+		})
 	},
 }
 
@@ -115,7 +132,93 @@ func init() {
 	createCmd.Flags().StringVar(&createMarkdown, "markdown", "", "Markdown content")
 	createCmd.Flags().BoolVar(&createStdin, "stdin", false, "Read content from stdin")
 	createCmd.Flags().StringVar(&createParentID, "parent", "", "Parent document ID")
+	createCmd.Flags().StringVar(&createFolder, "folder", "", "Folder ID to place the new document in")
 	createCmd.Flags().BoolVar(&batchCreate, "batch", false, "Batch create from JSON array on stdin")
+	createCmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "Key that makes re-runs return the original document instead of creating another")
+}
+
+// runIdempotentCreate creates a document under idempotencyKey. A re-run after
+// success replays the original result; a re-run after a failure reuses the
+// created document and resumes appending its content.
+func runIdempotentCreate(client *api.Client, req *models.CreateDocumentRequest, folder string) error {
+	ledger, err := loadIdempotencyLedger(idempotencyPath(), client.BaseURL())
+	if err != nil {
+		return err
+	}
+	fingerprint := requestFingerprint(req)
+	if folder != "" {
+		fingerprint = requestFingerprint([]interface{}{req, folder})
+	}
+	entry, err := ledger.begin(idempotencyKey, "documents.create", fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if entry.Complete {
+		res, err := entry.replayResult()
+		if err != nil {
+			return err
+		}
+		var doc models.Document
+		if err := decodeResult(res, &doc); err != nil {
+			return err
+		}
+		return outputMutation(res, func() error {
+			return outputDocument(&doc, getOutputFormat())
+		})
+	}
+
+	resumed := entry.started()
+	if entry.DocumentID == "" {
+		// Create the document empty and record it before adding content, so
+		// a failure while appending never leads to a second document.
+		empty := *req
+		empty.Markdown = ""
+		doc, err := client.InFolder(folder).CreateDocument(&empty)
+		if err != nil {
+			return err
+		}
+		if err := ledger.step(entry, func(e *idempotencyEntry) { e.DocumentID = doc.ID }); err != nil {
+			return err
+		}
+	}
+
+	_, err = client.ResumeAppendMarkdown(entry.DocumentID, req.Markdown, 0, entry.ChunksDone, func(done int) error {
+		return ledger.step(entry, func(e *idempotencyEntry) { e.ChunksDone = done })
+	})
+	if err != nil {
+		return resumeHint(err, idempotencyKey)
+	}
+	// Moving again on a resumed run is harmless.
+	if err := moveIntoFolder(client, entry.DocumentID, folder); err != nil {
+		return resumeHint(err, idempotencyKey)
+	}
+
+	doc := &models.Document{ID: entry.DocumentID, Title: req.Title}
+	res := newMutation("documents.create", doc.ID)
+	res.CreatedIDs = []string{doc.ID}
+	res.Result = doc
+	if resumed {
+		res.Details = map[string]interface{}{"resumed": true}
+	}
+	if err := ledger.complete(idempotencyKey, res); err != nil {
+		return err
+	}
+	return outputMutation(res, func() error {
+		return outputDocument(doc, getOutputFormat())
+	})
+}
+
+// moveIntoFolder moves a newly created document into folder, if one is given.
+// The API cannot create documents in a folder directly.
+func moveIntoFolder(client *api.Client, docID, folder string) error {
+	if folder == "" {
+		return nil
+	}
+	if err := client.MoveDocument(docID, folder, ""); err != nil {
+		return fmt.Errorf("document %s was created but not moved to folder %s: %w", docID, folder, err)
+	}
+	return nil
 }
 
 // readContent reads content from file, argument, or stdin
@@ -177,36 +280,35 @@ func runBatchCreate() error {
 	}
 
 	if isDryRun() {
-		fmt.Printf("Would create %d documents:\n", len(requests))
+		titles := make([]string, len(requests))
 		for i, req := range requests {
-			fmt.Printf("  %d. %s\n", i+1, req.Title)
+			titles[i] = req.Title
 		}
-		return nil
+		return dryRunOutput("documents.create", nil, map[string]interface{}{
+			"count": len(requests), "titles": titles,
+		})
 	}
 
-	var results []models.Document
+	res := newMutation("documents.create")
+	results := []models.Document{}
 	for _, req := range requests {
 		doc, err := client.CreateDocument(&req)
 		if err != nil {
-			printStatus("Error creating '%s': %v\n", req.Title, err)
+			res.OK = false
+			res.Warnings = append(res.Warnings, fmt.Sprintf("error creating '%s': %v", req.Title, err))
 			continue
 		}
 		results = append(results, *doc)
-		printStatus("Created: %s (%s)\n", doc.Title, doc.ID)
-	}
-
-	// Output results
-	if isQuiet() {
-		for _, doc := range results {
-			fmt.Println(doc.ID)
-		}
-		return nil
+		res.Targets = append(res.Targets, doc.ID)
+		res.CreatedIDs = append(res.CreatedIDs, doc.ID)
 	}
+	res.Result = &models.DocumentList{Items: results, Total: len(results)}
 
-	format := getOutputFormat()
-	if format == FormatJSON {
-		payload := &models.DocumentList{Items: results, Total: len(results)}
-		return outputDocumentsPayload(payload, format)
+	err = outputMutation(res, func() error {
+		return outputDocuments(results, getOutputFormat())
+	})
+	if err == nil && len(res.Warnings) > 0 {
+		err = fmt.Errorf("%d of %d document(s) could not be created", len(res.Warnings), len(requests))
 	}
-	return outputDocuments(results, format)
+	return err
 }
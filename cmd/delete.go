@@ -19,7 +19,8 @@ Examples:
   craft delete abc123
   craft delete abc123 --dry-run    # Preview without deleting
   craft delete abc123 -q           # Silent delete`,
-	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		docID := args[0]
 		if err := validateResourceID(docID, "document-id"); err != nil {
@@ -35,8 +36,8 @@ Examples:
 			if err != nil {
 				return fmt.Errorf("document not found: %s", docID)
 			}
-			return dryRunOutput("delete", map[string]interface{}{
-				"id": doc.ID, "title": doc.Title, "reversible": true,
+			return dryRunOutput("documents.delete", []string{doc.ID}, map[string]interface{}{
+				"title": doc.Title, "reversible": true,
 			})
 		}
 
@@ -49,8 +50,12 @@ Examples:
 			return err
 		}
 
-		outputDeleted(docID)
-		return nil
+		res := newMutation("documents.delete", docID)
+		res.DeletedIDs = []string{docID}
+		return outputMutation(res, func() error {
+			fmt.Printf("Document %s moved to trash\n", docID)
+			return nil
+		})
 	},
 }
 
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check results.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// maxClockSkew is the clock difference from the API beyond which doctor
+// warns: relative daily-note dates and schedules may land on the wrong day.
+const maxClockSkew = 2 * time.Minute
+
+var doctorSandbox string
+
+// doctorCheck is one line of the doctor report.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// doctorReport is the JSON output of craft doctor.
+type doctorReport struct {
+	OK      bool          `json:"ok"`
+	Profile string        `json:"profile,omitempty"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+func (r *doctorReport) add(name, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and connection of a profile",
+	Long: `Run health checks on the profile in effect and print a pass/fail report:
+
+  config      the config file parses and is private
+  url         the API URL is a well-formed Craft API link
+  connection  the API answers; reports the space time zone and clock skew
+  read        documents can be listed
+  write       a scratch block can be added to the --sandbox document
+  delete      the scratch block can be deleted again
+  update      the latest-release check works
+
+The write and delete checks change the sandbox document, so they only run
+with --sandbox (or a profile's sandbox default) and after confirmation;
+--yes skips the prompt. Exits with an error if any check fails.
+
+Examples:
+  craft doctor
+  craft doctor --profile work --sandbox DOC_ID
+  craft config set work sandbox DOC_ID && craft doctor --yes --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report := runDoctor()
+		if isJSONFormat(getOutputFormat()) {
+			if err := outputJSON(report); err != nil {
+				return err
+			}
+		} else {
+			printDoctorReport(report)
+		}
+		failed := 0
+		for _, c := range report.Checks {
+			if c.Status == checkFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("doctor found %d failing check(s)", failed)
+		}
+		return nil
+	},
+}
+
+// runDoctor runs every check, skipping those that depend on a failed one.
+func runDoctor() *doctorReport {
+	report := &doctorReport{Checks: []doctorCheck{}}
+	defer func() {
+		report.OK = true
+		for _, c := range report.Checks {
+			report.OK = report.OK && c.Status != checkFail
+		}
+	}()
+
+	if checkDoctorConfig(report) {
+		client := checkDoctorURL(report)
+		if client != nil && checkDoctorConnection(report, client) {
+			checkDoctorRead(report, client)
+			checkDoctorWrite(report, client)
+		} else {
+			for _, name := range []string{"read", "write", "delete"} {
+				report.add(name, checkSkip, "needs a working connection")
+			}
+		}
+	}
+	checkDoctorUpdate(report)
+	return report
+}
+
+func checkDoctorConfig(r *doctorReport) bool {
+	path := cfgManager.Path()
+	cfg, err := cfgManager.Load()
+	if err != nil {
+		r.add("config", checkFail, "%v", err)
+		return false
+	}
+	if cfg.Secrets != nil {
+		if err := cfg.Secrets.Validate(); err != nil {
+			r.add("config", checkFail, "%s: %v", path, err)
+			return false
+		}
+	}
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		r.add("config", checkWarn, "%s does not exist; run 'craft setup'", path)
+	case err != nil:
+		r.add("config", checkFail, "%v", err)
+		return false
+	case info.Mode().Perm()&0077 != 0:
+		r.add("config", checkWarn, "%s is readable by other users (mode %o); run chmod 600", path, info.Mode().Perm())
+	default:
+		r.add("config", checkPass, "%s", path)
+	}
+	return true
+}
+
+// checkDoctorURL validates the API URL in effect and returns a client for it.
+func checkDoctorURL(r *doctorReport) *api.Client {
+	r.Profile, _ = cfgManager.ActiveProfile()
+	raw := apiURL
+	if raw == "" {
+		var err error
+		if raw, err = cfgManager.GetActiveURL(); err != nil {
+			r.add("url", checkFail, "%v", err)
+			return nil
+		}
+	}
+	if problem := craftURLProblem(raw); problem != "" {
+		r.add("url", checkFail, "%s", problem)
+		return nil
+	}
+	client, err := getAPIClient()
+	if err != nil {
+		r.add("url", checkFail, "%v", err)
+		return nil
+	}
+	if !strings.HasSuffix(hostOf(raw), "craft.do") {
+		r.add("url", checkWarn, "%s is not a craft.do host", hostOf(raw))
+	} else {
+		r.add("url", checkPass, "%s", hostOf(raw))
+	}
+	return client
+}
+
+// craftURLProblem describes what is wrong with a Craft API URL, or "".
+func craftURLProblem(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("%q is not a URL", raw)
+	}
+	if u.Scheme != "https" && !isLoopback(u.Hostname()) {
+		return fmt.Sprintf("API URL must use https, not %s", u.Scheme)
+	}
+	if !strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v1") {
+		return fmt.Sprintf("API URL path %q should end in /api/v1", u.Path)
+	}
+	return ""
+}
+
+func hostOf(raw string) string {
+	u, _ := url.Parse(raw)
+	return u.Hostname()
+}
+
+func isLoopback(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func checkDoctorConnection(r *doctorReport, client *api.Client) bool {
+	start := time.Now()
+	info, err := client.GetConnection()
+	if err != nil {
+		r.add("connection", checkFail, "%v", err)
+		return false
+	}
+	latency := time.Since(start)
+
+	detail := fmt.Sprintf("space %s, time zone %s, %dms", info.Space.ID, info.Space.Timezone, latency.Milliseconds())
+	status := checkPass
+	if server, err := time.Parse(time.RFC3339, info.UTC.Time); err == nil {
+		skew := time.Until(server) + latency/2
+		detail += fmt.Sprintf(", clock skew %s", skew.Round(time.Second))
+		if skew > maxClockSkew || skew < -maxClockSkew {
+			status = checkWarn
+			detail += " (fix the system clock)"
+		}
+	}
+	if tz := activeDefault("timezone"); tz != "" && info.Space.Timezone != "" && tz != info.Space.Timezone {
+		status = checkWarn
+		detail += fmt.Sprintf("; profile timezone default %s differs from the space", tz)
+	}
+	r.add("connection", status, "%s", detail)
+	return true
+}
+
+func checkDoctorRead(r *doctorReport, client *api.Client) {
+	docs, err := client.GetDocuments()
+	if err != nil {
+		r.add("read", doctorDenied(err), "%v", err)
+		return
+	}
+	r.add("read", checkPass, "%d document(s) visible", len(docs.Items))
+}
+
+// checkDoctorWrite adds a scratch block to the sandbox document and deletes it.
+func checkDoctorWrite(r *doctorReport, client *api.Client) {
+	skip := func(format string, args ...interface{}) {
+		r.add("write", checkSkip, format, args...)
+		r.add("delete", checkSkip, format, args...)
+	}
+	switch {
+	case doctorSandbox == "":
+		skip("pass --sandbox DOC_ID to test with a scratch block")
+		return
+	case isDryRun():
+		skip("dry run")
+		return
+	case !yesFlag && !confirmDoctorWrite():
+		skip("not confirmed (use --yes)")
+		return
+	}
+
+	block, err := client.AddBlock(doctorSandbox, "craft doctor scratch block "+time.Now().UTC().Format(time.RFC3339), "end")
+	if err != nil {
+		r.add("write", doctorDenied(err), "%v", err)
+		r.add("delete", checkSkip, "needs a scratch block")
+		return
+	}
+	r.add("write", checkPass, "added block %s to %s", block.ID, doctorSandbox)
+
+	if err := client.DeleteBlock(block.ID); err != nil {
+		r.add("delete", doctorDenied(err), "%v; remove block %s by hand", err, block.ID)
+		return
+	}
+	r.add("delete", checkPass, "deleted block %s", block.ID)
+}
+
+// doctorDenied is the status of a refused change: a warning when the
+// profile's policy or link permissions forbid it, else a failure.
+func doctorDenied(err error) string {
+	switch categorizeError(err) {
+	case "POLICY_DENIED", "PERMISSION_DENIED":
+		return checkWarn
+	}
+	return checkFail
+}
+
+// confirmDoctorWrite asks before changing the sandbox document. Without a
+// terminal there is no one to ask, so the answer is no.
+func confirmDoctorWrite() bool {
+	if !isTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Add and delete a scratch block in document %s? (y/N): ", doctorSandbox)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func checkDoctorUpdate(r *doctorReport) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	latest, newer, err := checkLatestRelease(ctx)
+	switch {
+	case err != nil:
+		r.add("update", checkFail, "release check failed: %v", err)
+	case newer:
+		r.add("update", checkWarn, "%s is available (current %s); run 'craft upgrade'", latest, version)
+	default:
+		r.add("update", checkPass, "up to date (%s)", version)
+	}
+}
+
+func printDoctorReport(r *doctorReport) {
+	icons := map[string]string{checkPass: "✓", checkWarn: "!", checkFail: "✗", checkSkip: "-"}
+	if r.Profile != "" {
+		fmt.Printf("Profile: %s\n\n", r.Profile)
+	}
+	for _, c := range r.Checks {
+		fmt.Printf("%s %-11s %s\n", icons[c.Status], c.Name, c.Detail)
+	}
+	fmt.Println()
+	if r.OK {
+		fmt.Println("All checks passed.")
+	} else {
+		fmt.Println("Some checks failed.")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&doctorSandbox, "sandbox", "", "Document ID in which to add and delete a scratch block, to test write and delete access")
+}
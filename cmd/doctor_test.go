@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoctorReport(t *testing.T) {
+	useTempConfig(t)
+	orig := checkLatestRelease
+	t.Cleanup(func() { checkLatestRelease = orig })
+	checkLatestRelease = func(context.Context) (string, bool, error) { return "9.9.9", true, nil }
+
+	var changes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/connection", func(w http.ResponseWriter, r *http.Request) {
+		skewed := time.Now().UTC().Add(-10 * time.Minute).Format(time.RFC3339)
+		w.Write([]byte(`{"space":{"id":"s1","timezone":"Europe/Berlin"},"utc":{"time":"` + skewed + `"}}`))
+	})
+	mux.HandleFunc("/documents", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[{"id":"d1","title":"Sandbox"}]}`))
+	})
+	mux.HandleFunc("/blocks", func(w http.ResponseWriter, r *http.Request) {
+		changes = append(changes, r.Method)
+		w.Write([]byte(`{"items":[{"id":"scratch","type":"text"}]}`))
+	})
+	server := httptest.NewServer(http.StripPrefix("/api/v1", mux))
+	defer server.Close()
+	if err := cfgManager.AddProfile("work", server.URL+"/api/v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	doctor := func(args ...string) map[string]doctorCheck {
+		t.Helper()
+		out, err := runInProcess(append([]string{"doctor", "--format", "json"}, args...), "")
+		if err != nil {
+			t.Fatalf("doctor %v: %v\n%s", args, err, out)
+		}
+		var report doctorReport
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		checks := map[string]doctorCheck{}
+		for _, c := range report.Checks {
+			checks[c.Name] = c
+		}
+		return checks
+	}
+
+	checks := doctor()
+	if checks["config"].Status != checkPass || checks["read"].Status != checkPass {
+		t.Errorf("checks = %+v", checks)
+	}
+	if c := checks["connection"]; c.Status != checkWarn || !strings.Contains(c.Detail, "Europe/Berlin") || !strings.Contains(c.Detail, "clock skew") {
+		t.Errorf("connection = %+v, want a clock skew warning", c)
+	}
+	if checks["write"].Status != checkSkip || checks["update"].Status != checkWarn {
+		t.Errorf("write = %+v, update = %+v", checks["write"], checks["update"])
+	}
+	if checks["url"].Status != checkWarn {
+		t.Errorf("url = %+v, want a warning for a non-Craft host", checks["url"])
+	}
+
+	// Without --yes and a terminal there is no confirmation.
+	if checks = doctor("--sandbox", "d1"); checks["write"].Status != checkSkip || len(changes) != 0 {
+		t.Errorf("unconfirmed write = %+v, changes = %v", checks["write"], changes)
+	}
+	cfgManager.SetProfileDefault("work", "sandbox", "d1")
+	checks = doctor("--yes")
+	if checks["write"].Status != checkPass || checks["delete"].Status != checkPass {
+		t.Errorf("write = %+v, delete = %+v", checks["write"], checks["delete"])
+	}
+	if strings.Join(changes, " ") != "POST DELETE" {
+		t.Errorf("changes = %v, want the scratch block added and deleted", changes)
+	}
+
+	checkLatestRelease = func(context.Context) (string, bool, error) { return "", false, errors.New("offline") }
+	if _, err := runInProcess([]string{"doctor"}, ""); err == nil || !strings.Contains(err.Error(), "1 failing check") {
+		t.Errorf("error = %v, want the failed release check reported", err)
+	}
+}
+
+func TestCraftURLProblem(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://connect.craft.do/links/abc/api/v1":  "",
+		"https://connect.craft.do/links/abc/api/v1/": "",
+		"http://connect.craft.do/links/abc/api/v1":   "must use https",
+		"https://connect.craft.do/links/abc":         "should end in /api/v1",
+		"connect.craft.do/links/abc/api/v1":          "is not a URL",
+	} {
+		got := craftURLProblem(raw)
+		if (want == "") != (got == "") || !strings.Contains(got, want) {
+			t.Errorf("craftURLProblem(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// mutationResult is the JSON envelope printed by every mutating command.
+// Dry runs use the same shape with dry_run set, so automation can consume
+// all mutations uniformly. List fields are always arrays, never null.
+type mutationResult struct {
+	OK         bool                   `json:"ok"`
+	Action     string                 `json:"action"`
+	Targets    []string               `json:"targets"`
+	CreatedIDs []string               `json:"created_ids"`
+	DeletedIDs []string               `json:"deleted_ids"`
+	Warnings   []string               `json:"warnings"`
+	DryRun     bool                   `json:"dry_run"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Result     interface{}            `json:"result,omitempty"`
+}
+
+// newMutation creates a successful result for action on the given targets.
+func newMutation(action string, targets ...string) *mutationResult {
+	res := &mutationResult{
+		OK:         true,
+		Action:     action,
+		Targets:    []string{},
+		CreatedIDs: []string{},
+		DeletedIDs: []string{},
+		Warnings:   []string{},
+	}
+	for _, t := range targets {
+		if t != "" {
+			res.Targets = append(res.Targets, t)
+		}
+	}
+	return res
+}
+
+// wantsEnvelope reports whether mutation results should be printed as JSON.
+func wantsEnvelope() bool {
+	return isJSONFormat(getOutputFormat()) || jsonErrors
+}
+
+// outputMutation prints a mutation result. JSON formats get the envelope,
+// --quiet prints created IDs one per line, and other formats call human
+// (when non-nil) to print the command's usual text.
+func outputMutation(res *mutationResult, human func() error) error {
+	if isQuiet() && !res.DryRun {
+		for _, id := range res.CreatedIDs {
+			fmt.Println(id)
+		}
+		return nil
+	}
+
+	if wantsEnvelope() {
+		return outputJSON(res)
+	}
+
+	for _, w := range res.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if human != nil {
+		return human()
+	}
+	return nil
+}
+
+// firstStringField returns the first non-empty string value among keys in m.
+func firstStringField(m map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := m[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// actionVerb describes an action key for people: what would be done and the
+// preposition (if any) that introduces its targets.
+type actionVerb struct {
+	phrase string
+	prep   string
+}
+
+// actionVerbs maps the action keys of the mutation envelope to the words
+// used in human dry-run output.
+var actionVerbs = map[string]actionVerb{
+	"documents.create": {"create a document", ""},
+	"documents.update": {"update document", ""},
+	"documents.delete": {"delete document", ""},
+	"documents.clear":  {"clear document", ""},
+	"documents.move":   {"move document", ""},
+
+	"blocks.add":    {"add blocks", "at"},
+	"blocks.update": {"update block", ""},
+	"blocks.delete": {"delete block", ""},
+	"blocks.move":   {"move block", ""},
+	"comments.add":  {"comment on block", ""},
+
+	"collections.add_item":    {"add an item to collection", ""},
+	"collections.update_item": {"update collection item", ""},
+	"collections.delete_item": {"delete collection item", ""},
+
+	"folders.create": {"create a folder", "in"},
+	"folders.move":   {"move folder", ""},
+	"folders.delete": {"delete folder", ""},
+
+	"tasks.add":    {"add a task", "to"},
+	"tasks.update": {"update task", ""},
+	"tasks.delete": {"delete task", ""},
+
+	"whiteboards.create":          {"create a whiteboard", "in"},
+	"whiteboards.add_elements":    {"add elements to whiteboard", ""},
+	"whiteboards.update_elements": {"update elements of whiteboard", ""},
+	"whiteboards.delete_elements": {"delete elements from whiteboard", ""},
+
+	"files.upload": {"upload a file", "at"},
+	"plan.apply":   {"apply plan", ""},
+	"batch":        {"run a batch", ""},
+}
+
+// describeAction returns the human words for action. Unknown keys such as
+// "things.do_it" read as "do it things".
+func describeAction(action string) actionVerb {
+	if v, ok := actionVerbs[action]; ok {
+		return v
+	}
+	noun, verb, ok := strings.Cut(action, ".")
+	if !ok {
+		return actionVerb{phrase: strings.ReplaceAll(action, "_", " ")}
+	}
+	return actionVerb{phrase: strings.ReplaceAll(verb, "_", " ") + " " + noun}
+}
+
+// dryRunOutput prints what a mutation would do, using the mutation envelope
+// for JSON output and a short description otherwise. Action keys appear only
+// in the envelope; the description uses words from actionVerbs.
+func dryRunOutput(action string, targets []string, details map[string]interface{}) error {
+	res := newMutation(action, targets...)
+	res.DryRun = true
+	res.Details = details
+
+	if wantsEnvelope() {
+		return outputJSON(res)
+	}
+
+	verb := describeAction(action)
+	fmt.Printf("[dry-run] Would %s", verb.phrase)
+	if len(res.Targets) > 0 {
+		if verb.prep != "" {
+			fmt.Printf(" %s", verb.prep)
+		}
+		fmt.Printf(" %s", strings.Join(res.Targets, ", "))
+	}
+	if title, ok := details["title"]; ok && title != "" {
+		fmt.Printf(" (%v)", title)
+	}
+	fmt.Println()
+
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		if k != "title" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s: %v\n", k, details[k])
+	}
+	return nil
+}
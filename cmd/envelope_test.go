@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOutputMutationEnvelope(t *testing.T) {
+	oldFormat, oldQuiet := outputFormat, quietMode
+	defer func() { outputFormat, quietMode = oldFormat, oldQuiet }()
+	outputFormat, quietMode = "json", false
+
+	res := newMutation("blocks.delete", "b1")
+	res.DeletedIDs = []string{"b1"}
+	out := captureStdout(t, func() {
+		if err := outputMutation(res, nil); err != nil {
+			t.Errorf("outputMutation() error = %v", err)
+		}
+	})
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	for _, key := range []string{"ok", "action", "targets", "created_ids", "deleted_ids", "warnings", "dry_run"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("envelope missing %q: %s", key, out)
+		}
+	}
+	if created, ok := decoded["created_ids"].([]interface{}); !ok || len(created) != 0 {
+		t.Errorf("created_ids should be an empty array, got %v", decoded["created_ids"])
+	}
+	if decoded["ok"] != true || decoded["dry_run"] != false {
+		t.Errorf("unexpected ok/dry_run: %s", out)
+	}
+}
+
+func TestDryRunOutputUsesEnvelope(t *testing.T) {
+	oldFormat := outputFormat
+	defer func() { outputFormat = oldFormat }()
+	outputFormat = "json"
+
+	out := captureStdout(t, func() {
+		_ = dryRunOutput("documents.delete", []string{"doc1"}, map[string]interface{}{"reversible": true})
+	})
+
+	var res mutationResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if !res.DryRun || res.Action != "documents.delete" || len(res.Targets) != 1 || res.Targets[0] != "doc1" {
+		t.Errorf("unexpected dry-run envelope: %+v", res)
+	}
+	if res.Details["reversible"] != true {
+		t.Errorf("details not preserved: %+v", res.Details)
+	}
+}
+
+func TestOutputMutationQuietPrintsCreatedIDs(t *testing.T) {
+	oldFormat, oldQuiet := outputFormat, quietMode
+	defer func() { outputFormat, quietMode = oldFormat, oldQuiet }()
+	outputFormat, quietMode = "json", true
+
+	res := newMutation("documents.create")
+	res.CreatedIDs = []string{"a", "b"}
+	out := captureStdout(t, func() { _ = outputMutation(res, nil) })
+	if strings.TrimSpace(out) != "a\nb" {
+		t.Errorf("quiet output = %q, want created IDs", out)
+	}
+}
+
+func TestBatchCreateFailsOnPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "bad") {
+			http.Error(w, `{"error":"invalid title"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"items":[{"id":"d1","title":"good"}]}`))
+	}))
+	defer server.Close()
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "create", "--batch"}, `[{"title":"good"},{"title":"bad"}]`)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 document(s)") {
+		t.Errorf("error = %v, want the failure reported", err)
+	}
+	var res mutationResult
+	if json.Unmarshal([]byte(out), &res) != nil || res.OK || len(res.CreatedIDs) != 1 {
+		t.Errorf("envelope = %s", out)
+	}
+}
+
+func TestDryRunOutputDescribesActionsInWords(t *testing.T) {
+	oldFormat := outputFormat
+	defer func() { outputFormat = oldFormat }()
+	outputFormat = "table"
+
+	for _, tt := range []struct {
+		action  string
+		targets []string
+		want    string
+	}{
+		{"documents.delete", []string{"doc1"}, "[dry-run] Would delete document doc1\n"},
+		{"folders.create", []string{"f1"}, "[dry-run] Would create a folder in f1\n"},
+		{"folders.create", nil, "[dry-run] Would create a folder\n"},
+		{"things.do_it", []string{"t1"}, "[dry-run] Would do it things t1\n"},
+	} {
+		out := captureStdout(t, func() { _ = dryRunOutput(tt.action, tt.targets, nil) })
+		if out != tt.want {
+			t.Errorf("dryRunOutput(%q) = %q, want %q", tt.action, out, tt.want)
+		}
+	}
+}
+
+func TestClearReportsDeletedIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"id":"d1","type":"page","markdown":"Doc","content":[
+			{"id":"b1","type":"text","markdown":"a","content":[{"id":"b2","type":"text","markdown":"b"}]},
+			{"id":"b3","type":"text","markdown":"c"}]}`))
+	}))
+	defer server.Close()
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "clear", "d1"}, "")
+	var res mutationResult
+	if err != nil || json.Unmarshal([]byte(out), &res) != nil {
+		t.Fatalf("clear = %q, %v", out, err)
+	}
+	if strings.Join(res.DeletedIDs, ",") != "b1,b2,b3" {
+		t.Errorf("deleted_ids = %v, want b1,b2,b3", res.DeletedIDs)
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// fanOutProfiles and allProfiles run a read command against several
+	// profiles at once.
+	fanOutProfiles []string
+	allProfiles    bool
+)
+
+// fanOutCommands are the read commands that accept --profiles and --all-profiles.
+var fanOutCommands = []string{"list", "search", "tasks list"}
+
+// isFanOut reports whether the command runs across several profiles.
+func isFanOut() bool {
+	return len(fanOutProfiles) > 0 || allProfiles
+}
+
+// checkFanOut rejects --profiles and --all-profiles where they cannot apply.
+// It runs after the config layers, so an API URL or key from the
+// environment or .craft.yaml is caught too.
+func checkFanOut(cmd *cobra.Command) error {
+	if !isFanOut() {
+		return nil
+	}
+	key := commandKey(cmd)
+	supported := false
+	for _, c := range fanOutCommands {
+		supported = supported || c == key
+	}
+	switch {
+	case !supported:
+		return fmt.Errorf("--profiles and --all-profiles only work with: %s", strings.Join(fanOutCommands, ", "))
+	case len(fanOutProfiles) > 0 && allProfiles:
+		return fmt.Errorf("use either --profiles or --all-profiles")
+	case cmd.Flags().Changed("profile"):
+		return fmt.Errorf("--profile cannot be combined with --profiles or --all-profiles")
+	case apiURL != "" || apiKey != "":
+		return fmt.Errorf("an API URL or key given by flag, environment or .craft.yaml cannot be combined with --profiles or --all-profiles")
+	}
+	return nil
+}
+
+// fanOutTargets returns the profiles to query, in the order given.
+func fanOutTargets() ([]string, error) {
+	profiles, err := cfgManager.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	var all []string
+	for _, p := range profiles {
+		known[p.Name] = true
+		all = append(all, p.Name)
+	}
+	if allProfiles {
+		if len(all) == 0 {
+			return nil, fmt.Errorf("no profiles configured. Run 'craft config add' first")
+		}
+		return all, nil
+	}
+
+	var targets []string
+	seen := map[string]bool{}
+	for _, name := range fanOutProfiles {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("profile '%s' not found", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+	return targets, nil
+}
+
+// fanOutClients builds the client of each profile as getAPIClient would,
+// with that profile's defaults, policy and audit log.
+func fanOutClients(profiles []string) ([]*api.Client, error) {
+	current, err := cfgManager.ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	defer cfgManager.SetProfile(current)
+
+	clients := make([]*api.Client, len(profiles))
+	for i, name := range profiles {
+		cfgManager.SetProfile(name)
+		client, err := getAPIClient()
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		clients[i] = client
+	}
+	return clients, nil
+}
+
+// profiled is an item returned by one profile of a fan-out.
+type profiled[T any] struct {
+	Profile string
+	Item    T
+}
+
+// MarshalJSON writes the item with a leading "profile" field.
+func (p profiled[T]) MarshalJSON() ([]byte, error) {
+	item, err := json.Marshal(p.Item)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := json.Marshal(p.Profile)
+	if !bytes.HasPrefix(item, []byte("{")) {
+		return nil, fmt.Errorf("cannot add a profile to %s", item)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"profile":`)
+	buf.Write(name)
+	if rest := bytes.TrimSpace(item[1:]); !bytes.Equal(rest, []byte("}")) {
+		buf.WriteByte(',')
+	}
+	buf.Write(item[1:])
+	return buf.Bytes(), nil
+}
+
+// fanOutError reports a profile that failed.
+type fanOutError struct {
+	Profile string `json:"profile"`
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+}
+
+// fanOutResult is the JSON output of a fan-out.
+type fanOutResult[T any] struct {
+	Items    []profiled[T] `json:"items"`
+	Total    int           `json:"total"`
+	Profiles []string      `json:"profiles"`
+	Errors   []fanOutError `json:"errors,omitempty"`
+}
+
+// fanOutView describes how a command's items are printed as table rows.
+type fanOutView[T any] struct {
+	Columns []string
+	Row     func(T) []string
+	Limit   int
+}
+
+// runFanOut runs fetch against every target profile concurrently and prints
+// the merged items, each tagged with its profile, in profile order. A
+// profile that fails is reported without holding back the others' items;
+// the command then exits non-zero after printing them.
+func runFanOut[T any](fetch func(*api.Client) ([]T, error), view fanOutView[T]) error {
+	profiles, err := fanOutTargets()
+	if err != nil {
+		return err
+	}
+	clients, err := fanOutClients(profiles)
+	if err != nil {
+		return err
+	}
+
+	results := make([][]T, len(profiles))
+	errs := make([]error, len(profiles))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	streamed := 0
+	var writeErr error
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(clients[i])
+			if !isStreaming() || errs[i] != nil {
+				return
+			}
+			// Stream each profile's items as soon as it answers.
+			mu.Lock()
+			defer mu.Unlock()
+			for _, item := range results[i] {
+				if writeErr != nil || view.Limit > 0 && streamed >= view.Limit {
+					return
+				}
+				streamed++
+				writeErr = writeNDJSON(profiled[T]{Profile: profiles[i], Item: item})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	res := fanOutResult[T]{Items: []profiled[T]{}, Profiles: profiles}
+	for i, name := range profiles {
+		if errs[i] != nil {
+			res.Errors = append(res.Errors, fanOutError{Profile: name, Code: categorizeError(errs[i]), Error: errs[i].Error()})
+			printStatus("Warning: profile %s: %v\n", name, errs[i])
+			continue
+		}
+		for _, item := range results[i] {
+			res.Items = append(res.Items, profiled[T]{Profile: name, Item: item})
+		}
+	}
+	if len(res.Errors) == len(profiles) {
+		return fmt.Errorf("profile %s: %w", profiles[0], errs[0])
+	}
+	if isStreaming() {
+		if writeErr != nil {
+			return fmt.Errorf("failed to write output: %w", writeErr)
+		}
+	} else {
+		if view.Limit > 0 && len(res.Items) > view.Limit {
+			res.Items = res.Items[:view.Limit]
+		}
+		res.Total = len(res.Items)
+		if err := outputFanOut(res, view); err != nil {
+			return err
+		}
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("%d of %d profile(s) failed", len(res.Errors), len(profiles))
+	}
+	return nil
+}
+
+func outputFanOut[T any](res fanOutResult[T], view fanOutView[T]) error {
+	if field := getOutputOnly(); field != "" {
+		return outputFanOutField(res.Items, field)
+	}
+
+	switch format := getOutputFormat(); format {
+	case FormatJSON:
+		return outputJSON(res)
+	case FormatCompact:
+		return outputJSON(res.Items)
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !hasNoHeaders() {
+			fmt.Fprintln(w, "PROFILE\t"+strings.Join(view.Columns, "\t"))
+			fmt.Fprintln(w, "-------\t"+strings.Join(underlines(view.Columns), "\t"))
+		}
+		for _, p := range res.Items {
+			fmt.Fprintln(w, p.Profile+"\t"+strings.Join(view.Row(p.Item), "\t"))
+		}
+		return w.Flush()
+	case "markdown":
+		fmt.Println("| Profile | " + strings.Join(view.Columns, " | ") + " |")
+		fmt.Println("|---" + strings.Repeat("|---", len(view.Columns)) + "|")
+		for _, p := range res.Items {
+			cells := view.Row(p.Item)
+			for i := range cells {
+				cells[i] = strings.ReplaceAll(cells[i], "|", "\\|")
+			}
+			fmt.Println("| " + p.Profile + " | " + strings.Join(cells, " | ") + " |")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// outputFanOutField prints one field of each item, looked up by its JSON name.
+func outputFanOutField[T any](items []profiled[T], field string) error {
+	for _, p := range items {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		found := false
+		for k, v := range fields {
+			if strings.EqualFold(k, field) {
+				fmt.Println(v)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown field: %s", field)
+		}
+	}
+	return nil
+}
+
+func underlines(columns []string) []string {
+	lines := make([]string, len(columns))
+	for i, c := range columns {
+		lines[i] = strings.Repeat("-", len(c))
+	}
+	return lines
+}
+
+// truncate shortens s to n characters for table cells, cutting on rune
+// boundaries.
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
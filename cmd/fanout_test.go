@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// spaceServer serves one document, search hit and task named after space.
+// A space named "down" answers every request with 404.
+func spaceServer(t *testing.T, space string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case space == "down":
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		case r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"` + space + `-doc","title":"Notes"}]}`))
+		case r.URL.Path == "/documents/search":
+			w.Write([]byte(`{"items":[{"documentId":"` + space + `-doc","markdown":"match"}]}`))
+		case r.URL.Path == "/tasks":
+			w.Write([]byte(`{"items":[{"id":"` + space + `-task","markdown":"Do it","state":"todo"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestFanOutMergesProfiles(t *testing.T) {
+	useTempConfig(t)
+	for _, space := range []string{"work", "personal", "down"} {
+		if err := cfgManager.AddProfile(space, spaceServer(t, space)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runInProcess([]string{"list", "--profiles", "work,personal"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Items []struct {
+			Profile string `json:"profile"`
+			ID      string `json:"id"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if res.Total != 2 || res.Items[0].Profile != "work" || res.Items[0].ID != "work-doc" || res.Items[1].Profile != "personal" {
+		t.Errorf("merged list = %+v", res)
+	}
+
+	out, err = runInProcess([]string{"search", "match", "--profiles", "work,personal", "--format", "compact"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"profile": "personal"`) || !strings.Contains(out, `"documentId": "work-doc"`) {
+		t.Errorf("search across profiles:\n%s", out)
+	}
+
+	// A failing profile does not hold back the others, but fails the command.
+	out, err = runInProcess([]string{"tasks", "list", "--all-profiles", "--format", "json"}, "")
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 profile(s) failed") {
+		t.Errorf("error = %v, want the failed profile reported", err)
+	}
+	if !strings.Contains(out, `"profile": "down"`) || !strings.Contains(out, `"code": "NOT_FOUND"`) || !strings.Contains(out, "work-task") {
+		t.Errorf("tasks list with a failing profile:\n%s", out)
+	}
+
+	out, err = runInProcess([]string{"list", "--profiles", "personal,work", "--format", "table", "--id-only"}, "")
+	if err != nil || out != "personal-doc\nwork-doc\n" {
+		t.Errorf("--id-only = %q, %v", out, err)
+	}
+
+	if _, err := runInProcess([]string{"list", "--profiles", "down"}, ""); err == nil {
+		t.Error("expected an error when every profile fails")
+	}
+}
+
+func TestFanOutRejectsUnsupportedUse(t *testing.T) {
+	useTempConfig(t)
+	if err := cfgManager.AddProfile("work", "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"get", "d1", "--all-profiles"},
+		{"delete", "d1", "--profiles", "work"},
+		{"list", "--profiles", "work", "--profile", "work"},
+		{"list", "--profiles", "work", "--api-url", "https://other.example.com"},
+		{"list", "--profiles", "nope"},
+		{"search", "--document", "d1", "x", "--all-profiles"},
+	} {
+		if _, err := runInProcess(args, ""); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	if got := truncate("héllo wörld", 8); got != "héllo..." {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("日本語のタイトル", 6); got != "日本語..." || !utf8.ValidString(got) {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
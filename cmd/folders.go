@@ -33,6 +33,10 @@ var foldersListCmd = &cobra.Command{
 			return err
 		}
 
+		if isStreaming() {
+			return finishStream(client.StreamFolders(ndjsonSink[models.Folder](0)))
+		}
+
 		folders, err := client.GetFolders()
 		if err != nil {
 			return err
@@ -62,18 +66,23 @@ var foldersCreateCmd = &cobra.Command{
 		}
 
 		name := args[0]
+		if isDryRun() {
+			return dryRunOutput("folders.create", []string{folderParentID}, map[string]interface{}{
+				"name": name,
+			})
+		}
+
 		folder, err := client.CreateFolder(name, folderParentID)
 		if err != nil {
 			return err
 		}
 
-		if isQuiet() {
-			fmt.Println(folder.ID)
-			return nil
-		}
-
-		format := getOutputFormat()
-		return outputFolder(folder, format)
+		res := newMutation("folders.create", folderParentID)
+		res.CreatedIDs = []string{folder.ID}
+		res.Result = folder
+		return outputMutation(res, func() error {
+			return outputFolder(folder, getOutputFormat())
+		})
 	},
 }
 
@@ -102,30 +111,39 @@ Examples:
 			targetID = ""
 		}
 
+		if isDryRun() {
+			return dryRunOutput("folders.move", []string{folderID}, map[string]interface{}{
+				"destination_folder": folderTargetID,
+			})
+		}
+
 		if err := client.MoveFolder(folderID, targetID); err != nil {
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("folders.move", folderID)
+		res.Details = map[string]interface{}{"destination_folder": folderTargetID}
+		return outputMutation(res, func() error {
 			if targetID == "" {
 				fmt.Printf("Folder %s moved to root\n", folderID)
 			} else {
 				fmt.Printf("Folder %s moved to %s\n", folderID, targetID)
 			}
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
 var foldersDeleteCmd = &cobra.Command{
-	Use:   "delete [folder-id]",
-	Short: "Delete a folder",
-	Long:  "Delete a folder. Contents will be moved to the parent folder.",
-	Args:  cobra.ExactArgs(1),
+	Use:         "delete [folder-id]",
+	Short:       "Delete a folder",
+	Long:        "Delete a folder. Contents will be moved to the parent folder.",
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if isDryRun() {
-			return dryRunOutput("delete folder", map[string]interface{}{
-				"id": args[0], "destructive": true,
+			return dryRunOutput("folders.delete", []string{args[0]}, map[string]interface{}{
+				"destructive": true,
 			})
 		}
 
@@ -139,10 +157,12 @@ var foldersDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("folders.delete", folderID)
+		res.DeletedIDs = []string{folderID}
+		return outputMutation(res, func() error {
 			fmt.Printf("Folder %s deleted\n", folderID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -27,12 +27,18 @@ Output Formats:
   structured  Full block tree with all metadata (for LLMs)
   craft       MCP-style markdown with XML tags (matches Craft MCP server)
   rich        Terminal output with ANSI colors and Unicode
+  pandoc-json Pandoc JSON AST (pipe to pandoc for DOCX/PDF)
+  llm         Compact outline with short block handles (e.g. ^3fa2) that
+              blocks commands accept in place of IDs; see --max-tokens
 
 Examples:
   craft get abc123                        # Default JSON output
   craft get abc123 --format structured    # Full block tree for AI processing
   craft get abc123 --format craft         # MCP-compatible format
-  craft get abc123 --format rich          # Pretty terminal output`,
+  craft get abc123 --format rich          # Pretty terminal output
+  craft get abc123 --format pandoc-json | pandoc -f json -o doc.docx
+  craft get abc123 --format llm --max-tokens 2000
+  craft get abc123 --format llm --max-tokens 2000 --cursor ^3fa2`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := getAPIClient()
@@ -46,8 +52,17 @@ Examples:
 		}
 		format := getOutputFormat()
 
-		// For structured/craft/rich formats, get full block response
-		if format == FormatStructured || format == FormatCraft || format == FormatRich {
+		// Streaming emits the block tree one block per line
+		if isStreaming() && outputFile == "" {
+			blocksResp, err := client.GetDocumentBlocksWithDepth(docID, getMaxDepth)
+			if err != nil {
+				return err
+			}
+			return streamBlockTree(blockFromResponse(&blocksResp), "", 0)
+		}
+
+		// For structured/craft/rich/pandoc formats, get full block response
+		if format == FormatStructured || format == FormatCraft || format == FormatRich || format == FormatPandocJSON || format == FormatLLM {
 			blocksResp, err := client.GetDocumentBlocksWithDepth(docID, getMaxDepth)
 			if err != nil {
 				return err
@@ -65,6 +80,10 @@ Examples:
 				return outputBlocksCraft(&blocksResp)
 			case FormatRich:
 				return outputBlocksRich(&blocksResp)
+			case FormatPandocJSON:
+				return outputBlocksPandoc(&blocksResp)
+			case FormatLLM:
+				return outputBlocksLLM(&blocksResp)
 			}
 		}
 
@@ -115,6 +134,25 @@ func writeBlocksToFile(blocksResp *models.BlocksResponse, format string) error {
 		var sb strings.Builder
 		renderBlockCraft(&sb, blockFromResponse(blocksResp), 0) // Use craft format for files
 		content = sb.String()
+	case FormatPandocJSON:
+		rendered, err := renderPandocJSON(blocksResp)
+		if err != nil {
+			return err
+		}
+		content = rendered
+	case FormatLLM:
+		store, err := loadHandleStore(handlesPath())
+		if err != nil {
+			return err
+		}
+		rendered, err := renderLLM(blockFromResponse(blocksResp), store, llmMaxTokens, llmCursor)
+		if err != nil {
+			return err
+		}
+		if err := store.save(); err != nil {
+			return err
+		}
+		content = rendered
 	}
 
 	if err := os.WriteFile(outputFile, []byte(content), 0644); err != nil {
@@ -131,4 +169,6 @@ func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write document content to file")
 	getCmd.Flags().IntVar(&getMaxDepth, "max-depth", -1, "Maximum block nesting depth (-1 = all, 0 = top level only)")
+	getCmd.Flags().IntVar(&llmMaxTokens, "max-tokens", 0, "With --format llm: truncate at about this many tokens (0 = no limit)")
+	getCmd.Flags().StringVar(&llmCursor, "cursor", "", "With --format llm: resume output at this block handle")
 }
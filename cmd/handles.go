@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+)
+
+const (
+	// handlePrefix marks a short block handle (e.g. ^3fa2) in place of a block ID.
+	handlePrefix = "^"
+	// handlesFileName stores the handle → block ID mapping next to the config.
+	handlesFileName = "handles.json"
+	// minHandleLen is the number of hex digits in a handle before collisions extend it.
+	minHandleLen = 4
+	// maxStoredHandles bounds the handle store; least recently used entries are dropped.
+	maxStoredHandles = 20000
+)
+
+// handleEntry records the block a handle refers to.
+type handleEntry struct {
+	ID       string    `json:"id"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// handleStore maps short handles to block IDs. Handles are derived from a
+// hash of the block ID, so the same block keeps the same handle across runs.
+type handleStore struct {
+	path    string
+	Handles map[string]handleEntry `json:"handles"`
+	byID    map[string]string
+	// touched holds the handles this process assigned or used; only they
+	// are written over the store on disk, which other runs may have changed.
+	touched map[string]bool
+}
+
+// handlesPath returns the location of the handle store.
+func handlesPath() string {
+	if cfgManager == nil {
+		return ""
+	}
+	return filepath.Join(cfgManager.Dir(), handlesFileName)
+}
+
+// loadHandleStore reads the handle store at path. A missing file yields an empty store.
+func loadHandleStore(path string) (*handleStore, error) {
+	store := &handleStore{path: path, Handles: map[string]handleEntry{}, touched: map[string]bool{}}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read handle store: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, store); err != nil {
+				return nil, fmt.Errorf("failed to parse handle store: %w", err)
+			}
+			if store.Handles == nil {
+				store.Handles = map[string]handleEntry{}
+			}
+		}
+	}
+	store.byID = make(map[string]string, len(store.Handles))
+	for h, e := range store.Handles {
+		store.byID[e.ID] = h
+	}
+	return store, nil
+}
+
+// handleFor returns the handle for a block ID, assigning one if needed.
+// New handles use the shortest hash prefix (at least minHandleLen digits)
+// that is not already taken by a different block.
+func (s *handleStore) handleFor(id string) string {
+	now := time.Now().UTC()
+	if h, ok := s.byID[id]; ok {
+		s.Handles[h] = handleEntry{ID: id, LastUsed: now}
+		s.touched[h] = true
+		return h
+	}
+
+	sum := sha1.Sum([]byte(id))
+	digest := hex.EncodeToString(sum[:])
+	h := ""
+	for n := minHandleLen; n <= len(digest); n++ {
+		candidate := handlePrefix + digest[:n]
+		if _, taken := s.Handles[candidate]; !taken {
+			h = candidate
+			break
+		}
+	}
+	if h == "" {
+		// Full digest collision; fall back to the raw ID
+		return id
+	}
+
+	s.Handles[h] = handleEntry{ID: id, LastUsed: now}
+	s.byID[id] = h
+	s.touched[h] = true
+	return h
+}
+
+// resolve returns the block ID for a handle.
+func (s *handleStore) resolve(handle string) (string, bool) {
+	e, ok := s.Handles[handle]
+	return e.ID, ok
+}
+
+// save merges the handles this process used into the store on disk and
+// writes it, dropping the least recently used entries beyond
+// maxStoredHandles.
+func (s *handleStore) save() error {
+	if len(s.touched) == 0 || s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return config.WithFileLock(s.path, func() error {
+		current, err := loadHandleStore(s.path)
+		if err != nil {
+			return err
+		}
+		for h := range s.touched {
+			current.Handles[h] = s.Handles[h]
+		}
+		s.Handles = current.Handles
+
+		if len(s.Handles) > maxStoredHandles {
+			handles := make([]string, 0, len(s.Handles))
+			for h := range s.Handles {
+				handles = append(handles, h)
+			}
+			sort.Slice(handles, func(i, j int) bool {
+				return s.Handles[handles[i]].LastUsed.After(s.Handles[handles[j]].LastUsed)
+			})
+			for _, h := range handles[maxStoredHandles:] {
+				delete(s.Handles, h)
+			}
+		}
+		s.byID = make(map[string]string, len(s.Handles))
+		for h, e := range s.Handles {
+			s.byID[e.ID] = h
+		}
+
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if err := config.WriteFileAtomic(s.path, data); err != nil {
+			return fmt.Errorf("failed to write handle store: %w", err)
+		}
+		s.touched = map[string]bool{}
+		return nil
+	})
+}
+
+// isBlockHandle reports whether ref looks like a short block handle.
+func isBlockHandle(ref string) bool {
+	return strings.HasPrefix(ref, handlePrefix) && len(ref) > len(handlePrefix)
+}
+
+// resolveBlockRefs resolves each non-empty reference in place.
+func resolveBlockRefs(refs ...*string) error {
+	for _, ref := range refs {
+		if *ref == "" {
+			continue
+		}
+		id, err := resolveBlockRef(*ref)
+		if err != nil {
+			return err
+		}
+		*ref = id
+	}
+	return nil
+}
+
+// resolveBlockRef turns a block handle from `--format llm` output, a
+// bookmark or a document title into a block ID. Anything else is returned
+// unchanged.
+func resolveBlockRef(ref string) (string, error) {
+	if !isBlockHandle(ref) {
+		return resolveIDRef(ref, idDocument)
+	}
+	store, err := loadHandleStore(handlesPath())
+	if err != nil {
+		return "", err
+	}
+	id, ok := store.resolve(ref)
+	if !ok {
+		return "", fmt.Errorf("unknown block handle %s (handles come from `craft get --format llm`)", ref)
+	}
+	return id, nil
+}
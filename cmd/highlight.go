@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Colors used for basic syntax highlighting in rich output
+const (
+	syntaxKeyword = colorMagenta
+	syntaxString  = colorGreen
+	syntaxNumber  = colorYellow
+	syntaxComment = colorDim + colorItalic
+)
+
+// syntaxKeywords lists the keywords highlighted per language. Languages that
+// are not listed fall back to the shared C-like set.
+var syntaxKeywords = map[string][]string{
+	"go":         {"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var", "nil", "true", "false"},
+	"python":     {"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield", "None", "True", "False"},
+	"javascript": {"async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete", "do", "else", "export", "extends", "finally", "for", "function", "if", "import", "in", "instanceof", "let", "new", "of", "return", "switch", "this", "throw", "try", "typeof", "var", "while", "yield", "null", "undefined", "true", "false"},
+	"shell":      {"if", "then", "else", "elif", "fi", "for", "while", "do", "done", "case", "esac", "function", "in", "return", "export", "local"},
+	"sql":        {"select", "from", "where", "insert", "into", "update", "delete", "create", "table", "join", "left", "right", "inner", "on", "and", "or", "not", "null", "group", "by", "order", "limit", "as", "values", "set"},
+	"default":    {"if", "else", "for", "while", "return", "function", "class", "import", "const", "let", "var", "true", "false", "null"},
+}
+
+// syntaxAliases maps language names to the keyword set they share.
+var syntaxAliases = map[string]string{
+	"golang":     "go",
+	"py":         "python",
+	"js":         "javascript",
+	"ts":         "javascript",
+	"typescript": "javascript",
+	"jsx":        "javascript",
+	"tsx":        "javascript",
+	"bash":       "shell",
+	"sh":         "shell",
+	"zsh":        "shell",
+}
+
+// lineCommentPrefix returns the single-line comment marker for a language.
+func lineCommentPrefix(lang string) string {
+	switch lang {
+	case "python", "shell":
+		return "#"
+	case "sql":
+		return "--"
+	}
+	return "//"
+}
+
+// highlightCode applies basic ANSI syntax highlighting (keywords, strings,
+// numbers, line comments) to source code. It works line by line and does not
+// attempt to understand multi-line strings or block comments.
+func highlightCode(code, language string) string {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := syntaxAliases[lang]; ok {
+		lang = alias
+	}
+	words, ok := syntaxKeywords[lang]
+	if !ok {
+		words = syntaxKeywords["default"]
+	}
+	keywords := make(map[string]bool, len(words))
+	for _, w := range words {
+		keywords[w] = true
+	}
+	caseInsensitive := lang == "sql"
+	comment := lineCommentPrefix(lang)
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = highlightLine(line, keywords, caseInsensitive, comment)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightLine highlights a single line of code.
+func highlightLine(line string, keywords map[string]bool, caseInsensitive bool, comment string) string {
+	var sb strings.Builder
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case strings.HasPrefix(string(runes[i:]), comment):
+			sb.WriteString(syntaxComment + string(runes[i:]) + colorReset)
+			return sb.String()
+
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			sb.WriteString(syntaxString + string(runes[i:j+1]) + colorReset)
+			i = j + 1
+
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == '_') {
+				j++
+			}
+			sb.WriteString(syntaxNumber + string(runes[i:j]) + colorReset)
+			i = j
+
+		case isIdentRune(r):
+			j := i
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			lookup := word
+			if caseInsensitive {
+				lookup = strings.ToLower(word)
+			}
+			if keywords[lookup] {
+				sb.WriteString(syntaxKeyword + word + colorReset)
+			} else {
+				sb.WriteString(word)
+			}
+			i = j
+
+		default:
+			sb.WriteRune(r)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
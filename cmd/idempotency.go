@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+)
+
+const (
+	// idempotencyFileName stores the idempotency ledger next to the config.
+	idempotencyFileName = "idempotency.json"
+	// idempotencyRetention is how long completed entries are kept.
+	idempotencyRetention = 30 * 24 * time.Hour
+)
+
+var idempotencyKey string
+
+// idempotencyEntry records the progress of one keyed operation. Steps are
+// recorded as they succeed, so a re-run with the same key skips them.
+type idempotencyEntry struct {
+	Action      string `json:"action"`
+	Fingerprint string `json:"fingerprint"`
+	DocumentID  string `json:"document_id,omitempty"`
+	TitleDone   bool   `json:"title_done,omitempty"`
+	Cleared     bool   `json:"cleared,omitempty"`
+	ChunksDone  int    `json:"chunks_done,omitempty"`
+	// Pending is content computed from the document before it was changed
+	// (section replace), kept so a resumed run appends the same chunks.
+	Pending   string          `json:"pending,omitempty"`
+	Complete  bool            `json:"complete"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// idempotencyLedger maps idempotency keys to the operations they started.
+// Keys are namespaced by space, so the same key used against another space
+// starts a new operation instead of replaying one from elsewhere.
+type idempotencyLedger struct {
+	path  string
+	space string
+	// touched holds the entries this process began; only they are
+	// written back, so concurrent runs keep each other's entries.
+	touched map[string]bool
+	Entries map[string]*idempotencyEntry `json:"entries"`
+}
+
+// idempotencyPath returns the location of the idempotency ledger.
+func idempotencyPath() string {
+	if cfgManager == nil {
+		return ""
+	}
+	return filepath.Join(cfgManager.Dir(), idempotencyFileName)
+}
+
+// loadIdempotencyLedger reads the ledger at path for the space behind
+// apiURL. A missing file yields an empty ledger.
+func loadIdempotencyLedger(path, apiURL string) (*idempotencyLedger, error) {
+	// The URL itself may grant access, so only its hash is stored.
+	space := requestFingerprint(apiURL)[:16]
+	ledger := &idempotencyLedger{path: path, space: space, touched: map[string]bool{}, Entries: map[string]*idempotencyEntry{}}
+	if path == "" {
+		return ledger, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read idempotency ledger: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, ledger); err != nil {
+			return nil, fmt.Errorf("failed to parse idempotency ledger: %w", err)
+		}
+		if ledger.Entries == nil {
+			ledger.Entries = map[string]*idempotencyEntry{}
+		}
+	}
+	return ledger, nil
+}
+
+// requestFingerprint hashes the inputs of an operation so a key reused for
+// a different request is detected.
+func requestFingerprint(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// begin returns the entry for key, starting a new one if the key is unused.
+// Reusing a key for a different action or request is an error.
+func (l *idempotencyLedger) begin(key, action, fingerprint string) (*idempotencyEntry, error) {
+	key = l.entryKey(key)
+	l.touched[key] = true
+	if e, ok := l.Entries[key]; ok {
+		if e.Action != action || e.Fingerprint != fingerprint {
+			return nil, fmt.Errorf("idempotency key %q was already used for a different %s request", key, e.Action)
+		}
+		return e, nil
+	}
+	now := time.Now().UTC()
+	e := &idempotencyEntry{Action: action, Fingerprint: fingerprint, CreatedAt: now, UpdatedAt: now}
+	l.Entries[key] = e
+	return e, nil
+}
+
+// complete marks the entry for key as done and stores its result for replay.
+func (l *idempotencyLedger) complete(key string, res *mutationResult) error {
+	e := l.Entries[l.entryKey(key)]
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	e.Complete = true
+	e.Pending = ""
+	e.Result = data
+	e.UpdatedAt = time.Now().UTC()
+	return l.save()
+}
+
+func (l *idempotencyLedger) entryKey(key string) string {
+	return l.space + "/" + key
+}
+
+// save merges this process's entries into the ledger on disk and writes
+// it, dropping completed entries past idempotencyRetention.
+func (l *idempotencyLedger) save() error {
+	if l.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return config.WithFileLock(l.path, func() error {
+		current, err := loadIdempotencyLedger(l.path, "")
+		if err != nil {
+			return err
+		}
+		for key := range l.touched {
+			current.Entries[key] = l.Entries[key]
+		}
+		l.Entries = current.Entries
+
+		now := time.Now().UTC()
+		for key, e := range l.Entries {
+			if e.Complete && now.Sub(e.UpdatedAt) > idempotencyRetention {
+				delete(l.Entries, key)
+			}
+		}
+		data, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if err := config.WriteFileAtomic(l.path, data); err != nil {
+			return fmt.Errorf("failed to write idempotency ledger: %w", err)
+		}
+		return nil
+	})
+}
+
+// step records progress on e and saves the ledger.
+func (l *idempotencyLedger) step(e *idempotencyEntry, update func(e *idempotencyEntry)) error {
+	update(e)
+	e.UpdatedAt = time.Now().UTC()
+	return l.save()
+}
+
+// replayResult decodes the stored result of a completed entry.
+func (e *idempotencyEntry) replayResult() (*mutationResult, error) {
+	var res mutationResult
+	if err := json.Unmarshal(e.Result, &res); err != nil {
+		return nil, fmt.Errorf("failed to read stored result: %w", err)
+	}
+	if res.Details == nil {
+		res.Details = map[string]interface{}{}
+	}
+	res.Details["replayed"] = true
+	return &res, nil
+}
+
+// decodeResult decodes the result payload of a replayed mutation into v.
+func decodeResult(res *mutationResult, v interface{}) error {
+	data, err := json.Marshal(res.Result)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to read stored result: %w", err)
+	}
+	return nil
+}
+
+// started reports whether an earlier run already made progress on e.
+func (e *idempotencyEntry) started() bool {
+	return e.DocumentID != "" || e.TitleDone || e.Cleared || e.ChunksDone > 0 || e.Pending != ""
+}
+
+// resumeHint annotates an error from a keyed operation with how to resume it.
+func resumeHint(err error, key string) error {
+	return fmt.Errorf("%w (re-run with --idempotency-key %s to resume)", err, key)
+}
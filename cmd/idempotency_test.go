@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+)
+
+// flakyServer accepts document and block inserts, failing the failAt-th
+// POST /blocks (1-based; 0 never fails) once.
+type flakyServer struct {
+	mu        sync.Mutex
+	failAt    int
+	inserts   int
+	created   int
+	cleared   int
+	markdowns []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/documents":
+		s.created++
+		fmt.Fprintf(w, `{"items":[{"id":"doc%d","title":"Report"}]}`, s.created)
+	case r.Method == "POST" && r.URL.Path == "/blocks":
+		s.inserts++
+		if s.inserts == s.failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"boom"}`))
+			return
+		}
+		var body struct {
+			Markdown string `json:"markdown"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.markdowns = append(s.markdowns, body.Markdown)
+		w.Write([]byte(`{"items":[{"id":"b","type":"text","markdown":"x"}]}`))
+	case r.Method == "GET" && r.URL.Path == "/blocks":
+		w.Write([]byte(`{"id":"doc1","type":"page","markdown":"Report","content":[{"id":"old","type":"text","markdown":"old"}]}`))
+	case r.Method == "DELETE" && r.URL.Path == "/blocks":
+		s.cleared++
+		w.Write([]byte(`{"items":[{"id":"old"}]}`))
+	default:
+		w.Write([]byte(`{}`))
+	}
+}
+
+func useTempConfig(t *testing.T) {
+	t.Helper()
+	oldCfgManager := cfgManager
+	t.Cleanup(func() { cfgManager = oldCfgManager })
+	t.Setenv("HOME", t.TempDir())
+	manager, err := config.NewManager()
+	if err != nil {
+		t.Fatalf("failed to create config manager: %v", err)
+	}
+	cfgManager = manager
+}
+
+func TestIdempotentCreateResumesAndReplays(t *testing.T) {
+	useTempConfig(t)
+	fs := &flakyServer{failAt: 1}
+	server := httptest.NewServer(fs)
+	defer server.Close()
+
+	args := []string{"--api-url", server.URL, "create", "--title", "Report", "--markdown", "Body", "--idempotency-key", "k1"}
+	if _, err := runInProcess(args, ""); err == nil || !strings.Contains(err.Error(), "--idempotency-key k1 to resume") {
+		t.Fatalf("first run error = %v, want resume hint", err)
+	}
+
+	out, err := runInProcess(args, "")
+	if err != nil {
+		t.Fatalf("resumed run error = %v", err)
+	}
+	var res mutationResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if fs.created != 1 || strings.Join(res.CreatedIDs, ",") != "doc1" || res.Details["resumed"] != true {
+		t.Errorf("created %d documents, result %s", fs.created, out)
+	}
+
+	out, err = runInProcess(args, "")
+	if err != nil {
+		t.Fatalf("replayed run error = %v", err)
+	}
+	res = mutationResult{}
+	json.Unmarshal([]byte(out), &res)
+	if fs.created != 1 || fs.inserts != 2 || strings.Join(res.CreatedIDs, ",") != "doc1" || res.Details["replayed"] != true {
+		t.Errorf("replay made requests or changed result: created=%d inserts=%d\n%s", fs.created, fs.inserts, out)
+	}
+
+	changed := append(append([]string{}, args...), "--parent", "p1")
+	if _, err := runInProcess(changed, ""); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Errorf("reusing a key for a different request: error = %v", err)
+	}
+}
+
+func TestIdempotencyKeysArePerSpace(t *testing.T) {
+	useTempConfig(t)
+	first, second := &flakyServer{}, &flakyServer{}
+	server1, server2 := httptest.NewServer(first), httptest.NewServer(second)
+	defer server1.Close()
+	defer server2.Close()
+
+	for _, url := range []string{server1.URL, server2.URL} {
+		if _, err := runInProcess([]string{"--api-url", url, "create", "--title", "Report", "--idempotency-key", "k1"}, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.created != 1 || second.created != 1 {
+		t.Errorf("created %d and %d documents; a key from one space was replayed in another", first.created, second.created)
+	}
+}
+
+func TestIdempotencyLedgerKeepsConcurrentEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), idempotencyFileName)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ledger, err := loadIdempotencyLedger(path, "https://example.com/api/v1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			key := fmt.Sprintf("k%d", i)
+			if _, err := ledger.begin(key, "documents.create", "f"); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := ledger.complete(key, newMutation("documents.create")); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ledger, err := loadIdempotencyLedger(path, "")
+	if err != nil || len(ledger.Entries) != 10 {
+		t.Errorf("ledger has %d entries, want 10 (%v)", len(ledger.Entries), err)
+	}
+}
+
+func TestIdempotentUpdateResumesChunkedAppend(t *testing.T) {
+	useTempConfig(t)
+	fs := &flakyServer{failAt: 3}
+	server := httptest.NewServer(fs)
+	defer server.Close()
+
+	content := "para one\n\npara two\n\npara three\n\npara four"
+	args := []string{"--api-url", server.URL, "update", "doc1", "--mode", "replace", "--markdown", content,
+		"--chunk-bytes", "10", "--idempotency-key", "k2"}
+	if _, err := runInProcess(args, ""); err == nil {
+		t.Fatal("expected the third chunk to fail")
+	}
+	if _, err := runInProcess(args, ""); err != nil {
+		t.Fatalf("resumed run error = %v", err)
+	}
+	if _, err := runInProcess(args, ""); err != nil {
+		t.Fatalf("replayed run error = %v", err)
+	}
+
+	want := "para one|para two|para three|para four"
+	if got := strings.Join(fs.markdowns, "|"); got != want || fs.cleared != 1 {
+		t.Errorf("inserted %q (cleared %d times), want %q once", got, fs.cleared, want)
+	}
+}
@@ -2,10 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/ashrafali/craft-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -27,17 +24,23 @@ var infoCmd = &cobra.Command{
 			return err
 		}
 
-		// Get config file path
-		homeDir, _ := os.UserHomeDir()
-		configPath := filepath.Join(homeDir, config.ConfigDirName, config.ConfigFileName)
+		configPath := cfgManager.Path()
+		active, err := cfgManager.ActiveProfile()
+		if err != nil {
+			return err
+		}
 
 		fmt.Println("Craft CLI Information")
 		fmt.Println("=====================")
-		if cfg.ActiveProfile != "" {
-			fmt.Printf("Active Profile:   %s\n", cfg.ActiveProfile)
-			if profile, ok := cfg.Profiles[cfg.ActiveProfile]; ok {
-				fmt.Printf("API URL:          %s\n", profile.URL)
-				if profile.APIKey != "" {
+		if active != "" {
+			fmt.Printf("Active Profile:   %s\n", active)
+			if profile, ok := cfg.Profiles[active]; ok {
+				url, err := cfgManager.GetActiveURL()
+				if err != nil {
+					return err
+				}
+				fmt.Printf("API URL:          %s\n", url)
+				if profile.APIKey != "" || profile.APIKeyRef != "" {
 					fmt.Printf("Authentication:   API Key (configured)\n")
 				} else {
 					fmt.Printf("Authentication:   Public Link\n")
@@ -66,18 +69,10 @@ var infoCmd = &cobra.Command{
 				fmt.Println("✗ Read:   Denied")
 			}
 
-			// Test write permission (dry-run create)
-			// Note: We can't truly test write without making changes
-			fmt.Println("  Write:  Use 'craft create --dry-run' to test")
-
-			// Test delete permission
-			// Note: Can't safely test without actually deleting
-			fmt.Println("  Delete: Use 'craft delete --dry-run' to test")
-
-			fmt.Println()
-			fmt.Println("Note: Write and delete permissions are difficult to test")
-			fmt.Println("without making actual changes. Try the operations with")
-			fmt.Println("--dry-run flag to see if you get PERMISSION_DENIED errors.")
+			// Write and delete can only be tested by making changes,
+			// which craft doctor does in a sandbox document.
+			fmt.Println("  Write:  Run 'craft doctor --sandbox DOC_ID' to test")
+			fmt.Println("  Delete: Run 'craft doctor --sandbox DOC_ID' to test")
 			fmt.Println()
 		}
 
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// inProcess is set while a command runs through runInProcess. Background
+// work tied to a real process start (such as the update check) is skipped.
+var inProcess bool
+
+// runInProcess executes a craft command line in the current process and
+// returns what it wrote to stdout. stdin supplies the command's standard
+// input. Flags are reset to their defaults first so calls do not leak state
+// into each other. Calls must not run concurrently.
+func runInProcess(args []string, stdin string) (string, error) {
+	resetFlags(rootCmd)
+
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdinW.Close()
+		return "", fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	go func() {
+		io.WriteString(stdinW, stdin)
+		stdinW.Close()
+	}()
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&buf, stdoutR)
+		close(done)
+	}()
+
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinR, stdoutW
+	inProcess = true
+	defer func() {
+		os.Stdin, os.Stdout = origStdin, origStdout
+		inProcess = false
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs(args)
+	_, runErr := rootCmd.ExecuteC()
+
+	stdoutW.Close()
+	<-done
+	stdoutR.Close()
+	stdinR.Close()
+
+	return buf.String(), runErr
+}
+
+// execInProcess executes a craft command line in the current process,
+// sharing its standard streams. Flags are reset first, as in runInProcess.
+func execInProcess(args []string) error {
+	wasInProcess := inProcess
+	resetFlags(rootCmd)
+	inProcess = true
+	defer func() {
+		inProcess = wasInProcess
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs(args)
+	_, err := rootCmd.ExecuteC()
+	return err
+}
+
+// changedPersistentFlags returns those of the named root persistent flags
+// that were set on the current command line, as arguments.
+func changedPersistentFlags(names ...string) []string {
+	want := map[string]bool{}
+	for _, name := range names {
+		want[name] = true
+	}
+	var args []string
+	rootCmd.PersistentFlags().Visit(func(f *pflag.Flag) {
+		if !want[f.Name] {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// resetFlags restores every flag in the command tree to its default value.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			def := strings.Trim(f.DefValue, "[]")
+			if def == "" {
+				sv.Replace([]string{})
+			} else {
+				sv.Replace(strings.Split(def, ","))
+			}
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
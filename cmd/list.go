@@ -38,18 +38,32 @@ Examples:
   craft list --folder abc123                      # List documents in folder
   craft list --location unsorted                  # List unsorted documents
   craft list --created-after 2025-01-01           # Created since Jan 2025
-  craft list --modified-after 2025-06-01 --metadata  # Recently modified with metadata`,
+  craft list --modified-after 2025-06-01 --metadata  # Recently modified with metadata
+  craft list --stream                             # NDJSON, one document per line
+  craft list --all-profiles --format table        # Documents of every profile`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if isFanOut() {
+			return runFanOut(func(client *api.Client) ([]models.Document, error) {
+				result, err := listDocuments(client)
+				if err != nil {
+					return nil, err
+				}
+				return result.Items, nil
+			}, fanOutView[models.Document]{
+				Columns: []string{"ID", "TITLE", "UPDATED"},
+				Row: func(doc models.Document) []string {
+					return []string{doc.ID, truncate(doc.Title, 50), doc.LastModifiedAt.Format("2006-01-02 15:04")}
+				},
+				Limit: listLimit,
+			})
+		}
+
 		client, err := getAPIClient()
 		if err != nil {
 			return err
 		}
 
-		useAdvanced := listCreatedAfter != "" || listCreatedBefore != "" ||
-			listModifiedAfter != "" || listModifiedBefore != "" || listMetadata
-
-		var result *models.DocumentList
-		if useAdvanced {
+		if isStreaming() {
 			opts := api.ListDocumentsOptions{
 				FolderID:            listFolderID,
 				Location:            listLocation,
@@ -59,10 +73,10 @@ Examples:
 				LastModifiedDateGte: listModifiedAfter,
 				LastModifiedDateLte: listModifiedBefore,
 			}
-			result, err = client.GetDocumentsAdvanced(opts)
-		} else {
-			result, err = client.GetDocumentsFiltered(listFolderID, listLocation)
+			return finishStream(client.StreamDocuments(opts, ndjsonSink[models.Document](listLimit)))
 		}
+
+		result, err := listDocuments(client)
 		if err != nil {
 			return err
 		}
@@ -79,6 +93,24 @@ Examples:
 	},
 }
 
+// listDocuments fetches the documents selected by the list flags.
+func listDocuments(client *api.Client) (*models.DocumentList, error) {
+	useAdvanced := listCreatedAfter != "" || listCreatedBefore != "" ||
+		listModifiedAfter != "" || listModifiedBefore != "" || listMetadata
+	if !useAdvanced {
+		return client.GetDocumentsFiltered(listFolderID, listLocation)
+	}
+	return client.GetDocumentsAdvanced(api.ListDocumentsOptions{
+		FolderID:            listFolderID,
+		Location:            listLocation,
+		FetchMetadata:       listMetadata,
+		CreatedDateGte:      listCreatedAfter,
+		CreatedDateLte:      listCreatedBefore,
+		LastModifiedDateGte: listModifiedAfter,
+		LastModifiedDateLte: listModifiedBefore,
+	})
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&listFolderID, "folder", "", "Filter by folder ID")
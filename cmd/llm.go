@@ -9,11 +9,14 @@ import (
 )
 
 type llmFlagSpec struct {
-	Name      string `json:"name"`
-	Shorthand string `json:"shorthand,omitempty"`
-	Type      string `json:"type"`
-	Default   string `json:"default"`
-	Usage     string `json:"usage"`
+	Name      string   `json:"name"`
+	Shorthand string   `json:"shorthand,omitempty"`
+	Type      string   `json:"type"`
+	Default   string   `json:"default"`
+	Usage     string   `json:"usage"`
+	Required  bool     `json:"required,omitempty"`
+	Enum      []string `json:"enum,omitempty"`
+	Format    string   `json:"format,omitempty"`
 }
 
 type llmCommandSpec struct {
@@ -34,16 +37,22 @@ type llmSpec struct {
 	Notes     []string         `json:"notes"`
 }
 
-func flagsToSpec(fs *pflag.FlagSet) []llmFlagSpec {
+func flagsToSpec(c *cobra.Command, fs *pflag.FlagSet) []llmFlagSpec {
 	var specs []llmFlagSpec
 	fs.VisitAll(func(f *pflag.Flag) {
-		specs = append(specs, llmFlagSpec{
+		spec := llmFlagSpec{
 			Name:      f.Name,
 			Shorthand: f.Shorthand,
 			Type:      f.Value.Type(),
 			Default:   f.DefValue,
 			Usage:     f.Usage,
-		})
+			Required:  isRequiredFlag(f),
+		}
+		if fc, ok := lookupFlagConstraint(c, f.Name); ok {
+			spec.Enum = fc.Enum
+			spec.Format = fc.Format
+		}
+		specs = append(specs, spec)
 	})
 	return specs
 }
@@ -54,7 +63,7 @@ func commandToSpec(c *cobra.Command) llmCommandSpec {
 		Short:   c.Short,
 		Long:    c.Long,
 		Aliases: c.Aliases,
-		Flags:   flagsToSpec(c.Flags()),
+		Flags:   flagsToSpec(c, c.Flags()),
 	}
 
 	for _, sc := range c.Commands() {
@@ -81,7 +90,7 @@ Subcommands:
 			Tool:      "craft",
 			Version:   version,
 			Generated: time.Now().UTC().Format(time.RFC3339),
-			Global:    flagsToSpec(rootCmd.PersistentFlags()),
+			Global:    flagsToSpec(rootCmd, rootCmd.PersistentFlags()),
 			Notes: []string{
 				"Default output is JSON. Use --format compact (legacy JSON), table, or markdown for human output where supported.",
 				"craft delete is a soft-delete to trash (DELETE /documents).",
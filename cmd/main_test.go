@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain points HOME at a scratch directory so commands run by tests never
+// write config, ledgers or audit entries into the real home directory, and
+// clears environment settings that would override the test config.
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "craft-cli-test-home")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", envAPIURL, envAPIKey, envProfile, envFormat, policyEnvVar} {
+		os.Unsetenv(env)
+	}
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
@@ -0,0 +1,533 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// mcpProtocolVersions lists supported MCP protocol versions, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// mcpExcluded lists top-level commands that are not exposed as MCP tools
+// (interactive, process-level, or the server itself).
+var mcpExcluded = map[string]bool{
+	"mcp":        true,
+	"setup":      true,
+	"upgrade":    true,
+	"completion": true,
+	"config":     true,
+	"help":       true,
+	"schema":     true,
+	"openapi":    true,
+}
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol server",
+	Long: `Run craft as a Model Context Protocol (MCP) server so AI clients can
+call craft commands as tools and read documents as resources.`,
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve MCP over stdio",
+	Long: `Speak MCP (JSON-RPC 2.0, one message per line) on stdin/stdout.
+
+Every craft command is exposed as a tool (e.g. blocks_add, tasks_list),
+generated from the same command tree as 'craft schema'. Destructive tools
+carry destructiveHint; read-only tools carry readOnlyHint.
+
+Resources:
+  craft://documents/{id}          Document content as markdown
+  craft://daily-notes/{date}      Daily note (today, yesterday, YYYY-MM-DD)
+
+The active profile (or --profile/--api-url) is used for all requests;
+tool calls cannot switch it. Confirmations are not skipped unless the
+server is started with --yes, or with --allow-yes and a tool call passes
+"yes"; policies with require_confirmation also need a "reason".
+
+Example client configuration:
+  {"command": "craft", "args": ["mcp", "serve"]}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		srv := newMCPServer(client, buildSchema(rootCmd))
+		// Connection flags given to serve apply to every tool call
+		srv.baseArgs = changedPersistentFlags("profile", "api-url", "api-key")
+		if yesFlag {
+			srv.baseArgs = append(srv.baseArgs, "--yes")
+		}
+		return srv.serve(os.Stdin, os.Stdout)
+	},
+}
+
+var mcpAllowYes bool
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+	mcpServeCmd.Flags().BoolVar(&mcpAllowYes, "allow-yes", false, `Let tool calls skip confirmations by passing "yes"`)
+}
+
+// rpcRequest is an incoming JSON-RPC message. Notifications have no ID.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is an outgoing JSON-RPC response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is a tool advertised in tools/list.
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations map[string]interface{} `json:"annotations"`
+
+	path  []string
+	flags map[string]bool
+}
+
+// mcpServer dispatches MCP requests. Tool calls run the matching craft
+// command in-process; resources are read directly through client.
+type mcpServer struct {
+	client *api.Client
+	tools  []mcpTool
+	byName map[string]*mcpTool
+	run    func(args []string, stdin string) (string, error)
+	// baseArgs are global flags appended to every tool call
+	baseArgs []string
+}
+
+// newMCPServer builds the tool list from the command schema.
+func newMCPServer(client *api.Client, schema CommandSchema) *mcpServer {
+	s := &mcpServer{client: client, byName: map[string]*mcpTool{}, run: runInProcess}
+	for _, sc := range schema.Subcommands {
+		if mcpExcluded[sc.Name] {
+			continue
+		}
+		s.collectTools(sc, nil)
+	}
+	sort.Slice(s.tools, func(i, j int) bool { return s.tools[i].Name < s.tools[j].Name })
+	for i := range s.tools {
+		s.byName[s.tools[i].Name] = &s.tools[i]
+	}
+	return s
+}
+
+// collectTools adds a tool for each leaf command under sc.
+func (s *mcpServer) collectTools(sc CommandSchema, parent []string) {
+	path := append(append([]string{}, parent...), sc.Name)
+	if len(sc.Subcommands) > 0 {
+		for _, sub := range sc.Subcommands {
+			s.collectTools(sub, path)
+		}
+		return
+	}
+	s.tools = append(s.tools, mcpToolFromSchema(sc, path))
+}
+
+// mcpToolFromSchema converts a command schema into an MCP tool definition.
+func mcpToolFromSchema(sc CommandSchema, path []string) mcpTool {
+	props := map[string]interface{}{}
+	flags := map[string]bool{}
+	var required []string
+	for _, f := range sc.Flags {
+		name := strings.TrimPrefix(f.Name, "--")
+		prop := map[string]interface{}{"type": mcpJSONType(f.Type), "description": f.Desc}
+		if prop["type"] == "array" {
+			prop["items"] = map[string]interface{}{"type": "string"}
+		}
+		if len(f.Enum) > 0 && !f.EnumList && prop["type"] == "string" {
+			prop["enum"] = f.Enum
+		}
+		if f.Required {
+			required = append(required, name)
+		}
+		props[name] = prop
+		flags[name] = true
+	}
+
+	usage := strings.TrimSuffix(sc.Usage, " [flags]")
+	if strings.ContainsAny(usage, "[<") {
+		props["args"] = map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Positional arguments: " + usage,
+		}
+	}
+
+	safety := sc.Safety
+	if safety == nil {
+		safety = &SafetyInfo{}
+	}
+	if safety.DryRun && !safety.ReadOnly {
+		props["dry-run"] = map[string]interface{}{"type": "boolean", "description": "Preview the change without applying it"}
+		flags["dry-run"] = true
+	}
+	props["format"] = map[string]interface{}{"type": "string", "description": "Output format (default json)"}
+	flags["format"] = true
+	if !safety.ReadOnly {
+		props["reason"] = map[string]interface{}{"type": "string", "description": "Why the change is made; recorded in the audit log and required by policies with require_confirmation"}
+		flags["reason"] = true
+		// Only the operator can let the agent skip confirmations.
+		if mcpAllowYes {
+			props["yes"] = map[string]interface{}{"type": "boolean", "description": "Skip confirmation prompts (default false)"}
+			flags["yes"] = true
+		}
+	}
+	props["stdin"] = map[string]interface{}{"type": "string", "description": "Text passed to the command on standard input"}
+
+	inputSchema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+
+	return mcpTool{
+		Name:        strings.Join(path, "_"),
+		Description: sc.Description,
+		InputSchema: inputSchema,
+		Annotations: map[string]interface{}{
+			"title":           "craft " + strings.Join(path, " "),
+			"readOnlyHint":    safety.ReadOnly,
+			"destructiveHint": safety.Destructive,
+			"idempotentHint":  safety.Idempotent,
+			"openWorldHint":   true,
+		},
+		path:  path,
+		flags: flags,
+	}
+}
+
+// mcpJSONType maps a pflag type name to a JSON Schema type.
+func mcpJSONType(flagType string) string {
+	switch flagType {
+	case "bool":
+		return "boolean"
+	case "int", "int64", "int32", "uint", "uint64":
+		return "integer"
+	case "float64", "float32":
+		return "number"
+	case "stringSlice", "stringArray", "intSlice":
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+// serve reads requests from in until EOF, writing responses to out.
+func (s *mcpServer) serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if resp := s.handle([]byte(line)); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle processes one message and returns the response, or nil for notifications.
+func (s *mcpServer) handle(data []byte) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"),
+			Error: &rpcError{Code: rpcParseError, Message: "parse error: " + err.Error()}}
+	}
+	if req.Method == "" {
+		return &rpcResponse{JSONRPC: "2.0", ID: idOrNull(req.ID),
+			Error: &rpcError{Code: rpcInvalidRequest, Message: "missing method"}}
+	}
+
+	result, rerr := s.dispatch(req.Method, req.Params)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if rerr != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// idOrNull returns id, or a JSON null when the request had none.
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+// dispatch routes a method to its handler.
+func (s *mcpServer) dispatch(method string, params json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools}, nil
+	case "tools/call":
+		return s.callTool(params)
+	case "resources/list":
+		return s.listResources()
+	case "resources/templates/list":
+		return map[string]interface{}{"resourceTemplates": mcpResourceTemplates}, nil
+	case "resources/read":
+		return s.readResource(params)
+	}
+	if strings.HasPrefix(method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + method}
+}
+
+// initialize negotiates the protocol version and advertises capabilities.
+func (s *mcpServer) initialize(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+	}
+
+	negotiated := mcpProtocolVersions[0]
+	for _, v := range mcpProtocolVersions {
+		if v == p.ProtocolVersion {
+			negotiated = v
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": negotiated,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "craft-cli",
+			"version": version,
+		},
+	}, nil
+}
+
+// callTool runs the craft command behind a tool. Command failures are
+// reported as tool results with isError set, not as protocol errors.
+func (s *mcpServer) callTool(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	tool, ok := s.byName[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	args, err := tool.commandLine(p.Arguments)
+	if err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	stdin, _ := p.Arguments["stdin"].(string)
+
+	out, runErr := s.run(append(append([]string{}, s.baseArgs...), args...), stdin)
+	text := out
+	if runErr != nil {
+		text = strings.TrimSpace(out + "\nError: " + runErr.Error())
+	}
+	return map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": text}},
+		"isError": runErr != nil,
+	}, nil
+}
+
+// commandLine converts tool arguments into craft command-line arguments.
+// JSON output is applied unless overridden.
+func (t *mcpTool) commandLine(arguments map[string]interface{}) ([]string, error) {
+	args := append([]string{}, t.path...)
+
+	keys := make([]string, 0, len(arguments))
+	for k := range arguments {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var positional []string
+	hasFormat := false
+	for _, k := range keys {
+		v := arguments[k]
+		switch {
+		case k == "stdin":
+			continue
+		case k == "args":
+			list, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("args must be an array of strings")
+			}
+			for _, item := range list {
+				positional = append(positional, fmt.Sprint(item))
+			}
+			continue
+		case !t.flags[k]:
+			return nil, fmt.Errorf("unknown argument %q for tool %s", k, t.Name)
+		}
+
+		if k == "format" {
+			hasFormat = true
+		}
+		switch val := v.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range val {
+				args = append(args, fmt.Sprintf("--%s=%v", k, item))
+			}
+		case float64:
+			args = append(args, fmt.Sprintf("--%s=%s", k, strconv.FormatFloat(val, 'f', -1, 64)))
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", k, val))
+		}
+	}
+
+	if !hasFormat {
+		args = append(args, "--format=json")
+	}
+	if len(positional) > 0 {
+		args = append(args, "--")
+		args = append(args, positional...)
+	}
+	return args, nil
+}
+
+// mcpResourceTemplates describes the parameterized resources.
+var mcpResourceTemplates = []map[string]interface{}{
+	{
+		"uriTemplate": "craft://documents/{id}",
+		"name":        "Craft document",
+		"description": "Document content as markdown",
+		"mimeType":    "text/markdown",
+	},
+	{
+		"uriTemplate": "craft://daily-notes/{date}",
+		"name":        "Craft daily note",
+		"description": "Daily note by date (today, yesterday, tomorrow, or YYYY-MM-DD)",
+		"mimeType":    "text/markdown",
+	},
+}
+
+// listResources lists documents and today's daily note.
+func (s *mcpServer) listResources() (interface{}, *rpcError) {
+	resources := []map[string]interface{}{{
+		"uri":      "craft://daily-notes/today",
+		"name":     "Today's daily note",
+		"mimeType": "text/markdown",
+	}}
+
+	docs, err := s.client.GetDocuments()
+	if err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+	}
+	for _, doc := range docs.Items {
+		resources = append(resources, map[string]interface{}{
+			"uri":      "craft://documents/" + doc.ID,
+			"name":     doc.Title,
+			"mimeType": "text/markdown",
+		})
+	}
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+// readResource returns the markdown for a document or daily note URI.
+func (s *mcpServer) readResource(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+
+	var text string
+	switch {
+	case strings.HasPrefix(p.URI, "craft://documents/"):
+		id, err := resolveIDRef(strings.TrimPrefix(p.URI, "craft://documents/"), idDocument)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		if err := validateResourceID(id, "document ID"); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		md, err := s.client.GetDocumentContentMarkdown(id)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		text = md
+	case strings.HasPrefix(p.URI, "craft://daily-notes/"):
+		date := strings.TrimPrefix(p.URI, "craft://daily-notes/")
+		block, err := s.client.GetBlockByDate(resolveDay(date), -1, false)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		var sb strings.Builder
+		renderBlockCraft(&sb, block, 0)
+		text = sb.String()
+	default:
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown resource: " + p.URI}
+	}
+
+	return map[string]interface{}{
+		"contents": []map[string]interface{}{{
+			"uri":      p.URI,
+			"mimeType": "text/markdown",
+			"text":     text,
+		}},
+	}, nil
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+)
+
+func TestMCPToolsFromSchema(t *testing.T) {
+	srv := newMCPServer(nil, buildSchema(rootCmd))
+
+	del, ok := srv.byName["blocks_delete"]
+	if !ok {
+		t.Fatal("expected a blocks_delete tool")
+	}
+	if del.Annotations["destructiveHint"] != true || del.Annotations["readOnlyHint"] != false {
+		t.Errorf("blocks_delete annotations = %v", del.Annotations)
+	}
+
+	list, ok := srv.byName["list"]
+	if !ok {
+		t.Fatal("expected a list tool")
+	}
+	if list.Annotations["readOnlyHint"] != true {
+		t.Errorf("list annotations = %v", list.Annotations)
+	}
+	for _, name := range []string{"profile", "yes", "reason"} {
+		if _, ok := list.InputSchema["properties"].(map[string]interface{})[name]; ok {
+			t.Errorf("list has a %s argument", name)
+		}
+	}
+	if !del.flags["reason"] || del.flags["yes"] || del.flags["profile"] {
+		t.Errorf("blocks_delete arguments = %v, want reason without yes or profile", del.flags)
+	}
+	mcpAllowYes = true
+	defer func() { mcpAllowYes = false }()
+	if srv := newMCPServer(nil, buildSchema(rootCmd)); !srv.byName["blocks_delete"].flags["yes"] {
+		t.Error("--allow-yes should give blocks_delete a yes argument")
+	}
+
+	for _, name := range []string{"mcp_serve", "setup", "upgrade", "blocks"} {
+		if _, ok := srv.byName[name]; ok {
+			t.Errorf("tool %s should not be exposed", name)
+		}
+	}
+}
+
+func TestMCPToolCommandLine(t *testing.T) {
+	tool := mcpTool{
+		Name:  "blocks_add",
+		path:  []string{"blocks", "add"},
+		flags: map[string]bool{"markdown": true, "dry-run": true, "format": true, "tags": true},
+	}
+
+	got, err := tool.commandLine(map[string]interface{}{
+		"args":     []interface{}{"page1"},
+		"markdown": "hello",
+		"dry-run":  true,
+		"tags":     []interface{}{"a", "b"},
+		"stdin":    "ignored",
+	})
+	if err != nil {
+		t.Fatalf("commandLine() error = %v", err)
+	}
+	want := []string{"blocks", "add", "--dry-run=true", "--markdown=hello", "--tags=a", "--tags=b", "--format=json", "--", "page1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commandLine() = %v, want %v", got, want)
+	}
+
+	if _, err := tool.commandLine(map[string]interface{}{"bogus": "x"}); err == nil {
+		t.Error("expected error for unknown argument")
+	}
+}
+
+func TestMCPServeProtocol(t *testing.T) {
+	srv := newMCPServer(nil, buildSchema(rootCmd))
+	var gotArgs []string
+	srv.run = func(args []string, stdin string) (string, error) {
+		gotArgs = args
+		return `{"ok":true}`, nil
+	}
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"version","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nope"}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := srv.serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 responses, got %d: %s", len(lines), out.String())
+	}
+
+	var init struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	json.Unmarshal([]byte(lines[0]), &init)
+	if init.Result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("negotiated version = %q", init.Result.ProtocolVersion)
+	}
+
+	if !strings.Contains(lines[2], `"isError":false`) || !strings.Contains(lines[2], `{\"ok\":true}`) {
+		t.Errorf("tools/call response = %s", lines[2])
+	}
+	if len(gotArgs) == 0 || gotArgs[0] != "version" {
+		t.Errorf("tool ran with args %v", gotArgs)
+	}
+	if !strings.Contains(lines[3], `-32601`) {
+		t.Errorf("unknown method response = %s", lines[3])
+	}
+	if !strings.Contains(lines[4], `-32700`) {
+		t.Errorf("parse error response = %s", lines[4])
+	}
+}
+
+func TestMCPReadDocumentResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"doc1","type":"page","markdown":"Title","content":[{"id":"b1","type":"text","markdown":"Hello"}]}`))
+	}))
+	defer server.Close()
+
+	srv := newMCPServer(api.NewClient(server.URL), CommandSchema{})
+	result, rerr := srv.readResource(json.RawMessage(`{"uri":"craft://documents/doc1"}`))
+	if rerr != nil {
+		t.Fatalf("readResource() error = %v", rerr.Message)
+	}
+	data, _ := json.Marshal(result)
+	if !strings.Contains(string(data), "Hello") || !strings.Contains(string(data), "craft://documents/doc1") {
+		t.Errorf("resource contents = %s", data)
+	}
+
+	if _, rerr := srv.readResource(json.RawMessage(`{"uri":"http://example.com"}`)); rerr == nil {
+		t.Error("expected error for unknown resource URI")
+	}
+}
+
+func TestRunInProcessResetsFlags(t *testing.T) {
+	out, err := runInProcess([]string{"version", "--format", "table"}, "")
+	if err != nil {
+		t.Fatalf("runInProcess() error = %v", err)
+	}
+	if !strings.Contains(out, "craft-cli version") {
+		t.Errorf("output = %q", out)
+	}
+	if _, err := runInProcess([]string{"version"}, ""); err != nil {
+		t.Fatalf("runInProcess() error = %v", err)
+	}
+	if outputFormat != "" {
+		t.Errorf("outputFormat = %q, expected reset to default", outputFormat)
+	}
+}
@@ -31,13 +31,13 @@ Examples:
 		}
 
 		if isDryRun() {
-			target := map[string]interface{}{"id": args[0]}
+			target := map[string]interface{}{}
 			if moveTargetFolder != "" {
 				target["destination_folder"] = moveTargetFolder
 			} else {
 				target["destination_location"] = moveTargetLocation
 			}
-			return dryRunOutput("move", target)
+			return dryRunOutput("documents.move", []string{args[0]}, target)
 		}
 
 		client, err := getAPIClient()
@@ -50,14 +50,20 @@ Examples:
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("documents.move", docID)
+		if moveTargetFolder != "" {
+			res.Details = map[string]interface{}{"destination_folder": moveTargetFolder}
+		} else {
+			res.Details = map[string]interface{}{"destination_location": moveTargetLocation}
+		}
+		return outputMutation(res, func() error {
 			if moveTargetFolder != "" {
 				fmt.Printf("Document %s moved to folder %s\n", docID, moveTargetFolder)
 			} else {
 				fmt.Printf("Document %s moved to %s\n", docID, moveTargetLocation)
 			}
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
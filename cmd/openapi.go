@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var openapiOut string
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Output the OpenAPI 3.1 spec of the Craft endpoints used by the CLI",
+	Long: `Print an OpenAPI 3.1 document describing every Craft API endpoint,
+query parameter and payload the CLI uses. The spec is generated from the
+client's request and response types, so it always matches this build.
+
+A copy is kept in docs/openapi.json.`,
+	Example: `  craft openapi
+  craft openapi -o openapi.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := openAPIJSON()
+		if err != nil {
+			return err
+		}
+		if openapiOut == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(openapiOut, data, 0644); err != nil {
+			return fmt.Errorf("failed to write spec: %w", err)
+		}
+		printStatus("Wrote OpenAPI spec to %s\n", openapiOut)
+		return nil
+	},
+}
+
+// openAPIJSON renders the generated spec as indented JSON.
+func openAPIJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(api.OpenAPISpec(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.Flags().StringVarP(&openapiOut, "output", "o", "", "Write the spec to a file instead of stdout")
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+// TestOpenAPIDocUpToDate fails when docs/openapi.json drifts from the
+// generated spec. Regenerate with: go run . openapi -o docs/openapi.json
+func TestOpenAPIDocUpToDate(t *testing.T) {
+	want, err := openAPIJSON()
+	if err != nil {
+		t.Fatalf("openAPIJSON() error = %v", err)
+	}
+	got, err := os.ReadFile("../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("read docs/openapi.json: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Error("docs/openapi.json is stale; run: go run . openapi -o docs/openapi.json")
+	}
+}
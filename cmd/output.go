@@ -207,52 +207,30 @@ func outputMarkdown(docs []models.Document) error {
 	return nil
 }
 
-// outputDocumentMarkdown prints a single document as markdown
+// outputDocumentMarkdown prints a single document as markdown, paging long content
 func outputDocumentMarkdown(doc *models.Document) error {
-	fmt.Printf("# %s\n", doc.Title)
-	fmt.Printf("- **ID**: %s\n", doc.ID)
-	fmt.Printf("- **Space ID**: %s\n", doc.SpaceID)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", doc.Title)
+	fmt.Fprintf(&sb, "- **ID**: %s\n", doc.ID)
+	fmt.Fprintf(&sb, "- **Space ID**: %s\n", doc.SpaceID)
 
 	if doc.ParentID != "" {
-		fmt.Printf("- **Parent ID**: %s\n", doc.ParentID)
+		fmt.Fprintf(&sb, "- **Parent ID**: %s\n", doc.ParentID)
 	}
 
-	fmt.Printf("- **Created**: %s\n", doc.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("- **Updated**: %s\n", doc.LastModifiedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("- **Has Children**: %v\n", doc.HasChildren)
+	fmt.Fprintf(&sb, "- **Created**: %s\n", doc.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "- **Updated**: %s\n", doc.LastModifiedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "- **Has Children**: %v\n", doc.HasChildren)
 
 	if doc.Markdown != "" {
-		fmt.Println("\n## Content")
-		fmt.Println(doc.Markdown)
+		sb.WriteString("\n## Content\n")
+		sb.WriteString(doc.Markdown + "\n")
 	} else if doc.Content != "" {
-		fmt.Println("\n## Content")
-		fmt.Println(doc.Content)
+		sb.WriteString("\n## Content\n")
+		sb.WriteString(doc.Content + "\n")
 	}
 
-	return nil
-}
-
-// outputCreated outputs the result of a create operation
-func outputCreated(doc *models.Document, format string) error {
-	if isQuiet() {
-		// In quiet mode, just output the ID
-		fmt.Println(doc.ID)
-		return nil
-	}
-	return outputDocument(doc, format)
-}
-
-// outputDeleted outputs the result of a delete operation
-func outputDeleted(docID string) {
-	if !isQuiet() {
-		fmt.Printf("Document %s moved to trash\n", docID)
-	}
-}
-
-func outputCleared(docID string, deletedBlocks int) {
-	if !isQuiet() {
-		fmt.Printf("Document %s cleared (%d blocks deleted)\n", docID, deletedBlocks)
-	}
+	return writeTerminal(sb.String())
 }
 
 // outputSearchResults prints search results in the specified format
@@ -16,6 +16,7 @@ const (
 	colorDim       = "\033[2m"
 	colorItalic    = "\033[3m"
 	colorUnderline = "\033[4m"
+	colorStrike    = "\033[9m"
 
 	colorRed       = "\033[31m"
 	colorGreen     = "\033[32m"
@@ -69,12 +70,12 @@ func outputBlocksCraft(resp *models.BlocksResponse) error {
 	return nil
 }
 
-// outputBlocksRich outputs with ANSI colors and Unicode for terminal
+// outputBlocksRich outputs with ANSI colors and Unicode for terminal.
+// Colors are dropped when stdout is not a TTY and long output is paged.
 func outputBlocksRich(resp *models.BlocksResponse) error {
 	var sb strings.Builder
 	renderBlockRich(&sb, blockFromResponse(resp), 0)
-	fmt.Print(sb.String())
-	return nil
+	return writeTerminal(sb.String())
 }
 
 // blockFromResponse converts BlocksResponse to Block for unified rendering
@@ -235,9 +236,7 @@ func renderBlockRich(sb *strings.Builder, block *models.Block, depth int) {
 		renderTextRich(sb, block, indent)
 
 	case "code":
-		sb.WriteString(colorDim)
-		sb.WriteString(block.Markdown)
-		sb.WriteString(colorReset + "\n")
+		renderCodeRich(sb, block, indent)
 
 	case "table":
 		renderTableRich(sb, block, indent)
@@ -345,16 +344,16 @@ func renderTextRich(sb *strings.Builder, block *models.Block, indent string) {
 		if block.TaskInfo != nil {
 			switch block.TaskInfo.State {
 			case "done":
-				prefix = taskDone + " "
+				prefix = colorGreen + taskDone + colorReset + " "
 				md = colorGreen + md + colorReset
 			case "canceled":
-				prefix = taskCanceled + " "
-				md = colorDim + md + colorReset
+				prefix = colorDim + taskCanceled + colorReset + " "
+				md = colorDim + colorStrike + md + colorReset
 			default:
-				prefix = taskTodo + " "
+				prefix = colorYellow + taskTodo + colorReset + " "
 			}
 		} else {
-			prefix = taskTodo + " "
+			prefix = colorYellow + taskTodo + colorReset + " "
 		}
 	case "toggle":
 		prefix = toggleClosed + " "
@@ -363,8 +362,8 @@ func renderTextRich(sb *strings.Builder, block *models.Block, indent string) {
 	// Handle decorations
 	if sliceContains(block.Decorations, "callout") {
 		color := colorBgYellow
-		if block.Color != "" {
-			color = getColorFromHex(block.Color)
+		if bg := ansiBackground(block.Color); bg != "" {
+			color = bg
 		}
 		sb.WriteString(fmt.Sprintf("%s%s %s %s\n", fullIndent, color, md, colorReset))
 	} else if sliceContains(block.Decorations, "quote") {
@@ -390,6 +389,11 @@ func renderTextRich(sb *strings.Builder, block *models.Block, indent string) {
 			styledMd = colorDim + "`" + md + "`" + colorReset
 		}
 
+		// Apply block text color
+		if fg := ansiForeground(block.Color); fg != "" {
+			styledMd = fg + styledMd + colorReset
+		}
+
 		sb.WriteString(fullIndent + prefix + styledMd + "\n")
 	}
 
@@ -405,25 +409,27 @@ func renderTableRich(sb *strings.Builder, block *models.Block, indent string) {
 	sb.WriteString(indent + block.Markdown + "\n")
 }
 
-// getColorFromHex converts hex color to ANSI escape code
-func getColorFromHex(hex string) string {
-	// Map common Craft colors to ANSI
-	switch strings.ToLower(hex) {
-	case "#0064ff", "gradient-blue":
-		return colorBgBlue
-	case "#ff9500", "gradient-orange":
-		return "\033[48;5;208m"
-	case "#ff3b30", "gradient-red":
-		return "\033[41m"
-	case "#34c759", "gradient-green":
-		return "\033[42m"
-	case "#af52de", "gradient-purple":
-		return "\033[45m"
-	case "#ffcc00", "gradient-yellow":
-		return colorBgYellow
-	default:
-		return colorBgCyan
+// renderCodeRich renders a code block with basic syntax highlighting
+func renderCodeRich(sb *strings.Builder, block *models.Block, indent string) {
+	code := block.RawCode
+	if code == "" {
+		code = stripCodeFence(block.Markdown)
+	}
+	if block.Language != "" {
+		sb.WriteString(indent + colorDim + block.Language + colorReset + "\n")
+	}
+	for _, line := range strings.Split(highlightCode(code, block.Language), "\n") {
+		sb.WriteString(indent + colorDim + cardVertical + colorReset + " " + line + "\n")
+	}
+}
+
+// stripCodeFence removes a surrounding ``` fence from code block markdown
+func stripCodeFence(md string) string {
+	lines := strings.Split(strings.TrimRight(md, "\n"), "\n")
+	if len(lines) >= 2 && strings.HasPrefix(lines[0], "```") && strings.HasPrefix(lines[len(lines)-1], "```") {
+		lines = lines[1 : len(lines)-1]
 	}
+	return strings.Join(lines, "\n")
 }
 
 // sliceContains checks if a slice contains a string
@@ -445,6 +451,8 @@ const (
 	FormatStructured = "structured"
 	FormatCraft      = "craft"
 	FormatRich       = "rich"
+	FormatPandocJSON = "pandoc-json"
+	FormatLLM        = "llm"
 )
 
 // ValidOutputFormats lists all valid output formats
@@ -456,6 +464,8 @@ var ValidOutputFormats = []string{
 	FormatStructured,
 	FormatCraft,
 	FormatRich,
+	FormatPandocJSON,
+	FormatLLM,
 }
 
 // IsValidFormat checks if a format string is valid
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+var (
+	llmMaxTokens int
+	llmCursor    string
+)
+
+// llmLine is one outline entry: a block handle and its rendered text.
+type llmLine struct {
+	handle string
+	text   string
+}
+
+// estimateTokens approximates the token count of s (about 4 characters per token).
+func estimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// outputBlocksLLM prints the block tree as a compact outline for LLM prompts.
+// Block handles are recorded so later commands can accept them in place of IDs.
+func outputBlocksLLM(resp *models.BlocksResponse) error {
+	store, err := loadHandleStore(handlesPath())
+	if err != nil {
+		return err
+	}
+
+	out, err := renderLLM(blockFromResponse(resp), store, llmMaxTokens, llmCursor)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+
+	if err := store.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save block handles: %v\n", err)
+	}
+	return nil
+}
+
+// renderLLM renders the outline. The header line is always included; block
+// lines start at cursor (a handle, or "" for the beginning) and stop before
+// maxTokens is exceeded (0 = no limit), ending with a continuation hint.
+func renderLLM(root *models.Block, store *handleStore, maxTokens int, cursor string) (string, error) {
+	header := fmt.Sprintf("%s # %s\n", store.handleFor(root.ID), oneLine(root.Markdown))
+
+	var lines []llmLine
+	for i := range root.Content {
+		collectLLMLines(&root.Content[i], store, 0, &lines)
+	}
+
+	start := 0
+	if cursor != "" {
+		start = -1
+		for i, l := range lines {
+			if l.handle == cursor {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return "", fmt.Errorf("cursor %s not found in document", cursor)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	used := estimateTokens(header)
+	for i := start; i < len(lines); i++ {
+		if maxTokens > 0 && used+estimateTokens(lines[i].text) > maxTokens && i > start {
+			fmt.Fprintf(&sb, "… truncated (~%d tokens); continue with --cursor %s\n", used, lines[i].handle)
+			break
+		}
+		sb.WriteString(lines[i].text)
+		used += estimateTokens(lines[i].text)
+	}
+	return sb.String(), nil
+}
+
+// collectLLMLines appends the outline entry for block and its children.
+func collectLLMLines(block *models.Block, store *handleStore, depth int, lines *[]llmLine) {
+	handle := store.handleFor(block.ID)
+	indent := strings.Repeat("  ", depth+block.IndentationLevel)
+
+	var sb strings.Builder
+	sb.WriteString(indent + handle + " ")
+
+	switch block.Type {
+	case "page":
+		kind := "page"
+		if block.TextStyle == "card" {
+			kind = "card"
+		}
+		sb.WriteString(kind + ": " + oneLine(block.Markdown))
+	case "code":
+		code := block.RawCode
+		if code == "" {
+			code = stripCodeFence(block.Markdown)
+		}
+		sb.WriteString("```" + block.Language)
+		for _, l := range strings.Split(code, "\n") {
+			sb.WriteString("\n" + indent + "  " + l)
+		}
+		sb.WriteString("\n" + indent + "  ```")
+	case "table":
+		sb.WriteString("table:")
+		for _, l := range strings.Split(strings.TrimSpace(block.Markdown), "\n") {
+			sb.WriteString("\n" + indent + "  " + l)
+		}
+	case "line":
+		sb.WriteString("---")
+	case "image":
+		sb.WriteString("image: " + block.URL)
+		if block.AltText != "" {
+			sb.WriteString(" (" + block.AltText + ")")
+		}
+	case "file":
+		sb.WriteString("file: " + block.FileName + " " + block.URL)
+	case "richUrl":
+		sb.WriteString("link: " + block.Title + " " + block.URL)
+	default:
+		sb.WriteString(llmMarker(block) + oneLine(stripCraftMarkdown(block)))
+	}
+
+	sb.WriteString(llmAttrs(block))
+	sb.WriteString("\n")
+	*lines = append(*lines, llmLine{handle: handle, text: sb.String()})
+
+	for i := range block.Content {
+		collectLLMLines(&block.Content[i], store, depth+1, lines)
+	}
+}
+
+// llmMarker returns the compact prefix describing a text block's style.
+func llmMarker(block *models.Block) string {
+	var prefix string
+	if sliceContains(block.Decorations, "callout") {
+		prefix += "!callout "
+	}
+	if sliceContains(block.Decorations, "quote") {
+		prefix += "> "
+	}
+
+	switch block.ListStyle {
+	case "bullet":
+		return prefix + "- "
+	case "numbered":
+		return prefix + "1. "
+	case "toggle":
+		return prefix + "▸ "
+	case "task":
+		state := ""
+		if block.TaskInfo != nil {
+			state = block.TaskInfo.State
+		}
+		switch state {
+		case "done":
+			return prefix + "[x] "
+		case "canceled":
+			return prefix + "[-] "
+		}
+		return prefix + "[ ] "
+	}
+
+	switch block.TextStyle {
+	case "h1":
+		return prefix + "# "
+	case "h2":
+		return prefix + "## "
+	case "h3":
+		return prefix + "### "
+	case "h4":
+		return prefix + "#### "
+	case "caption":
+		return prefix + "(caption) "
+	}
+	return prefix
+}
+
+// llmAttrs returns non-default styling and task dates as a trailing {k=v} list.
+func llmAttrs(block *models.Block) string {
+	var attrs []string
+	if block.Color != "" {
+		attrs = append(attrs, "color="+block.Color)
+	}
+	if block.Font != "" && block.Font != "system" {
+		attrs = append(attrs, "font="+block.Font)
+	}
+	if block.TextAlignment != "" && block.TextAlignment != "left" {
+		attrs = append(attrs, "align="+block.TextAlignment)
+	}
+	if block.TaskInfo != nil {
+		if block.TaskInfo.ScheduleDate != "" {
+			attrs = append(attrs, "scheduled="+block.TaskInfo.ScheduleDate)
+		}
+		if block.TaskInfo.DeadlineDate != "" {
+			attrs = append(attrs, "due="+block.TaskInfo.DeadlineDate)
+		}
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " {" + strings.Join(attrs, ", ") + "}"
+}
+
+// oneLine collapses line breaks so each block stays on a single outline line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\n", " ⏎ ")), " ")
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+func llmTestDoc() *models.Block {
+	return &models.Block{
+		ID: "root-id", Type: "page", Markdown: "Plan",
+		Content: []models.Block{
+			{ID: "b1", Type: "text", TextStyle: "h2", Markdown: "## Goals"},
+			{ID: "b2", Type: "text", ListStyle: "task", Markdown: "- [ ] Ship it", TaskInfo: &models.TaskInfo{State: "todo", DeadlineDate: "2026-03-01"}},
+			{ID: "b3", Type: "text", Markdown: "Red text", Color: "#ef052a"},
+			{ID: "b4", Type: "text", ListStyle: "bullet", Markdown: "- Nested parent", Content: []models.Block{
+				{ID: "b5", Type: "text", Markdown: "child"},
+			}},
+		},
+	}
+}
+
+func TestRenderLLM(t *testing.T) {
+	store, _ := loadHandleStore("")
+	out, err := renderLLM(llmTestDoc(), store, 0, "")
+	if err != nil {
+		t.Fatalf("renderLLM() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d:\n%s", len(lines), out)
+	}
+	checks := []struct {
+		line int
+		want string
+	}{
+		{0, "# Plan"},
+		{1, " ## Goals"},
+		{2, " [ ] Ship it {due=2026-03-01}"},
+		{3, " Red text {color=#ef052a}"},
+		{4, " - Nested parent"},
+		{5, "  " + store.handleFor("b5") + " child"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(lines[c.line], c.want) {
+			t.Errorf("line %d = %q, want it to contain %q", c.line, lines[c.line], c.want)
+		}
+	}
+	if strings.Contains(out, "b2") || strings.Contains(out, "root-id") {
+		t.Errorf("full IDs should be replaced by handles:\n%s", out)
+	}
+}
+
+func TestRenderLLMMaxTokensAndCursor(t *testing.T) {
+	store, _ := loadHandleStore("")
+	out, err := renderLLM(llmTestDoc(), store, 12, "")
+	if err != nil {
+		t.Fatalf("renderLLM() error = %v", err)
+	}
+	if !strings.Contains(out, "--cursor ") {
+		t.Fatalf("expected continuation cursor:\n%s", out)
+	}
+	cursor := strings.TrimSpace(out[strings.LastIndex(out, "--cursor ")+len("--cursor "):])
+
+	rest, err := renderLLM(llmTestDoc(), store, 0, cursor)
+	if err != nil {
+		t.Fatalf("renderLLM(cursor) error = %v", err)
+	}
+	restLines := strings.Split(strings.TrimSpace(rest), "\n")
+	if !strings.HasPrefix(strings.TrimSpace(restLines[1]), cursor) {
+		t.Errorf("resumed output should start at %s:\n%s", cursor, rest)
+	}
+
+	if _, err := renderLLM(llmTestDoc(), store, 0, "^ffffffff"); err == nil {
+		t.Error("expected error for unknown cursor")
+	}
+}
+
+func TestHandleStorePersistsAndExtendsOnCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), handlesFileName)
+	store, err := loadHandleStore(path)
+	if err != nil {
+		t.Fatalf("loadHandleStore() error = %v", err)
+	}
+
+	h := store.handleFor("block-a")
+	if !strings.HasPrefix(h, handlePrefix) || len(h) != len(handlePrefix)+minHandleLen {
+		t.Errorf("handle = %q, want %s + %d hex digits", h, handlePrefix, minHandleLen)
+	}
+	if again := store.handleFor("block-a"); again != h {
+		t.Errorf("handle not stable: %q vs %q", h, again)
+	}
+
+	// Occupy block-b's short handle with a different block to force extension
+	short := store.handleFor("block-b")
+	delete(store.byID, "block-b")
+	store.Handles[short] = handleEntry{ID: "other"}
+	if extended := store.handleFor("block-b"); len(extended) <= len(short) || !strings.HasPrefix(extended, short) {
+		t.Errorf("collision should extend handle: %q -> %q", short, extended)
+	}
+
+	if err := store.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	reloaded, err := loadHandleStore(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if id, ok := reloaded.resolve(h); !ok || id != "block-a" {
+		t.Errorf("resolve(%s) = %q, %v", h, id, ok)
+	}
+}
+
+func TestHandleStoreKeepsConcurrentHandles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), handlesFileName)
+	first, _ := loadHandleStore(path)
+	second, _ := loadHandleStore(path)
+	a := first.handleFor("block-a")
+	b := second.handleFor("block-b")
+	if err := first.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, _ := loadHandleStore(path)
+	if id, _ := reloaded.resolve(a); id != "block-a" {
+		t.Errorf("handle %s from the first run was lost", a)
+	}
+	if id, _ := reloaded.resolve(b); id != "block-b" {
+		t.Errorf("handle %s from the second run was lost", b)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("handle store mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestBlocksGetLLMMaxTokens(t *testing.T) {
+	useTempConfig(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(llmTestDoc())
+	}))
+	defer server.Close()
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "blocks", "get", "root-id", "--format", "llm", "--max-tokens", "12"}, "")
+	if err != nil || !strings.Contains(out, "--cursor ") {
+		t.Fatalf("blocks get --max-tokens = %q, %v", out, err)
+	}
+	cursor := strings.TrimSpace(out[strings.LastIndex(out, "--cursor ")+len("--cursor "):])
+	rest, err := runInProcess([]string{"--api-url", server.URL, "blocks", "get", "root-id", "--format", "llm", "--cursor", cursor}, "")
+	if err != nil || strings.Contains(rest, "Goals") {
+		t.Errorf("blocks get --cursor %s = %q, %v", cursor, rest, err)
+	}
+}
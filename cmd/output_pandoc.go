@@ -0,0 +1,624 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+// pandocAPIVersion is the pandoc-types version the emitted AST targets (pandoc >= 3.0).
+var pandocAPIVersion = []int{1, 23, 1}
+
+// pandocNode is a single Pandoc AST element ({"t": tag, "c": contents}).
+// Elements without contents (Space, HorizontalRule, ...) omit "c".
+type pandocNode struct {
+	T string      `json:"t"`
+	C interface{} `json:"c,omitempty"`
+}
+
+// pandocDocument is the top-level Pandoc JSON document.
+type pandocDocument struct {
+	APIVersion []int                  `json:"pandoc-api-version"`
+	Meta       map[string]interface{} `json:"meta"`
+	Blocks     []pandocNode           `json:"blocks"`
+}
+
+// pandocAttr builds an Attr triple: [identifier, [classes], [[key, value]]].
+// Empty key/value pairs are dropped.
+func pandocAttr(id string, classes []string, kv ...[2]string) []interface{} {
+	if classes == nil {
+		classes = []string{}
+	}
+	pairs := [][]string{}
+	for _, p := range kv {
+		if p[0] != "" && p[1] != "" {
+			pairs = append(pairs, []string{p[0], p[1]})
+		}
+	}
+	return []interface{}{id, classes, pairs}
+}
+
+// outputBlocksPandoc outputs the block tree as a Pandoc JSON AST.
+func outputBlocksPandoc(resp *models.BlocksResponse) error {
+	encoder := json.NewEncoder(os.Stdout)
+	return encoder.Encode(blocksToPandoc(blockFromResponse(resp)))
+}
+
+// blocksToPandoc converts a root page block into a Pandoc document.
+// The root title becomes the document title; its children become the body.
+func blocksToPandoc(root *models.Block) *pandocDocument {
+	doc := &pandocDocument{
+		APIVersion: pandocAPIVersion,
+		Meta:       map[string]interface{}{},
+		Blocks:     []pandocNode{},
+	}
+
+	if root.Type == "page" || root.Type == "" {
+		if title := strings.TrimSpace(root.Markdown); title != "" {
+			doc.Meta["title"] = pandocNode{T: "MetaInlines", C: parsePandocInlines(title)}
+		}
+		doc.Blocks = append(doc.Blocks, pandocBlocks(root.Content, 1)...)
+		return doc
+	}
+
+	doc.Blocks = append(doc.Blocks, pandocBlocks([]models.Block{*root}, 1)...)
+	return doc
+}
+
+// pandocBlocks converts a sequence of sibling blocks. Consecutive list items
+// are grouped into (possibly nested) Pandoc lists.
+func pandocBlocks(blocks []models.Block, depth int) []pandocNode {
+	var out []pandocNode
+	for i := 0; i < len(blocks); {
+		if isPandocListItem(&blocks[i]) {
+			j := i
+			for j < len(blocks) && isPandocListItem(&blocks[j]) {
+				j++
+			}
+			out = append(out, pandocLists(blocks[i:j], depth)...)
+			i = j
+			continue
+		}
+		out = append(out, pandocBlock(&blocks[i], depth)...)
+		i++
+	}
+	return out
+}
+
+func isPandocListItem(block *models.Block) bool {
+	if block.Type != "" && block.Type != "text" {
+		return false
+	}
+	switch block.ListStyle {
+	case "bullet", "numbered", "task", "toggle":
+		return true
+	}
+	return false
+}
+
+// pandocListFrame accumulates the items of one list level while grouping.
+type pandocListFrame struct {
+	kind  string
+	level int
+	items [][]pandocNode
+}
+
+func (f *pandocListFrame) node() pandocNode {
+	items := make([]interface{}, len(f.items))
+	for i, item := range f.items {
+		items[i] = item
+	}
+	if f.kind == "numbered" {
+		listAttrs := []interface{}{1, pandocNode{T: "Decimal"}, pandocNode{T: "Period"}}
+		return pandocNode{T: "OrderedList", C: []interface{}{listAttrs, items}}
+	}
+	return pandocNode{T: "BulletList", C: items}
+}
+
+// pandocLists turns a run of list items into nested lists using IndentationLevel.
+func pandocLists(items []models.Block, depth int) []pandocNode {
+	var out []pandocNode
+	var stack []*pandocListFrame
+
+	pop := func() {
+		frame := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := frame.node()
+		if len(stack) == 0 {
+			out = append(out, node)
+			return
+		}
+		parent := stack[len(stack)-1]
+		if len(parent.items) == 0 {
+			parent.items = append(parent.items, []pandocNode{})
+		}
+		last := len(parent.items) - 1
+		parent.items[last] = append(parent.items[last], node)
+	}
+
+	for i := range items {
+		item := &items[i]
+		kind := item.ListStyle
+		if kind != "numbered" {
+			kind = "bullet"
+		}
+		level := item.IndentationLevel
+
+		for len(stack) > 0 && stack[len(stack)-1].level > level {
+			pop()
+		}
+		if len(stack) > 0 && stack[len(stack)-1].level == level && stack[len(stack)-1].kind != kind {
+			pop()
+		}
+		if len(stack) == 0 || stack[len(stack)-1].level < level {
+			stack = append(stack, &pandocListFrame{kind: kind, level: level})
+		}
+
+		top := stack[len(stack)-1]
+		top.items = append(top.items, pandocListItem(item, depth))
+	}
+	for len(stack) > 0 {
+		pop()
+	}
+	return out
+}
+
+// pandocListItem renders the contents of a single list item.
+func pandocListItem(block *models.Block, depth int) []pandocNode {
+	text := stripCraftMarkdown(block)
+	inlines := parsePandocInlines(text)
+
+	if block.ListStyle == "task" {
+		state := ""
+		if block.TaskInfo != nil {
+			state = block.TaskInfo.State
+		}
+		switch state {
+		case "done":
+			inlines = append([]interface{}{pandocNode{T: "Str", C: "☒"}, pandocNode{T: "Space"}}, inlines...)
+		case "canceled":
+			inlines = []interface{}{pandocNode{T: "Str", C: "☒"}, pandocNode{T: "Space"}, pandocNode{T: "Strikeout", C: inlines}}
+		default:
+			inlines = append([]interface{}{pandocNode{T: "Str", C: "☐"}, pandocNode{T: "Space"}}, inlines...)
+		}
+	}
+
+	content := []pandocNode{{T: "Plain", C: inlines}}
+	content = append(content, pandocBlocks(block.Content, depth)...)
+
+	if block.ListStyle == "toggle" {
+		return []pandocNode{{T: "Div", C: []interface{}{pandocAttr("", []string{"toggle"}), content}}}
+	}
+	return wrapPandocStyle(block, content)
+}
+
+// pandocBlock converts a single non-list block (and its children).
+func pandocBlock(block *models.Block, depth int) []pandocNode {
+	switch block.Type {
+	case "page":
+		return pandocPage(block, depth)
+	case "code":
+		return []pandocNode{pandocCode(block)}
+	case "table":
+		return []pandocNode{pandocTable(block)}
+	case "line":
+		if block.LineStyle == "pageBreak" {
+			return []pandocNode{{T: "RawBlock", C: []interface{}{"openxml", `<w:p><w:r><w:br w:type="page"/></w:r></w:p>`}}}
+		}
+		return []pandocNode{{T: "HorizontalRule"}}
+	case "image":
+		alt := parsePandocInlines(block.AltText)
+		img := pandocNode{T: "Image", C: []interface{}{pandocAttr("", nil), alt, []string{block.URL, ""}}}
+		return []pandocNode{{T: "Para", C: []interface{}{img}}}
+	case "file":
+		name := block.FileName
+		if name == "" {
+			name = block.URL
+		}
+		link := pandocNode{T: "Link", C: []interface{}{pandocAttr("", nil), parsePandocInlines(name), []string{block.URL, ""}}}
+		para := pandocNode{T: "Para", C: []interface{}{link}}
+		return []pandocNode{{T: "Div", C: []interface{}{pandocAttr("", []string{"file"}), []pandocNode{para}}}}
+	case "richUrl":
+		title := block.Title
+		if title == "" {
+			title = block.URL
+		}
+		link := pandocNode{T: "Link", C: []interface{}{pandocAttr("", nil), parsePandocInlines(title), []string{block.URL, ""}}}
+		content := []pandocNode{{T: "Para", C: []interface{}{link}}}
+		if block.Description != "" {
+			content = append(content, pandocNode{T: "Para", C: parsePandocInlines(block.Description)})
+		}
+		attr := pandocAttr("", []string{"rich-url"}, [2]string{"layout", block.Layout})
+		return []pandocNode{{T: "Div", C: []interface{}{attr, content}}}
+	}
+
+	// text and unknown types
+	text := stripCraftMarkdown(block)
+	var content []pandocNode
+	if text != "" {
+		inlines := parsePandocInlines(text)
+		switch block.TextStyle {
+		case "h1", "h2", "h3", "h4":
+			level := int(block.TextStyle[1] - '0')
+			content = append(content, pandocNode{T: "Header", C: []interface{}{level, pandocAttr("", nil), inlines}})
+		case "caption":
+			para := pandocNode{T: "Para", C: inlines}
+			attr := pandocAttr("", []string{"caption"}, [2]string{"custom-style", "Caption"})
+			content = append(content, pandocNode{T: "Div", C: []interface{}{attr, []pandocNode{para}}})
+		default:
+			content = append(content, pandocNode{T: "Para", C: inlines})
+		}
+	}
+
+	content = wrapPandocStyle(block, content)
+	return append(content, pandocBlocks(block.Content, depth)...)
+}
+
+// wrapPandocStyle applies decorations (callout, quote) and block-level styling
+// (color, font, alignment) as BlockQuote/Div wrappers so they survive conversion.
+func wrapPandocStyle(block *models.Block, content []pandocNode) []pandocNode {
+	if len(content) == 0 {
+		return content
+	}
+	if sliceContains(block.Decorations, "quote") {
+		content = []pandocNode{{T: "BlockQuote", C: content}}
+	}
+	if sliceContains(block.Decorations, "callout") {
+		attr := pandocAttr("", []string{"callout"},
+			[2]string{"custom-style", "Callout"},
+			[2]string{"color", block.Color},
+			[2]string{"font", block.Font},
+			[2]string{"align", block.TextAlignment},
+		)
+		return []pandocNode{{T: "Div", C: []interface{}{attr, content}}}
+	}
+	if block.Color != "" || block.Font != "" || block.TextAlignment != "" {
+		attr := pandocAttr("", nil,
+			[2]string{"color", block.Color},
+			[2]string{"font", block.Font},
+			[2]string{"align", block.TextAlignment},
+		)
+		return []pandocNode{{T: "Div", C: []interface{}{attr, content}}}
+	}
+	return content
+}
+
+// pandocPage renders a nested page or card as a Div with a heading.
+func pandocPage(block *models.Block, depth int) []pandocNode {
+	class := "page"
+	if block.TextStyle == "card" {
+		class = "card"
+	}
+	level := depth + 1
+	if level > 6 {
+		level = 6
+	}
+
+	var content []pandocNode
+	if title := strings.TrimSpace(block.Markdown); title != "" {
+		content = append(content, pandocNode{T: "Header", C: []interface{}{level, pandocAttr("", nil), parsePandocInlines(title)}})
+	}
+	content = append(content, pandocBlocks(block.Content, depth+1)...)
+
+	attr := pandocAttr("", []string{class},
+		[2]string{"craft-id", block.ID},
+		[2]string{"card-layout", block.CardLayout},
+	)
+	return []pandocNode{{T: "Div", C: []interface{}{attr, content}}}
+}
+
+var codeFencePattern = regexp.MustCompile("(?s)^```([^\\n]*)\\n(.*?)\\n?```\\s*$")
+
+// pandocCode renders a code block, preferring rawCode/language when available.
+func pandocCode(block *models.Block) pandocNode {
+	lang := block.Language
+	code := block.RawCode
+	if code == "" {
+		code = block.Markdown
+		if m := codeFencePattern.FindStringSubmatch(strings.TrimSpace(block.Markdown)); m != nil {
+			if lang == "" {
+				lang = strings.TrimSpace(m[1])
+			}
+			code = m[2]
+		}
+	}
+
+	if lang == "math_formula" {
+		math := pandocNode{T: "Math", C: []interface{}{pandocNode{T: "DisplayMath"}, code}}
+		return pandocNode{T: "Para", C: []interface{}{math}}
+	}
+
+	var classes []string
+	if lang != "" {
+		classes = []string{lang}
+	}
+	return pandocNode{T: "CodeBlock", C: []interface{}{pandocAttr("", classes), code}}
+}
+
+// pandocTable renders a table from structured rows, falling back to a
+// markdown pipe table. The first row is treated as the header.
+func pandocTable(block *models.Block) pandocNode {
+	var rows [][]string
+	if len(block.Rows) > 0 {
+		for _, r := range block.Rows {
+			var cells []string
+			for _, cell := range r {
+				cells = append(cells, cell.Value)
+			}
+			rows = append(rows, cells)
+		}
+	} else {
+		rows = parsePipeTable(block.Markdown)
+	}
+
+	cols := 0
+	for _, r := range rows {
+		if len(r) > cols {
+			cols = len(r)
+		}
+	}
+
+	colSpecs := make([]interface{}, cols)
+	for i := range colSpecs {
+		colSpecs[i] = []interface{}{pandocNode{T: "AlignDefault"}, pandocNode{T: "ColWidthDefault"}}
+	}
+
+	makeRow := func(cells []string) []interface{} {
+		out := make([]interface{}, cols)
+		for i := 0; i < cols; i++ {
+			text := ""
+			if i < len(cells) {
+				text = cells[i]
+			}
+			var blocks []pandocNode
+			if text != "" {
+				blocks = []pandocNode{{T: "Plain", C: parsePandocInlines(text)}}
+			} else {
+				blocks = []pandocNode{}
+			}
+			out[i] = []interface{}{pandocAttr("", nil), pandocNode{T: "AlignDefault"}, 1, 1, blocks}
+		}
+		return []interface{}{pandocAttr("", nil), out}
+	}
+
+	headRows := []interface{}{}
+	bodyRows := []interface{}{}
+	for i, r := range rows {
+		if i == 0 {
+			headRows = append(headRows, makeRow(r))
+		} else {
+			bodyRows = append(bodyRows, makeRow(r))
+		}
+	}
+
+	caption := []interface{}{nil, []pandocNode{}}
+	head := []interface{}{pandocAttr("", nil), headRows}
+	body := []interface{}{pandocAttr("", nil), 0, []interface{}{}, bodyRows}
+	foot := []interface{}{pandocAttr("", nil), []interface{}{}}
+
+	return pandocNode{T: "Table", C: []interface{}{pandocAttr("", nil), caption, colSpecs, head, []interface{}{body}, foot}}
+}
+
+// parsePipeTable parses a markdown pipe table into rows of cell text,
+// skipping the header separator line.
+func parsePipeTable(md string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		line = strings.Trim(line, "|")
+		parts := strings.Split(line, "|")
+		separator := true
+		var cells []string
+		for _, p := range parts {
+			p = strings.TrimSpace(p)
+			if strings.Trim(p, ":-") != "" {
+				separator = false
+			}
+			cells = append(cells, p)
+		}
+		if separator {
+			continue
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+var (
+	headingPrefixPattern = regexp.MustCompile(`^#{1,6}\s+`)
+	taskPrefixPattern    = regexp.MustCompile(`^[-*+]\s+\[[ xX\-]\]\s*`)
+	bulletPrefixPattern  = regexp.MustCompile(`^[-*+]\s+`)
+	numberPrefixPattern  = regexp.MustCompile(`^\d+[.)]\s+`)
+	wrapperTagPattern    = regexp.MustCompile(`^<(callout|caption)>(?s)(.*)</(callout|caption)>$`)
+)
+
+// stripCraftMarkdown removes block-level markdown syntax (heading hashes, list
+// markers, quote markers, callout/caption tags) that Pandoc expresses structurally.
+func stripCraftMarkdown(block *models.Block) string {
+	text := strings.TrimSpace(block.Markdown)
+	if m := wrapperTagPattern.FindStringSubmatch(text); m != nil {
+		text = strings.TrimSpace(m[2])
+	}
+	if sliceContains(block.Decorations, "quote") || strings.HasPrefix(text, "> ") {
+		text = strings.TrimPrefix(text, "> ")
+	}
+	switch block.ListStyle {
+	case "task":
+		text = taskPrefixPattern.ReplaceAllString(text, "")
+	case "bullet":
+		text = bulletPrefixPattern.ReplaceAllString(text, "")
+	case "numbered":
+		text = numberPrefixPattern.ReplaceAllString(text, "")
+	case "toggle":
+		text = strings.TrimPrefix(text, "+ ")
+	}
+	if strings.HasPrefix(block.TextStyle, "h") {
+		text = headingPrefixPattern.ReplaceAllString(text, "")
+	}
+	return strings.TrimSpace(text)
+}
+
+var highlightOpenPattern = regexp.MustCompile(`^<highlight(?:\s+color="([^"]*)")?>`)
+
+// parsePandocInlines converts a Craft inline markdown string into Pandoc inlines.
+// Supported: **bold**, *italic*, ~strike~/~~strike~~, `code`,
+// [label](url), $math$, and <highlight color="..."> spans.
+func parsePandocInlines(s string) []interface{} {
+	out := []interface{}{}
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, pandocTextInlines(text.String())...)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		if m := highlightOpenPattern.FindStringSubmatch(rest); m != nil {
+			if end := strings.Index(rest[len(m[0]):], "</highlight>"); end >= 0 {
+				flush()
+				inner := rest[len(m[0]) : len(m[0])+end]
+				attr := pandocAttr("", []string{"highlight"}, [2]string{"color", m[1]})
+				out = append(out, pandocNode{T: "Span", C: []interface{}{attr, parsePandocInlines(inner)}})
+				i += len(m[0]) + end + len("</highlight>")
+				continue
+			}
+		}
+
+		var prev byte
+		if i > 0 {
+			prev = s[i-1]
+		}
+		if node, n, ok := pandocDelimited(rest, prev); ok {
+			flush()
+			out = append(out, node)
+			i += n
+			continue
+		}
+
+		if rest[0] == '[' {
+			if close := strings.Index(rest, "]("); close > 0 {
+				if end := strings.IndexByte(rest[close+2:], ')'); end >= 0 {
+					flush()
+					label := rest[1:close]
+					target := rest[close+2 : close+2+end]
+					out = append(out, pandocNode{T: "Link", C: []interface{}{pandocAttr("", nil), parsePandocInlines(label), []string{target, ""}}})
+					i += close + 2 + end + 1
+					continue
+				}
+			}
+		}
+
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+	return out
+}
+
+// pandocDelimited matches a delimiter-wrapped inline at the start of s; prev
+// is the byte before s (0 at the start). Like Craft, the inner text must not
+// start or end with a space, single * and $ must not sit inside a word, and a
+// closing $ must not be followed by a digit, so "$5 and $10" stays text.
+func pandocDelimited(s string, prev byte) (pandocNode, int, bool) {
+	type delim struct {
+		open string
+		tag  string
+	}
+	for _, d := range []delim{{"**", "Strong"}, {"~~", "Strikeout"}, {"`", "Code"}, {"$$", "Math"}, {"$", "Math"}, {"*", "Emph"}, {"~", "Strikeout"}} {
+		if !strings.HasPrefix(s, d.open) || len(s) <= 2*len(d.open) {
+			continue
+		}
+		if (d.open == "*" || d.open == "$") && isWordByte(prev) {
+			continue
+		}
+		end := strings.Index(s[len(d.open):], d.open)
+		if end <= 0 {
+			continue
+		}
+		inner := s[len(d.open) : len(d.open)+end]
+		if strings.TrimSpace(inner) != inner {
+			continue
+		}
+		n := 2*len(d.open) + end
+		var next byte
+		if n < len(s) {
+			next = s[n]
+		}
+		switch d.open {
+		case "$", "$$":
+			if next >= '0' && next <= '9' {
+				continue
+			}
+		case "*":
+			if isWordByte(next) {
+				continue
+			}
+		}
+		switch d.tag {
+		case "Code":
+			return pandocNode{T: "Code", C: []interface{}{pandocAttr("", nil), inner}}, n, true
+		case "Math":
+			kind := "InlineMath"
+			if d.open == "$$" {
+				kind = "DisplayMath"
+			}
+			return pandocNode{T: "Math", C: []interface{}{pandocNode{T: kind}, inner}}, n, true
+		default:
+			return pandocNode{T: d.tag, C: parsePandocInlines(inner)}, n, true
+		}
+	}
+	return pandocNode{}, 0, false
+}
+
+// isWordByte reports whether b is an ASCII letter or digit.
+func isWordByte(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// pandocTextInlines splits plain text into Str/Space/SoftBreak inlines.
+func pandocTextInlines(s string) []interface{} {
+	var out []interface{}
+	var word strings.Builder
+	flushWord := func() {
+		if word.Len() > 0 {
+			out = append(out, pandocNode{T: "Str", C: word.String()})
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch r {
+		case '\n':
+			flushWord()
+			out = append(out, pandocNode{T: "SoftBreak"})
+		case ' ', '\t':
+			flushWord()
+			if n := len(out); n == 0 || out[n-1].(pandocNode).T != "Space" {
+				out = append(out, pandocNode{T: "Space"})
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flushWord()
+	return out
+}
+
+// renderPandocJSON renders blocks as Pandoc JSON for file output.
+func renderPandocJSON(resp *models.BlocksResponse) (string, error) {
+	data, err := json.Marshal(blocksToPandoc(blockFromResponse(resp)))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode pandoc json: %w", err)
+	}
+	return string(data) + "\n", nil
+}
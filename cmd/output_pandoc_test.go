@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+func TestBlocksToPandoc(t *testing.T) {
+	root := &models.Block{
+		ID:       "root",
+		Type:     "page",
+		Markdown: "My Doc",
+		Content: []models.Block{
+			{ID: "h", Type: "text", TextStyle: "h2", Markdown: "## Section"},
+			{ID: "c", Type: "text", Markdown: "<callout>Heads up</callout>", Decorations: []string{"callout"}, Color: "#00ca85"},
+			{ID: "p", Type: "text", Markdown: "Some <highlight color=\"yellow\">marked</highlight> **bold** text"},
+			{ID: "l1", Type: "text", ListStyle: "bullet", Markdown: "- one"},
+			{ID: "l2", Type: "text", ListStyle: "bullet", Markdown: "- two", IndentationLevel: 1},
+			{ID: "l3", Type: "text", ListStyle: "bullet", Markdown: "- three"},
+		},
+	}
+
+	doc := blocksToPandoc(root)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	out := string(data)
+
+	if !strings.Contains(out, `"pandoc-api-version":[1,23,1]`) {
+		t.Errorf("missing api version: %s", out)
+	}
+	if _, ok := doc.Meta["title"]; !ok {
+		t.Error("expected root title in meta")
+	}
+	if len(doc.Blocks) != 4 {
+		t.Fatalf("expected 4 top-level blocks (header, callout, para, list), got %d: %s", len(doc.Blocks), out)
+	}
+	if doc.Blocks[0].T != "Header" {
+		t.Errorf("blocks[0] = %s, want Header", doc.Blocks[0].T)
+	}
+	if doc.Blocks[1].T != "Div" || !strings.Contains(out, `["callout"]`) {
+		t.Errorf("callout should be a Div with class callout: %s", out)
+	}
+	if strings.Contains(out, "<callout>") || strings.Contains(out, "<highlight") {
+		t.Errorf("Craft tags leaked into output: %s", out)
+	}
+	if !strings.Contains(out, `["highlight"]`) || !strings.Contains(out, `"Strong"`) {
+		t.Errorf("expected highlight Span and Strong inline: %s", out)
+	}
+	if doc.Blocks[3].T != "BulletList" {
+		t.Errorf("blocks[3] = %s, want BulletList", doc.Blocks[3].T)
+	}
+	items, _ := doc.Blocks[3].C.([]interface{})
+	if len(items) != 2 {
+		t.Errorf("expected nested item to fold into first bullet, got %d items", len(items))
+	}
+}
+
+func TestParsePandocInlinesSnakeCase(t *testing.T) {
+	data, _ := json.Marshal(parsePandocInlines("use my_var_name here"))
+	want := `[{"t":"Str","c":"use"},{"t":"Space"},{"t":"Str","c":"my_var_name"},{"t":"Space"},{"t":"Str","c":"here"}]`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestParsePandocInlinesBoundaries(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"$5 and $10", `[{"t":"Str","c":"$5"},{"t":"Space"},{"t":"Str","c":"and"},{"t":"Space"},{"t":"Str","c":"$10"}]`},
+		{"$x$2", `[{"t":"Str","c":"$x$2"}]`},
+		{"a * b * c", `[{"t":"Str","c":"a"},{"t":"Space"},{"t":"Str","c":"*"},{"t":"Space"},{"t":"Str","c":"b"},{"t":"Space"},{"t":"Str","c":"*"},{"t":"Space"},{"t":"Str","c":"c"}]`},
+		{"2*3*4", `[{"t":"Str","c":"2*3*4"}]`},
+		{"an *em* word", `[{"t":"Str","c":"an"},{"t":"Space"},{"t":"Emph","c":[{"t":"Str","c":"em"}]},{"t":"Space"},{"t":"Str","c":"word"}]`},
+		{"so $x^2$.", `[{"t":"Str","c":"so"},{"t":"Space"},{"t":"Math","c":[{"t":"InlineMath"},"x^2"]},{"t":"Str","c":"."}]`},
+		{"$$E=mc^2$$", `[{"t":"Math","c":[{"t":"DisplayMath"},"E=mc^2"]}]`},
+	}
+	for _, tt := range tests {
+		data, _ := json.Marshal(parsePandocInlines(tt.in))
+		if string(data) != tt.want {
+			t.Errorf("%q:\n got %s\nwant %s", tt.in, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/ashrafali/craft-cli/internal/jsonschema"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// commandOutput maps each JSON-producing format of a command to the Go
+// values it prints. Several values for one format become anyOf.
+type commandOutput map[string][]interface{}
+
+// mutationOutput is shared by every mutating command: JSON formats print the
+// mutation envelope, including for --dry-run.
+var mutationOutput = commandOutput{
+	FormatJSON:    {mutationResult{}},
+	FormatCompact: {mutationResult{}},
+}
+
+// commandOutputs lists the output types of each command, keyed by command
+// path without the "craft" prefix.
+var commandOutputs = map[string]commandOutput{
+	"list": {FormatJSON: {models.DocumentList{}}, FormatCompact: {[]models.Document{}}},
+	"docs": {FormatJSON: {models.DocumentList{}}, FormatCompact: {[]models.Document{}}},
+	"get": {
+		FormatJSON:       {models.Document{}},
+		FormatCompact:    {models.Document{}},
+		FormatStructured: {models.BlocksResponse{}},
+		FormatPandocJSON: {pandocDocument{}},
+	},
+	"search": {
+		FormatJSON:    {models.SearchResult{}, models.BlockSearchResultList{}},
+		FormatCompact: {[]models.SearchItem{}, []models.BlockSearchResult{}},
+	},
+	"connection":    {FormatJSON: {models.ConnectionInfo{}}, FormatCompact: {models.ConnectionInfo{}}},
+	"limits":        {FormatJSON: {limitsInfo{}}},
+	"llm":           {FormatJSON: {llmSpec{}}},
+	"schema":        {FormatJSON: {CommandSchema{}}},
+	"openapi":       {FormatJSON: {api.OpenAPIDocument{}}},
+	"plan":          {FormatJSON: {savedPlan{}}},
+	"batch":         {FormatJSON: {batchResult{}}},
+	"context":       {FormatJSON: {contextPack{}}, FormatCompact: {contextPack{}}},
+	"audit list":    {FormatJSON: {[]auditEntry{}}, FormatCompact: {[]auditEntry{}}},
+	"alias list":    {FormatJSON: {[]userCommand{}}, FormatCompact: {[]userCommand{}}},
+	"config export": {FormatJSON: {config.Bundle{}}},
+	"bookmark list": {FormatJSON: {[]bookmark{}}},
+
+	"blocks get": {
+		FormatJSON:       {models.Block{}},
+		FormatCompact:    {models.Block{}},
+		FormatStructured: {models.Block{}},
+	},
+	"folders list":       {FormatJSON: {models.FolderList{}}, FormatCompact: {[]models.Folder{}}},
+	"tasks list":         {FormatJSON: {models.TaskList{}}, FormatCompact: {[]models.Task{}}},
+	"collections list":   {FormatJSON: {models.CollectionList{}}, FormatCompact: {[]models.Collection{}}},
+	"collections schema": {FormatJSON: {models.CollectionSchema{}}},
+	"collections items":  {FormatJSON: {models.CollectionItemList{}}, FormatCompact: {[]models.CollectionItem{}}},
+	"whiteboards get":    {FormatJSON: {map[string]interface{}{}}, FormatCompact: {map[string]interface{}{}}},
+
+	"create":             mutationOutput,
+	"update":             mutationOutput,
+	"delete":             mutationOutput,
+	"clear":              mutationOutput,
+	"move":               mutationOutput,
+	"upload":             mutationOutput,
+	"apply":              mutationOutput,
+	"blocks add":         mutationOutput,
+	"blocks update":      mutationOutput,
+	"blocks delete":      mutationOutput,
+	"blocks move":        mutationOutput,
+	"collections add":    mutationOutput,
+	"collections update": mutationOutput,
+	"collections delete": mutationOutput,
+	"comments add":       mutationOutput,
+	"folders create":     mutationOutput,
+	"folders move":       mutationOutput,
+	"folders delete":     mutationOutput,
+	"tasks add":          mutationOutput,
+	"tasks update":       mutationOutput,
+	"tasks delete":       mutationOutput,
+	"whiteboards create": mutationOutput,
+	"whiteboards add":    mutationOutput,
+	"whiteboards update": mutationOutput,
+	"whiteboards delete": mutationOutput,
+}
+
+// ndjsonOutputs are commands that print one JSON value per line; their
+// schema describes a single line.
+var ndjsonOutputs = map[string]bool{"batch": true}
+
+// commandKey returns the commandOutputs key for cmd.
+func commandKey(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if i := strings.IndexByte(path, ' '); i >= 0 {
+		return path[i+1:]
+	}
+	return ""
+}
+
+// outputFormats returns the formats with an output schema for cmd, sorted.
+func outputFormats(cmd *cobra.Command) []string {
+	var formats []string
+	for f := range commandOutputs[commandKey(cmd)] {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// buildOutputSchema generates the JSON Schema of what cmd prints in format.
+func buildOutputSchema(cmd *cobra.Command, format string) (*jsonschema.Schema, error) {
+	key := commandKey(cmd)
+	outputs, ok := commandOutputs[key]
+	if !ok {
+		return nil, fmt.Errorf("craft %s does not print JSON output", key)
+	}
+	values, ok := outputs[format]
+	if !ok {
+		return nil, fmt.Errorf("craft %s has no JSON output for --format %s (available: %s)",
+			key, format, strings.Join(outputFormats(cmd), ", "))
+	}
+
+	r := jsonschema.NewReflector("#/$defs/")
+	var schema *jsonschema.Schema
+	if len(values) == 1 {
+		schema = r.Reflect(values[0])
+	} else {
+		schema = &jsonschema.Schema{}
+		for _, v := range values {
+			schema.AnyOf = append(schema.AnyOf, r.Reflect(v))
+		}
+	}
+
+	schema.Dialect = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = fmt.Sprintf("craft %s --format %s", key, format)
+	schema.Defs = r.Defs
+	if ndjsonOutputs[key] {
+		schema.Description = "Each line of output is one JSON value matching this schema."
+	}
+	return schema, nil
+}
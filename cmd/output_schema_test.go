@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/jsonschema"
+)
+
+// outputSchemaServer returns realistic payloads for the read endpoints.
+func outputSchemaServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"d1","title":"Notes","createdAt":"2025-01-02T03:04:05Z","hasChildren":false}],"total":1}`))
+		case r.URL.Path == "/documents/search":
+			w.Write([]byte(`{"items":[{"documentId":"d1","markdown":"hit"}],"total":1}`))
+		case r.URL.Path == "/blocks/search":
+			w.Write([]byte(`{"items":[{"blockId":"b1","markdown":"hit","pageBlockPath":[{"id":"d1","content":"Notes"}]}]}`))
+		case r.URL.Path == "/blocks":
+			w.Write([]byte(`{"id":"d1","type":"page","markdown":"Notes","content":[
+				{"id":"b1","type":"text","textStyle":"h1","markdown":"# Title"},
+				{"id":"b2","type":"text","listStyle":"task","markdown":"- [ ] todo","taskInfo":{"state":"todo"}}]}`))
+		case r.URL.Path == "/tasks":
+			w.Write([]byte(`{"items":[{"id":"t1","blockId":"b2","documentId":"d1","markdown":"todo","state":"todo"}],"total":1}`))
+		case r.URL.Path == "/folders":
+			w.Write([]byte(`{"items":[{"id":"f1","name":"Work","documentCount":2}],"total":1}`))
+		case r.URL.Path == "/collections/c1/items":
+			w.Write([]byte(`{"items":[{"id":"i1","title":"Row","properties":{"status":"Done","points":3}}]}`))
+		case r.URL.Path == "/connection":
+			w.Write([]byte(`{"space":{"id":"s1","timezone":"UTC"},"utc":{"time":"2025-01-02T03:04:05Z"},"urlTemplates":{"app":"craftdocs://x"}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+}
+
+func TestCommandOutputMatchesSchema(t *testing.T) {
+	server := outputSchemaServer()
+	defer server.Close()
+
+	tests := []struct {
+		command string
+		args    []string
+		format  string
+	}{
+		{"list", []string{"list"}, FormatJSON},
+		{"list", []string{"list"}, FormatCompact},
+		{"get", []string{"get", "d1"}, FormatJSON},
+		{"get", []string{"get", "d1"}, FormatStructured},
+		{"get", []string{"get", "d1"}, FormatPandocJSON},
+		{"search", []string{"search", "hit"}, FormatJSON},
+		{"search", []string{"search", "hit", "--document", "d1"}, FormatJSON},
+		{"search", []string{"search", "hit", "--document", "d1"}, FormatCompact},
+		{"blocks get", []string{"blocks", "get", "b1"}, FormatJSON},
+		{"tasks list", []string{"tasks", "list"}, FormatJSON},
+		{"folders list", []string{"folders", "list"}, FormatCompact},
+		{"collections items", []string{"collections", "items", "c1"}, FormatJSON},
+		{"connection", []string{"connection"}, FormatJSON},
+		{"limits", []string{"limits"}, FormatJSON},
+		{"tasks delete", []string{"tasks", "delete", "t1", "--dry-run"}, FormatJSON},
+		{"blocks delete", []string{"blocks", "delete", "b1"}, FormatJSON},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, "_")+"_"+tt.format, func(t *testing.T) {
+			args := append([]string{"--api-url", server.URL, "--format", tt.format}, tt.args...)
+			out, err := runInProcess(args, "")
+			if err != nil {
+				t.Fatalf("craft %s: %v", strings.Join(tt.args, " "), err)
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(out), &value); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+
+			target, _, _ := rootCmd.Find(strings.Fields(tt.command))
+			schema, err := buildOutputSchema(target, tt.format)
+			if err != nil {
+				t.Fatalf("buildOutputSchema() error = %v", err)
+			}
+			if err := jsonschema.Validate(schema, schema.Defs, value); err != nil {
+				t.Errorf("output does not match schema: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestOutputSchemaCoversRegisteredCommands(t *testing.T) {
+	for key := range commandOutputs {
+		target, _, err := rootCmd.Find(strings.Fields(key))
+		if err != nil || commandKey(target) != key {
+			t.Errorf("commandOutputs has %q but no such command", key)
+		}
+	}
+}
+
+func TestSchemaOutputFlag(t *testing.T) {
+	out, err := runInProcess([]string{"schema", "--command", "tasks list", "--output", "--format", "compact"}, "")
+	if err != nil {
+		t.Fatalf("schema --output error = %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &schema); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if schema["title"] != "craft tasks list --format compact" || schema["$defs"] == nil {
+		t.Errorf("unexpected schema: %s", out)
+	}
+
+	if _, err := runInProcess([]string{"schema", "--command", "version", "--output"}, ""); err == nil {
+		t.Error("expected error for a command without JSON output")
+	}
+}
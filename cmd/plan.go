@@ -0,0 +1,800 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// planVersion is the format version written to saved plans.
+const planVersion = 1
+
+// planRefPrefix marks a parameter that refers to the document created by an
+// earlier step (e.g. "$step:1"). It is replaced with the real ID during apply.
+const planRefPrefix = "$step:"
+
+// maxDiffLines caps how many content lines a step shows in its diff.
+const maxDiffLines = 20
+
+var (
+	planFile      string
+	planOut       string
+	applyContinue bool
+	applyForce    bool
+)
+
+// planOperation is one intended change. The same struct holds the fields as
+// written in the changes file and, in a saved plan, the resolved IDs.
+type planOperation struct {
+	Op         string                 `yaml:"op" json:"op"`
+	Title      string                 `yaml:"title,omitempty" json:"title,omitempty"`
+	Folder     string                 `yaml:"folder,omitempty" json:"folder,omitempty"`
+	Document   string                 `yaml:"document,omitempty" json:"document,omitempty"`
+	Markdown   string                 `yaml:"markdown,omitempty" json:"markdown,omitempty"`
+	Block      string                 `yaml:"block,omitempty" json:"block,omitempty"`
+	To         string                 `yaml:"to,omitempty" json:"to,omitempty"`
+	Position   string                 `yaml:"position,omitempty" json:"position,omitempty"`
+	Task       string                 `yaml:"task,omitempty" json:"task,omitempty"`
+	State      string                 `yaml:"state,omitempty" json:"state,omitempty"`
+	Schedule   string                 `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Deadline   string                 `yaml:"deadline,omitempty" json:"deadline,omitempty"`
+	Collection string                 `yaml:"collection,omitempty" json:"collection,omitempty"`
+	Item       string                 `yaml:"item,omitempty" json:"item,omitempty"`
+	Properties map[string]interface{} `yaml:"properties,omitempty" json:"properties,omitempty"`
+}
+
+// changesFile is the input to `craft plan`.
+type changesFile struct {
+	Operations []planOperation `yaml:"operations" json:"operations"`
+}
+
+// savedPlan is the reviewed, resolved plan executed by `craft apply`.
+type savedPlan struct {
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	Source    string     `json:"source"`
+	Steps     []planStep `json:"steps"`
+}
+
+// planStep is one resolved operation with the state it was planned against.
+type planStep struct {
+	Index   int           `json:"index"`
+	Summary string        `json:"summary"`
+	Params  planOperation `json:"params"`
+	Diff    []string      `json:"diff"`
+	// Target fingerprints the state of what the step changes, so apply can
+	// tell when it changed after the plan was made.
+	Target string `json:"target,omitempty"`
+}
+
+// planStepResult records the outcome of applying one step.
+type planStepResult struct {
+	Index     int    `json:"index"`
+	Op        string `json:"op"`
+	Status    string `json:"status"` // ok, failed, skipped
+	CreatedID string `json:"created_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// planOpSpec describes how to plan and apply one kind of operation.
+type planOpSpec struct {
+	// prepare validates and resolves op in place and returns the step summary and diff
+	prepare func(pc *planContext, op *planOperation) (string, []string, error)
+	// apply executes a resolved op, returning the ID of a created object if any
+	apply func(client *api.Client, op *planOperation) (string, error)
+	// target returns the current state of what a resolved op changes, or
+	// is nil when the op only adds something new
+	target func(client *api.Client, op *planOperation) (string, error)
+}
+
+// planOps is the registry of operations supported in changes files.
+var planOps = map[string]planOpSpec{
+	"documents.create": {prepare: prepareDocumentCreate, apply: applyDocumentCreate},
+	"sections.append":  {prepare: prepareSectionAppend, apply: applySectionAppend, target: sectionAppendTarget},
+	"blocks.move":      {prepare: prepareBlockMove, apply: applyBlockMove, target: blockMoveTarget},
+	"tasks.update":     {prepare: prepareTaskUpdate, apply: applyTaskUpdate, target: taskUpdateTarget},
+	"collections.set":  {prepare: prepareCollectionSet, apply: applyCollectionSet, target: collectionSetTarget},
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Build a reviewable plan from a list of intended changes",
+	Long: `Read a list of intended operations (YAML or JSON), resolve document
+titles to IDs, fetch the current state, and save a plan that shows exactly
+what 'craft apply' will change. Nothing is modified.
+
+Operations:
+  documents.create   title, markdown, folder
+  sections.append    document, markdown
+  blocks.move        block, to, position (start|end)
+  tasks.update       task, state, schedule, deadline
+  collections.set    collection, item, properties
+
+Documents may be given by ID or exact title, including the title of a
+document created earlier in the same plan. The plan records the state of
+each document, block, task and item it changes.
+
+Example changes.yaml:
+  operations:
+    - op: documents.create
+      title: Weekly Review
+      markdown: "## Wins"
+    - op: sections.append
+      document: Weekly Review
+      markdown: "## Next week"
+    - op: tasks.update
+      task: 1A2B3C
+      state: done
+
+Examples:
+  craft plan -f changes.yaml                 # Save plan.json and show the diff
+  craft plan -f changes.yaml --out review.json
+  craft apply plan.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changes, err := readChangesFile(planFile)
+		if err != nil {
+			return err
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+
+		plan, err := buildPlan(&planContext{client: client}, changes)
+		if err != nil {
+			return err
+		}
+		plan.Source = planFile
+
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := config.WriteFileAtomic(planOut, append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+
+		if isJSONFormat(getOutputFormat()) {
+			return outputJSON(plan)
+		}
+		printPlan(plan)
+		printStatus("\nSaved plan to %s. Review it, then run: craft apply %s\n", planOut, planOut)
+		return nil
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan.json>",
+	Short: "Execute a saved plan",
+	Long: `Execute the steps of a plan produced by 'craft plan', in order.
+
+By default apply stops at the first failed step and reports the remaining
+steps as skipped. Use --continue-on-error to attempt every step; steps that
+depend on a document whose creation failed are still skipped. If any step
+fails or is skipped, apply exits non-zero after printing the results.
+
+Before running anything, apply checks that the documents, blocks, tasks
+and items the plan changes are as they were when it was made. If any
+changed, apply stops; re-run 'craft plan', or pass --force to apply the
+plan anyway.
+
+Examples:
+  craft apply plan.json
+  craft apply plan.json --continue-on-error
+  craft apply plan.json --dry-run           # Show the steps without running them`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := readSavedPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		if isDryRun() {
+			summaries := make([]string, len(plan.Steps))
+			for i, s := range plan.Steps {
+				summaries[i] = s.Summary
+			}
+			return dryRunOutput("plan.apply", []string{args[0]}, map[string]interface{}{
+				"steps": summaries,
+			})
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+
+		changed, err := changedPlanTargets(client, plan)
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 && !applyForce {
+			return fmt.Errorf("plan is out of date, re-run craft plan or pass --force:\n  %s", strings.Join(changed, "\n  "))
+		}
+
+		results := applyPlan(client, plan, applyContinue)
+
+		res := newMutation("plan.apply", args[0])
+		res.Warnings = append(res.Warnings, changed...)
+		notOK := 0
+		for _, r := range results {
+			if r.CreatedID != "" {
+				res.CreatedIDs = append(res.CreatedIDs, r.CreatedID)
+			}
+			if r.Status != "ok" {
+				res.OK = false
+				notOK++
+			}
+			if r.Status == "failed" {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("step %d (%s) failed: %s", r.Index, r.Op, r.Error))
+			}
+		}
+		res.Result = results
+
+		err = outputMutation(res, func() error {
+			for _, r := range results {
+				mark := "✓"
+				switch r.Status {
+				case "failed":
+					mark = "✗"
+				case "skipped":
+					mark = "-"
+				}
+				fmt.Printf("%s [%d] %s %s\n", mark, r.Index, plan.Steps[r.Index-1].Summary, r.Status)
+			}
+			return nil
+		})
+		if err == nil && notOK > 0 {
+			err = fmt.Errorf("%d of %d step(s) failed or were skipped", notOK, len(results))
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().StringVarP(&planFile, "file", "f", "", "Changes file (YAML or JSON)")
+	planCmd.Flags().StringVar(&planOut, "out", "plan.json", "Where to save the plan")
+	planCmd.MarkFlagRequired("file")
+
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().BoolVar(&applyContinue, "continue-on-error", false, "Keep going after a failed step")
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "Apply even if what the plan changes was changed since")
+}
+
+// readChangesFile parses a changes file. JSON is accepted as a subset of YAML.
+func readChangesFile(path string) (*changesFile, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes file: %w", err)
+	}
+
+	var changes changesFile
+	if err := yaml.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("invalid changes file: %w", err)
+	}
+	if len(changes.Operations) == 0 {
+		return nil, fmt.Errorf("changes file has no operations")
+	}
+	return &changes, nil
+}
+
+// readSavedPlan loads a plan written by `craft plan`.
+func readSavedPlan(path string) (*savedPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan savedPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	if plan.Version != planVersion {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", plan.Version, planVersion)
+	}
+	for i, s := range plan.Steps {
+		if _, ok := planOps[s.Params.Op]; !ok {
+			return nil, fmt.Errorf("step %d: unknown operation %q", i+1, s.Params.Op)
+		}
+		if s.Index != i+1 {
+			return nil, fmt.Errorf("step %d: out of order (index %d)", i+1, s.Index)
+		}
+	}
+	return &plan, nil
+}
+
+// buildPlan resolves every operation against the current state.
+func buildPlan(pc *planContext, changes *changesFile) (*savedPlan, error) {
+	plan := &savedPlan{Version: planVersion, CreatedAt: time.Now().UTC(), Steps: []planStep{}}
+	for i := range changes.Operations {
+		op := changes.Operations[i]
+		spec, ok := planOps[op.Op]
+		if !ok {
+			return nil, fmt.Errorf("operation %d: unknown op %q (supported: %s)", i+1, op.Op, strings.Join(planOpNames(), ", "))
+		}
+		pc.step = i + 1
+		summary, diff, err := spec.prepare(pc, &op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+		if diff == nil {
+			diff = []string{}
+		}
+		step := planStep{Index: i + 1, Summary: summary, Params: op, Diff: diff}
+		if step.Target, err = planTarget(pc.client, &op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+// planTarget fingerprints the current state of what op changes. It is ""
+// for ops that only add something, or act on a document the plan creates.
+func planTarget(client *api.Client, op *planOperation) (string, error) {
+	spec := planOps[op.Op]
+	if spec.target == nil || strings.HasPrefix(op.Document, planRefPrefix) || strings.HasPrefix(op.To, planRefPrefix) {
+		return "", nil
+	}
+	state, err := spec.target(client, op)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// changedPlanTargets describes the steps whose targets changed since the
+// plan was made. Steps saved without a target are not checked.
+func changedPlanTargets(client *api.Client, plan *savedPlan) ([]string, error) {
+	var changed []string
+	for _, step := range plan.Steps {
+		if step.Target == "" {
+			continue
+		}
+		op := step.Params
+		current, err := planTarget(client, &op)
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", step.Index, step.Summary, err)
+		}
+		if current != step.Target {
+			changed = append(changed, fmt.Sprintf("step %d (%s): changed since the plan was made", step.Index, step.Summary))
+		}
+	}
+	return changed, nil
+}
+
+// planOpNames returns the supported operation names, sorted.
+func planOpNames() []string {
+	names := make([]string, 0, len(planOps))
+	for name := range planOps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPlan runs the steps in order and reports a result for every step.
+func applyPlan(client *api.Client, plan *savedPlan, continueOnError bool) []planStepResult {
+	created := map[int]string{}
+	results := make([]planStepResult, 0, len(plan.Steps))
+	stopped := false
+
+	for _, step := range plan.Steps {
+		r := planStepResult{Index: step.Index, Op: step.Params.Op}
+		if stopped {
+			r.Status = "skipped"
+			results = append(results, r)
+			continue
+		}
+
+		printStatus("[%d/%d] %s\n", step.Index, len(plan.Steps), step.Summary)
+		op := step.Params
+		err := substitutePlanRefs(&op, created)
+		if err == nil {
+			r.CreatedID, err = planOps[op.Op].apply(client, &op)
+		}
+
+		var depErr *planDependencyError
+		switch {
+		case errors.As(err, &depErr):
+			r.Status = "skipped"
+			r.Error = err.Error()
+		case err != nil:
+			r.Status = "failed"
+			r.Error = err.Error()
+			stopped = !continueOnError
+		default:
+			r.Status = "ok"
+			if r.CreatedID != "" {
+				created[step.Index] = r.CreatedID
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// planDependencyError reports a step whose referenced document was never created.
+type planDependencyError struct {
+	step int
+}
+
+func (e *planDependencyError) Error() string {
+	return fmt.Sprintf("depends on step %d, which did not create a document", e.step)
+}
+
+// substitutePlanRefs replaces $step:N references with IDs created by earlier steps.
+func substitutePlanRefs(op *planOperation, created map[int]string) error {
+	for _, field := range []*string{&op.Document, &op.To} {
+		if !strings.HasPrefix(*field, planRefPrefix) {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(*field, planRefPrefix), "%d", &n); err != nil {
+			return fmt.Errorf("invalid step reference %q", *field)
+		}
+		id, ok := created[n]
+		if !ok {
+			return &planDependencyError{step: n}
+		}
+		*field = id
+	}
+	return nil
+}
+
+// printPlan shows each step and its diff.
+func printPlan(plan *savedPlan) {
+	fmt.Printf("Plan: %d step(s)", len(plan.Steps))
+	if plan.Source != "" {
+		fmt.Printf(" from %s", plan.Source)
+	}
+	fmt.Println()
+	for _, s := range plan.Steps {
+		fmt.Printf("\n[%d] %s\n", s.Index, s.Summary)
+		for _, line := range s.Diff {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+// planContext caches lookups made while building a plan.
+type planContext struct {
+	client  *api.Client
+	step    int
+	docs    map[string][]string // title -> IDs
+	docIDs  map[string]string   // ID -> title
+	created map[string]int      // title -> step creating it
+}
+
+// loadDocuments fetches the document list once.
+func (pc *planContext) loadDocuments() error {
+	if pc.docs != nil {
+		return nil
+	}
+	list, err := pc.client.GetDocuments()
+	if err != nil {
+		return err
+	}
+	pc.docs = map[string][]string{}
+	pc.docIDs = map[string]string{}
+	for _, d := range list.Items {
+		pc.docs[d.Title] = append(pc.docs[d.Title], d.ID)
+		pc.docIDs[d.ID] = d.Title
+	}
+	return nil
+}
+
+// resolveDocument turns a document ID, exact title, @bookmark or title:
+// reference into an ID, or a $step:N reference when the title is created
+// earlier in the plan.
+// The second return value is the document's title for summaries.
+func (pc *planContext) resolveDocument(ref string) (string, string, error) {
+	if ref == "" {
+		return "", "", fmt.Errorf("document is required")
+	}
+	if step, ok := pc.created[ref]; ok {
+		return fmt.Sprintf("%s%d", planRefPrefix, step), ref, nil
+	}
+	if isIDRef(ref) {
+		id, err := resolveIDRef(ref, idDocument)
+		if err != nil {
+			return "", "", err
+		}
+		ref = id
+	}
+	if err := pc.loadDocuments(); err != nil {
+		return "", "", err
+	}
+	if title, ok := pc.docIDs[ref]; ok {
+		return ref, title, nil
+	}
+	ids := pc.docs[ref]
+	switch len(ids) {
+	case 0:
+		return "", "", fmt.Errorf("no document with ID or title %q", ref)
+	case 1:
+		return ids[0], ref, nil
+	default:
+		return "", "", fmt.Errorf("title %q matches %d documents (%s); use an ID", ref, len(ids), strings.Join(ids, ", "))
+	}
+}
+
+// markdownDiff renders markdown lines with a prefix, capped at maxDiffLines.
+func markdownDiff(prefix, markdown string) []string {
+	var lines []string
+	all := strings.Split(strings.TrimRight(markdown, "\n"), "\n")
+	for i, l := range all {
+		if i == maxDiffLines {
+			lines = append(lines, fmt.Sprintf("%s… (%d more lines)", prefix, len(all)-maxDiffLines))
+			break
+		}
+		lines = append(lines, prefix+l)
+	}
+	return lines
+}
+
+func prepareDocumentCreate(pc *planContext, op *planOperation) (string, []string, error) {
+	if op.Title == "" {
+		return "", nil, fmt.Errorf("title is required")
+	}
+	if pc.created == nil {
+		pc.created = map[string]int{}
+	}
+	pc.created[op.Title] = pc.step
+	folder, err := resolveIDRef(op.Folder, idFolder)
+	if err != nil {
+		return "", nil, err
+	}
+	op.Folder = folder
+
+	diff := []string{"+ document: " + op.Title}
+	if op.Folder != "" {
+		diff = append(diff, "+ folder: "+op.Folder)
+	}
+	if op.Markdown != "" {
+		diff = append(diff, markdownDiff("+ ", op.Markdown)...)
+	}
+	return fmt.Sprintf("create document %q", op.Title), diff, nil
+}
+
+func applyDocumentCreate(client *api.Client, op *planOperation) (string, error) {
+	doc, err := client.InFolder(op.Folder).CreateDocument(&models.CreateDocumentRequest{Title: op.Title, Markdown: op.Markdown})
+	if err != nil {
+		return "", err
+	}
+	return doc.ID, moveIntoFolder(client, doc.ID, op.Folder)
+}
+
+func prepareSectionAppend(pc *planContext, op *planOperation) (string, []string, error) {
+	if strings.TrimSpace(op.Markdown) == "" {
+		return "", nil, fmt.Errorf("markdown is required")
+	}
+	id, title, err := pc.resolveDocument(op.Document)
+	if err != nil {
+		return "", nil, err
+	}
+	op.Document = id
+
+	var diff []string
+	if !strings.HasPrefix(id, planRefPrefix) {
+		current, err := pc.client.GetDocumentContentMarkdown(id)
+		if err != nil {
+			return "", nil, err
+		}
+		existing := strings.Split(strings.TrimRight(current, "\n"), "\n")
+		if len(existing) > 3 {
+			diff = append(diff, fmt.Sprintf("  … (%d lines)", len(existing)-3))
+			existing = existing[len(existing)-3:]
+		}
+		for _, l := range existing {
+			if l != "" {
+				diff = append(diff, "  "+l)
+			}
+		}
+	}
+	diff = append(diff, markdownDiff("+ ", op.Markdown)...)
+	return fmt.Sprintf("append to %q", title), diff, nil
+}
+
+func sectionAppendTarget(client *api.Client, op *planOperation) (string, error) {
+	return client.GetDocumentContentMarkdown(op.Document)
+}
+
+func applySectionAppend(client *api.Client, op *planOperation) (string, error) {
+	_, err := client.AppendMarkdown(op.Document, op.Markdown, 0)
+	return "", err
+}
+
+func prepareBlockMove(pc *planContext, op *planOperation) (string, []string, error) {
+	if op.Block == "" {
+		return "", nil, fmt.Errorf("block is required")
+	}
+	if err := resolveBlockRefs(&op.Block); err != nil {
+		return "", nil, err
+	}
+	if op.Position == "" {
+		op.Position = "end"
+	}
+	if op.Position != "start" && op.Position != "end" {
+		return "", nil, fmt.Errorf("position must be start or end")
+	}
+	id, title, err := pc.resolveDocument(op.To)
+	if err != nil {
+		return "", nil, err
+	}
+	op.To = id
+
+	block, err := pc.client.GetBlock(op.Block)
+	if err != nil {
+		return "", nil, err
+	}
+	diff := []string{
+		"~ block: " + oneLine(block.Markdown),
+		fmt.Sprintf("+ location: %s (%s)", title, op.Position),
+	}
+	return fmt.Sprintf("move block %s to %q", op.Block, title), diff, nil
+}
+
+func blockMoveTarget(client *api.Client, op *planOperation) (string, error) {
+	block, err := client.GetBlock(op.Block)
+	if err != nil {
+		return "", err
+	}
+	return block.Markdown, nil
+}
+
+func applyBlockMove(client *api.Client, op *planOperation) (string, error) {
+	return "", client.MoveBlock(op.Block, op.To, op.Position)
+}
+
+func prepareTaskUpdate(pc *planContext, op *planOperation) (string, []string, error) {
+	if op.Task == "" {
+		return "", nil, fmt.Errorf("task is required")
+	}
+	if err := resolveBlockRefs(&op.Task); err != nil {
+		return "", nil, err
+	}
+	if op.State == "" && op.Schedule == "" && op.Deadline == "" {
+		return "", nil, fmt.Errorf("at least one of state, schedule, deadline is required")
+	}
+	if op.State != "" && op.State != "todo" && op.State != "done" && op.State != "canceled" {
+		return "", nil, fmt.Errorf("state must be todo, done, or canceled")
+	}
+
+	block, err := pc.client.GetBlock(op.Task)
+	if err != nil {
+		return "", nil, err
+	}
+	var state, schedule, deadline string
+	if block.TaskInfo != nil {
+		state, schedule, deadline = block.TaskInfo.State, block.TaskInfo.ScheduleDate, block.TaskInfo.DeadlineDate
+	}
+
+	diff := []string{"  task: " + oneLine(stripCraftMarkdown(block))}
+	diff = append(diff, fieldDiff("state", state, op.State)...)
+	diff = append(diff, fieldDiff("schedule", schedule, op.Schedule)...)
+	diff = append(diff, fieldDiff("deadline", deadline, op.Deadline)...)
+	return fmt.Sprintf("update task %s", op.Task), diff, nil
+}
+
+func taskUpdateTarget(client *api.Client, op *planOperation) (string, error) {
+	block, err := client.GetBlock(op.Task)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal([]interface{}{block.Markdown, block.TaskInfo})
+	return string(data), err
+}
+
+func applyTaskUpdate(client *api.Client, op *planOperation) (string, error) {
+	return "", client.UpdateTask(op.Task, op.State, op.Schedule, op.Deadline)
+}
+
+func prepareCollectionSet(pc *planContext, op *planOperation) (string, []string, error) {
+	if op.Collection == "" || op.Item == "" {
+		return "", nil, fmt.Errorf("collection and item are required")
+	}
+	if len(op.Properties) == 0 {
+		return "", nil, fmt.Errorf("properties are required")
+	}
+	for _, field := range []*string{&op.Collection, &op.Item} {
+		if isIDRef(*field) {
+			id, err := resolveIDRef(*field, idPlain)
+			if err != nil {
+				return "", nil, err
+			}
+			*field = id
+		}
+	}
+
+	items, err := pc.client.GetCollectionItems(op.Collection, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	var matches []int
+	for i, it := range items.Items {
+		if it.ID == op.Item {
+			matches = []int{i}
+			break
+		}
+		if it.Title == op.Item {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil, fmt.Errorf("no item with ID or title %q in collection %s", op.Item, op.Collection)
+	case 1:
+	default:
+		return "", nil, fmt.Errorf("title %q matches %d items; use an ID", op.Item, len(matches))
+	}
+	item := items.Items[matches[0]]
+	op.Item = item.ID
+
+	keys := make([]string, 0, len(op.Properties))
+	for k := range op.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	diff := []string{"  item: " + item.Title}
+	for _, k := range keys {
+		before := ""
+		if v, ok := item.Properties[k]; ok && v != nil {
+			before = fmt.Sprint(v)
+		}
+		diff = append(diff, fieldDiff(k, before, fmt.Sprint(op.Properties[k]))...)
+	}
+	return fmt.Sprintf("set properties on %q", item.Title), diff, nil
+}
+
+func collectionSetTarget(client *api.Client, op *planOperation) (string, error) {
+	items, err := client.GetCollectionItems(op.Collection, 0)
+	if err != nil {
+		return "", err
+	}
+	for _, it := range items.Items {
+		if it.ID == op.Item {
+			data, err := json.Marshal([]interface{}{it.Title, it.Properties})
+			return string(data), err
+		}
+	}
+	return "", fmt.Errorf("item %s is no longer in collection %s", op.Item, op.Collection)
+}
+
+func applyCollectionSet(client *api.Client, op *planOperation) (string, error) {
+	return "", client.UpdateCollectionItem(op.Collection, op.Item, op.Properties, false)
+}
+
+// fieldDiff renders a field change, or nothing when the value is unchanged or not set.
+func fieldDiff(name, before, after string) []string {
+	if after == "" || after == before {
+		return nil
+	}
+	var lines []string
+	if before != "" {
+		lines = append(lines, fmt.Sprintf("- %s: %s", name, before))
+	}
+	return append(lines, fmt.Sprintf("+ %s: %s", name, after))
+}
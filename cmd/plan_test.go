@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+)
+
+// planTestServer serves one document "Notes" (d1) and records writes.
+func planTestServer(t *testing.T, writes *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodGet {
+			*writes = append(*writes, r.Method+" "+r.URL.Path+" "+string(body))
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"d1","title":"Notes"}],"total":1}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/blocks":
+			w.Write([]byte(`{"id":"d1","type":"page","markdown":"Notes","content":[{"id":"b1","type":"text","markdown":"existing line"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/documents":
+			w.Write([]byte(`{"items":[{"id":"new1","title":"Weekly"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/blocks":
+			if strings.Contains(string(body), "fail-me") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"bad block"}`))
+				return
+			}
+			w.Write([]byte(`{"items":[{"id":"b2","type":"text","markdown":"x"}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+}
+
+func TestReadChangesFileYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.yaml")
+	os.WriteFile(path, []byte(`operations:
+  - op: collections.set
+    collection: c1
+    item: Row
+    properties:
+      status: Done
+      points: 3
+`), 0644)
+
+	changes, err := readChangesFile(path)
+	if err != nil {
+		t.Fatalf("readChangesFile() error = %v", err)
+	}
+	op := changes.Operations[0]
+	if op.Op != "collections.set" || op.Properties["status"] != "Done" || op.Properties["points"] != 3 {
+		t.Errorf("parsed op = %+v", op)
+	}
+}
+
+func TestBuildPlanResolvesTitles(t *testing.T) {
+	var writes []string
+	server := planTestServer(t, &writes)
+	defer server.Close()
+
+	changes := &changesFile{Operations: []planOperation{
+		{Op: "documents.create", Title: "Weekly", Markdown: "## Wins"},
+		{Op: "sections.append", Document: "Weekly", Markdown: "## Next"},
+		{Op: "sections.append", Document: "Notes", Markdown: "more"},
+	}}
+	plan, err := buildPlan(&planContext{client: api.NewClient(server.URL)}, changes)
+	if err != nil {
+		t.Fatalf("buildPlan() error = %v", err)
+	}
+
+	if got := plan.Steps[1].Params.Document; got != "$step:1" {
+		t.Errorf("reference to planned document = %q", got)
+	}
+	if got := plan.Steps[2].Params.Document; got != "d1" {
+		t.Errorf("title resolved to %q, want d1", got)
+	}
+	diff := strings.Join(plan.Steps[2].Diff, "\n")
+	if !strings.Contains(diff, "  existing line") || !strings.Contains(diff, "+ more") {
+		t.Errorf("diff = %q", diff)
+	}
+	if len(writes) != 0 {
+		t.Errorf("plan should not modify anything, got %v", writes)
+	}
+
+	_, err = buildPlan(&planContext{client: api.NewClient(server.URL)}, &changesFile{Operations: []planOperation{
+		{Op: "sections.append", Document: "Missing", Markdown: "x"},
+	}})
+	if err == nil {
+		t.Error("expected error for unknown document title")
+	}
+}
+
+func TestApplyPlanStopsOnError(t *testing.T) {
+	var writes []string
+	server := planTestServer(t, &writes)
+	defer server.Close()
+	quietMode = true
+	defer func() { quietMode = false }()
+
+	plan := &savedPlan{Version: planVersion, Steps: []planStep{
+		{Index: 1, Params: planOperation{Op: "documents.create", Title: "Weekly"}},
+		{Index: 2, Params: planOperation{Op: "sections.append", Document: "$step:1", Markdown: "fail-me"}},
+		{Index: 3, Params: planOperation{Op: "tasks.update", Task: "t1", State: "done"}},
+	}}
+
+	results := applyPlan(api.NewClient(server.URL), plan, false)
+	statuses := []string{results[0].Status, results[1].Status, results[2].Status}
+	if strings.Join(statuses, ",") != "ok,failed,skipped" {
+		t.Errorf("statuses = %v", statuses)
+	}
+	if results[0].CreatedID != "new1" {
+		t.Errorf("created ID = %q", results[0].CreatedID)
+	}
+	if !strings.Contains(strings.Join(writes, "\n"), `"pageId":"new1"`) {
+		t.Errorf("step reference not substituted: %v", writes)
+	}
+
+	results = applyPlan(api.NewClient(server.URL), plan, true)
+	if results[2].Status != "ok" {
+		t.Errorf("--continue-on-error should run step 3, got %s", results[2].Status)
+	}
+}
+
+func TestApplyPlanCreatesInFolder(t *testing.T) {
+	var writes []string
+	server := planTestServer(t, &writes)
+	defer server.Close()
+	quietMode = true
+	defer func() { quietMode = false }()
+
+	plan := &savedPlan{Version: planVersion, Steps: []planStep{
+		{Index: 1, Params: planOperation{Op: "documents.create", Title: "Weekly", Folder: "f1"}},
+	}}
+	results := applyPlan(api.NewClient(server.URL), plan, false)
+	if results[0].Status != "ok" || len(writes) != 2 {
+		t.Fatalf("results = %+v, writes = %v", results, writes)
+	}
+	if strings.Contains(writes[0], "parentId") || !strings.HasPrefix(writes[1], "PUT /documents") || !strings.Contains(writes[1], `"folderId":"f1"`) {
+		t.Errorf("expected a create then a move into f1, got %v", writes)
+	}
+}
+
+func TestApplyCommandFailsOnFailedStep(t *testing.T) {
+	var writes []string
+	server := planTestServer(t, &writes)
+	defer server.Close()
+	plan := savedPlan{Version: planVersion, Steps: []planStep{
+		{Index: 1, Summary: "append", Params: planOperation{Op: "sections.append", Document: "d1", Markdown: "fail-me"}},
+	}}
+	data, _ := json.Marshal(plan)
+	path := filepath.Join(t.TempDir(), "plan.json")
+	os.WriteFile(path, data, 0644)
+
+	out, err := runInProcess([]string{"--api-url", server.URL, "apply", path}, "")
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 step(s) failed") {
+		t.Errorf("error = %v, want the failed step reported", err)
+	}
+	if !strings.Contains(out, `"ok": false`) {
+		t.Errorf("envelope not printed before failing:\n%s", out)
+	}
+}
+
+func TestSubstitutePlanRefsMissingStep(t *testing.T) {
+	op := planOperation{Op: "sections.append", Document: "$step:4"}
+	err := substitutePlanRefs(&op, map[int]string{})
+	var depErr *planDependencyError
+	if err == nil || !strings.Contains(err.Error(), "step 4") {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.As(err, &depErr) {
+		t.Errorf("expected planDependencyError, got %T", err)
+	}
+}
+
+func TestReadSavedPlanRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	data, _ := json.Marshal(savedPlan{Version: 99})
+	os.WriteFile(path, data, 0644)
+	if _, err := readSavedPlan(path); err == nil {
+		t.Error("expected version error")
+	}
+}
+
+func TestApplyRefusesStalePlan(t *testing.T) {
+	useTempConfig(t)
+	line, status := "existing line", "Open"
+	var writes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/documents":
+			w.Write([]byte(`{"items":[{"id":"d1","title":"Notes"}]}`))
+		case "/blocks":
+			w.Write([]byte(`{"id":"d1","type":"page","markdown":"Notes","content":[{"id":"b1","type":"text","markdown":"` + line + `"}]}`))
+		case "/collections/c1/items":
+			w.Write([]byte(`{"items":[{"id":"i1","title":"Row","properties":{"status":"` + status + `"}}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+	cfgManager.AddProfile("work", server.URL)
+	cfgManager.SetBookmark("work", "board", "c1")
+
+	changes := filepath.Join(t.TempDir(), "changes.yaml")
+	os.WriteFile(changes, []byte(`operations:
+  - op: sections.append
+    document: Notes
+    markdown: more
+  - op: collections.set
+    collection: "@board"
+    item: Row
+    properties: {status: Done}
+`), 0644)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	if out, err := runInProcess([]string{"plan", "-f", changes, "--out", planPath}, ""); err != nil {
+		t.Fatalf("plan: %v\n%s", err, out)
+	}
+	if info, err := os.Stat(planPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("plan file = %v, %v; want mode 0600", info, err)
+	}
+	plan, err := readSavedPlan(planPath)
+	if err != nil || plan.Steps[1].Params.Collection != "c1" || plan.Steps[0].Target == "" || plan.Steps[1].Target == "" {
+		t.Fatalf("plan = %+v, %v", plan, err)
+	}
+
+	line, status = "edited meanwhile", "Blocked"
+	_, err = runInProcess([]string{"apply", planPath}, "")
+	if err == nil || !strings.Contains(err.Error(), "step 1") || !strings.Contains(err.Error(), "step 2") || len(writes) != 0 {
+		t.Fatalf("apply of a stale plan = %v, writes %v", err, writes)
+	}
+	if out, err := runInProcess([]string{"apply", planPath, "--force"}, ""); err != nil || !strings.Contains(out, "changed since the plan was made") || len(writes) != 2 {
+		t.Errorf("apply --force = %q, %v, writes %v", out, err, writes)
+	}
+}
@@ -0,0 +1,693 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// policyEnvVar names a policy file applied on top of the active profile's.
+const policyEnvVar = "CRAFT_POLICY"
+
+var reasonFlag string
+
+// safetyPolicy restricts what craft may change, on top of the API key's own
+// read/write permission.
+type safetyPolicy struct {
+	// DenyDestructive blocks every delete, including the block deletes
+	// behind clear and update --mode replace.
+	DenyDestructive bool `yaml:"deny_destructive" json:"deny_destructive"`
+	// AllowedFolders limits writes to documents in these folders and their
+	// subfolders. Anything that cannot be tied to them is denied, including
+	// new documents not created for one of them (see api.Client.InFolder).
+	AllowedFolders []string `yaml:"allowed_folders" json:"allowed_folders,omitempty"`
+	// AlsoAllowedFolders are the allowed folders of further merged
+	// policies; a write must be allowed by every list.
+	AlsoAllowedFolders [][]string `yaml:"-" json:"also_allowed_folders,omitempty"`
+	// MaxDeletedBlocks caps the blocks deleted by one invocation (0 = no
+	// cap). A deleted document counts with all of its blocks.
+	MaxDeletedBlocks int `yaml:"max_deleted_blocks" json:"max_deleted_blocks,omitempty"`
+	// RequireConfirmation makes every change need --yes and --reason.
+	RequireConfirmation bool `yaml:"require_confirmation" json:"require_confirmation"`
+}
+
+// policyError is returned when the safety policy denies an action.
+type policyError struct {
+	msg string
+}
+
+func (e *policyError) Error() string {
+	return "denied by safety policy: " + e.msg
+}
+
+func policyDenied(format string, args ...interface{}) error {
+	return &policyError{msg: fmt.Sprintf(format, args...)}
+}
+
+func isPolicyError(err error) bool {
+	var pe *policyError
+	return errors.As(err, &pe)
+}
+
+// activePolicyPaths returns the policy files in effect: the active
+// profile's policy and $CRAFT_POLICY, each if set.
+func activePolicyPaths() ([]string, error) {
+	var paths []string
+	if cfgManager != nil {
+		path, err := cfgManager.GetActivePolicy()
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if path := os.Getenv(policyEnvVar); path != "" {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// loadSafetyPolicy reads the policies in effect merged into one, or
+// returns nil if there is none.
+func loadSafetyPolicy() (*safetyPolicy, error) {
+	paths, err := activePolicyPaths()
+	if err != nil {
+		return nil, err
+	}
+	var merged *safetyPolicy
+	for _, path := range paths {
+		policy, err := readPolicyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergePolicies(merged, policy)
+	}
+	return merged, nil
+}
+
+// mergePolicies combines two policies so the stricter rule wins: a policy
+// can only add restrictions to another, never lift them.
+func mergePolicies(a, b *safetyPolicy) *safetyPolicy {
+	if a == nil {
+		return b
+	}
+	out := *a
+	out.DenyDestructive = a.DenyDestructive || b.DenyDestructive
+	out.RequireConfirmation = a.RequireConfirmation || b.RequireConfirmation
+	if b.MaxDeletedBlocks > 0 && (out.MaxDeletedBlocks == 0 || b.MaxDeletedBlocks < out.MaxDeletedBlocks) {
+		out.MaxDeletedBlocks = b.MaxDeletedBlocks
+	}
+	out.AlsoAllowedFolders = append([][]string{}, a.AlsoAllowedFolders...)
+	for _, folders := range append([][]string{b.AllowedFolders}, b.AlsoAllowedFolders...) {
+		switch {
+		case len(folders) == 0:
+		case len(out.AllowedFolders) == 0:
+			out.AllowedFolders = folders
+		default:
+			out.AlsoAllowedFolders = append(out.AlsoAllowedFolders, folders)
+		}
+	}
+	return &out
+}
+
+// readPolicyFile parses a YAML or JSON policy file. Unknown keys are errors,
+// so a misspelled rule never silently disables a guardrail.
+func readPolicyFile(path string) (*safetyPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var policy safetyPolicy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	if policy.MaxDeletedBlocks < 0 {
+		return nil, fmt.Errorf("invalid policy file %s: max_deleted_blocks must not be negative", path)
+	}
+	return &policy, nil
+}
+
+// checkCommandPolicy rejects commands the policy denies outright, before
+// they run. Requests are checked again by the client guard.
+func checkCommandPolicy(cmd *cobra.Command) error {
+	if isDryRun() || !commandSafety(cmd).Destructive {
+		return nil
+	}
+	policy, err := loadSafetyPolicy()
+	if err != nil || policy == nil {
+		return err
+	}
+	if policy.DenyDestructive {
+		return policyDenied("destructive command %q is not allowed", cmd.CommandPath())
+	}
+	return nil
+}
+
+// installPolicyGuard makes client check every mutating request against the
+// policy in effect.
+func installPolicyGuard(client *api.Client) error {
+	policy, err := loadSafetyPolicy()
+	if err != nil {
+		return err
+	}
+	readOnly := activeDefault("read-only") == "true"
+	if policy == nil && !readOnly {
+		return nil
+	}
+	if policy == nil {
+		policy = &safetyPolicy{}
+	}
+	g := &policyGuard{policy: policy, client: client, readOnly: readOnly}
+	client.SetGuard(g.check)
+	client.AddObserver(g.observe)
+	return nil
+}
+
+// policyGuard enforces a policy for one invocation.
+type policyGuard struct {
+	policy *safetyPolicy
+	// readOnly is set by the profile's read-only default.
+	readOnly bool
+	client   *api.Client
+	spec     *api.OpenAPIDocument
+
+	// The folder scope is loaded once, on first use, without holding mu.
+	scopeOnce sync.Once
+	scope     *folderScope
+	scopeErr  error
+
+	mu sync.Mutex
+	// deleted counts blocks deleted by requests that succeeded; reserved
+	// holds the counts of delete requests still in flight, by request.
+	deleted   int
+	reserved  map[string][]int
+	docBlocks map[string]int
+}
+
+// check runs the checks that need no network first; mu is held only to
+// read and update the shared counts, not while folders or blocks load.
+func (g *policyGuard) check(m api.Mutation) error {
+	p := g.policy
+	if g.readOnly {
+		profile, _ := cfgManager.ActiveProfile()
+		return policyDenied("profile %q is read-only (craft config unset %s read-only)", profile, profile)
+	}
+	if p.RequireConfirmation && (!yesFlag || strings.TrimSpace(reasonFlag) == "") {
+		return policyDenied("changes require --yes and --reason")
+	}
+	if p.DenyDestructive && m.Method == http.MethodDelete {
+		return policyDenied("destructive request %s %s is not allowed", m.Method, m.Path)
+	}
+
+	g.mu.Lock()
+	if g.spec == nil {
+		g.spec = api.OpenAPISpec()
+	}
+	spec := g.spec
+	g.mu.Unlock()
+	template, _ := spec.MatchOperation(m.Method, m.Path)
+	targets, err := mutationTargetsOf(template, m)
+	if err != nil {
+		return err
+	}
+
+	if len(p.AllowedFolders) > 0 {
+		if err := g.checkFolders(targets); err != nil {
+			return err
+		}
+	}
+
+	if p.MaxDeletedBlocks > 0 {
+		n, err := g.deletedBlockCount(targets)
+		if err != nil || n == 0 {
+			return err
+		}
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		pending := g.deleted
+		for _, counts := range g.reserved {
+			for _, c := range counts {
+				pending += c
+			}
+		}
+		if pending+n > p.MaxDeletedBlocks {
+			return policyDenied("deleting %d more block(s) would exceed max_deleted_blocks (%d already deleted, limit %d)",
+				n, pending, p.MaxDeletedBlocks)
+		}
+		if g.reserved == nil {
+			g.reserved = map[string][]int{}
+		}
+		key := mutationKey(m)
+		g.reserved[key] = append(g.reserved[key], n)
+	}
+	return nil
+}
+
+// observe counts the blocks of a reserved delete once it has succeeded, and
+// releases the reservation either way. Documents created for an allowed
+// folder join the scope, so they can be moved there and written to.
+func (g *policyGuard) observe(o api.Outcome) {
+	if o.Err == nil && o.Method == http.MethodPost && o.Path == "/documents" && o.Folder != "" && len(g.policy.AllowedFolders) > 0 {
+		var created models.DocumentList
+		if scope, err := g.folderScope(); err == nil && json.Unmarshal(o.Response, &created) == nil {
+			scope.mu.Lock()
+			for _, d := range created.Items {
+				scope.docs[d.ID] = true
+			}
+			scope.mu.Unlock()
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	key := mutationKey(o.Mutation)
+	counts := g.reserved[key]
+	if len(counts) == 0 {
+		return
+	}
+	if o.Err == nil {
+		g.deleted += counts[0]
+	}
+	if len(counts) == 1 {
+		delete(g.reserved, key)
+	} else {
+		g.reserved[key] = counts[1:]
+	}
+}
+
+func mutationKey(m api.Mutation) string {
+	return m.Method + " " + m.Path + "?" + m.Query.Encode() + "\n" + string(m.Body)
+}
+
+// deletedBlockCount returns how many blocks t deletes, counting each
+// deleted document with its content.
+func (g *policyGuard) deletedBlockCount(t mutationTargets) (int, error) {
+	n := len(t.deletedBlocks)
+	for _, id := range t.deletedDocs {
+		g.mu.Lock()
+		count, ok := g.docBlocks[id]
+		g.mu.Unlock()
+		if !ok {
+			resp, err := g.client.GetDocumentBlocks(id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to count blocks for the safety policy: %w", err)
+			}
+			var walk func(b *models.Block)
+			walk = func(b *models.Block) {
+				count++
+				for i := range b.Content {
+					walk(&b.Content[i])
+				}
+			}
+			walk(blockFromResponse(&resp))
+			g.mu.Lock()
+			if g.docBlocks == nil {
+				g.docBlocks = map[string]int{}
+			}
+			g.docBlocks[id] = count
+			g.mu.Unlock()
+		}
+		n += count
+	}
+	return n, nil
+}
+
+// mutationTargets is what a mutating request touches.
+type mutationTargets struct {
+	folders       []string // folders written into or changed
+	blocks        []string // documents or blocks (documents are blocks)
+	tasks         []string
+	deletedBlocks []string
+	deletedDocs   []string
+	// unscoped explains why the request cannot be tied to a folder.
+	unscoped string
+}
+
+// mutationBody holds the ID-bearing fields of every mutating request body.
+type mutationBody struct {
+	Documents []struct {
+		ID       string `json:"id"`
+		FolderID string `json:"folderId"`
+		Location string `json:"location"`
+	} `json:"documents"`
+	DocumentIDs []string `json:"documentIds"`
+	Blocks      []struct {
+		ID       string `json:"id"`
+		Position struct {
+			PageID string `json:"pageId"`
+		} `json:"position"`
+	} `json:"blocks"`
+	BlockIDs []string `json:"blockIds"`
+	Position struct {
+		PageID    string `json:"pageId"`
+		SiblingID string `json:"siblingId"`
+		Date      string `json:"date"`
+	} `json:"position"`
+	Folders []struct {
+		ID       string `json:"id"`
+		ParentID string `json:"parentId"`
+	} `json:"folders"`
+	FolderIDs []string `json:"folderIds"`
+	Tasks     []struct {
+		ID         string `json:"id"`
+		DocumentID string `json:"documentId"`
+	} `json:"tasks"`
+	TaskIDs  []string `json:"taskIds"`
+	Comments []struct {
+		BlockID string `json:"blockId"`
+	} `json:"comments"`
+}
+
+// mutationTargetsOf extracts the targets of m, whose path matches the
+// OpenAPI path template.
+func mutationTargetsOf(template string, m api.Mutation) (mutationTargets, error) {
+	var t mutationTargets
+	var body mutationBody
+	if len(m.Body) > 0 && json.Valid(m.Body) {
+		if err := json.Unmarshal(m.Body, &body); err != nil {
+			return t, fmt.Errorf("failed to inspect request: %w", err)
+		}
+	}
+	segment := func(i int) string {
+		segs := strings.Split(strings.Trim(m.Path, "/"), "/")
+		if i < len(segs) {
+			return segs[i]
+		}
+		return ""
+	}
+
+	switch m.Method + " " + template {
+	case "POST /documents":
+		if m.Folder == "" {
+			t.unscoped = "new documents need --folder"
+		}
+		t.folders = append(t.folders, m.Folder)
+	case "PUT /documents":
+		for _, d := range body.Documents {
+			t.blocks = append(t.blocks, d.ID)
+			if d.FolderID == "" {
+				t.unscoped = "documents may only move between allowed folders"
+			}
+			t.folders = append(t.folders, d.FolderID)
+		}
+	case "DELETE /documents":
+		t.blocks = append(t.blocks, body.DocumentIDs...)
+		t.deletedDocs = body.DocumentIDs
+	case "POST /blocks", "POST /whiteboards":
+		if body.Position.Date != "" {
+			t.unscoped = "daily notes are not in a folder"
+		}
+		t.blocks = append(t.blocks, body.Position.PageID, body.Position.SiblingID)
+	case "PUT /blocks":
+		for _, b := range body.Blocks {
+			t.blocks = append(t.blocks, b.ID, b.Position.PageID)
+		}
+	case "DELETE /blocks":
+		t.blocks = append(t.blocks, body.BlockIDs...)
+		t.deletedBlocks = body.BlockIDs
+	case "POST /folders", "PUT /folders":
+		for _, f := range body.Folders {
+			if f.ParentID == "" {
+				t.unscoped = "top-level folders are outside the allowed folders"
+			}
+			t.folders = append(t.folders, f.ID, f.ParentID)
+		}
+	case "DELETE /folders":
+		t.folders = append(t.folders, body.FolderIDs...)
+	case "POST /tasks":
+		for _, task := range body.Tasks {
+			if task.DocumentID == "" {
+				t.unscoped = "inbox tasks are not in a folder"
+			}
+			t.blocks = append(t.blocks, task.DocumentID)
+		}
+	case "PUT /tasks":
+		for _, task := range body.Tasks {
+			t.tasks = append(t.tasks, task.ID)
+		}
+	case "DELETE /tasks":
+		t.tasks = append(t.tasks, body.TaskIDs...)
+	case "POST /comments":
+		for _, c := range body.Comments {
+			t.blocks = append(t.blocks, c.BlockID)
+		}
+	case "POST /upload":
+		if m.Query.Get("date") != "" {
+			t.unscoped = "daily notes are not in a folder"
+		}
+		t.blocks = append(t.blocks, m.Query.Get("pageId"), m.Query.Get("siblingId"))
+	case "POST /collections/{collectionId}/items", "PUT /collections/{collectionId}/items", "DELETE /collections/{collectionId}/items",
+		"POST /whiteboards/{whiteboardId}/elements", "PUT /whiteboards/{whiteboardId}/elements", "DELETE /whiteboards/{whiteboardId}/elements":
+		t.blocks = append(t.blocks, segment(1))
+	default:
+		t.unscoped = fmt.Sprintf("unrecognized request %s %s", m.Method, m.Path)
+	}
+	return t, nil
+}
+
+// checkFolders denies targets outside the allowed folders.
+func (g *policyGuard) checkFolders(t mutationTargets) error {
+	if t.unscoped != "" {
+		return policyDenied("writes are limited to allowed folders; %s", t.unscoped)
+	}
+	scope, err := g.folderScope()
+	if err != nil {
+		return err
+	}
+	for _, id := range t.folders {
+		if id != "" && !scope.hasFolder(id) {
+			return policyDenied("folder %s is outside the allowed folders", id)
+		}
+	}
+	for _, id := range t.blocks {
+		if id == "" {
+			continue
+		}
+		ok, err := scope.hasBlock(g.client, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return policyDenied("%s is not in a document in the allowed folders", id)
+		}
+	}
+	for _, id := range t.tasks {
+		ok, err := scope.hasTask(g.client, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return policyDenied("task %s is not in a document in the allowed folders", id)
+		}
+	}
+	return nil
+}
+
+// folderScope loads the policy's folder scope on first use.
+func (g *policyGuard) folderScope() (*folderScope, error) {
+	g.scopeOnce.Do(func() {
+		g.scope, g.scopeErr = loadFolderScope(g.client, append([][]string{g.policy.AllowedFolders}, g.policy.AlsoAllowedFolders...))
+	})
+	return g.scope, g.scopeErr
+}
+
+// folderScope is the set of folders, documents, blocks and tasks writes may
+// touch. Blocks and tasks are only fetched when a target is not a document.
+type folderScope struct {
+	mu      sync.Mutex
+	folders map[string]bool
+	docs    map[string]bool
+	blocks  map[string]bool
+	tasks   map[string]bool
+}
+
+// loadFolderScope returns the folders inside one of the allowed folders
+// of every list, with their documents.
+func loadFolderScope(client *api.Client, allowed [][]string) (*folderScope, error) {
+	s := &folderScope{folders: map[string]bool{}, docs: map[string]bool{}}
+	list, err := client.GetFolders()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load folders for the safety policy: %w", err)
+	}
+	for i, ids := range allowed {
+		subtree := map[string]bool{}
+		for _, id := range ids {
+			subtree[id] = true
+		}
+		// Add subfolders until no more are found.
+		for added := true; added; {
+			added = false
+			for _, f := range list.Items {
+				if !subtree[f.ID] && subtree[f.ParentID] {
+					subtree[f.ID] = true
+					added = true
+				}
+			}
+		}
+		for id := range subtree {
+			if i == 0 {
+				s.folders[id] = true
+			}
+		}
+		for id := range s.folders {
+			if !subtree[id] {
+				delete(s.folders, id)
+			}
+		}
+	}
+
+	for id := range s.folders {
+		docs, err := client.GetDocumentsFiltered(id, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load documents for the safety policy: %w", err)
+		}
+		for _, d := range docs.Items {
+			s.docs[d.ID] = true
+		}
+	}
+	return s, nil
+}
+
+func (s *folderScope) hasFolder(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.folders[id]
+}
+
+func (s *folderScope) hasBlock(client *api.Client, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.docs[id] {
+		return true, nil
+	}
+	if s.blocks == nil {
+		s.blocks = map[string]bool{}
+		for doc := range s.docs {
+			resp, err := client.GetDocumentBlocks(doc)
+			if err != nil {
+				return false, fmt.Errorf("failed to load blocks for the safety policy: %w", err)
+			}
+			var walk func(b *models.Block)
+			walk = func(b *models.Block) {
+				s.blocks[b.ID] = true
+				for i := range b.Content {
+					walk(&b.Content[i])
+				}
+			}
+			walk(blockFromResponse(&resp))
+		}
+	}
+	return s.blocks[id], nil
+}
+
+func (s *folderScope) hasTask(client *api.Client, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == nil {
+		s.tasks = map[string]bool{}
+		for doc := range s.docs {
+			list, err := client.GetDocumentTasks(doc)
+			if err != nil {
+				return false, fmt.Errorf("failed to load tasks for the safety policy: %w", err)
+			}
+			for _, task := range list.Items {
+				s.tasks[task.ID] = true
+			}
+		}
+	}
+	return s.tasks[id], nil
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show the safety policy in effect",
+	Long: `Show the safety policy that restricts what craft may change.
+
+The policy comes from the active profile's policy file (set with
+'craft config policy <profile> <file>') and $CRAFT_POLICY. When both are
+set they are merged and the stricter rule wins, so $CRAFT_POLICY can add
+restrictions but never lift the profile's. Policy files are YAML or JSON:
+
+  deny_destructive: true        # no deletes, clears or replace-mode updates
+  allowed_folders: [FOLDER_ID]  # writes only in these folders and subfolders
+  max_deleted_blocks: 50        # cap on blocks deleted per invocation,
+                                # including cleared and deleted documents
+  require_confirmation: true    # changes need --yes and --reason "..."
+
+The policy is enforced by the API client before every mutating request, so
+it applies to all commands, batch, apply and MCP tools alike.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := activePolicyPaths()
+		if err != nil {
+			return err
+		}
+		policy, err := loadSafetyPolicy()
+		if err != nil {
+			return err
+		}
+		out := map[string]interface{}{"sources": append([]string{}, paths...)}
+		if policy != nil {
+			out["policy"] = policy
+		}
+		return outputJSON(out)
+	},
+}
+
+var clearPolicy bool
+
+var configPolicyCmd = &cobra.Command{
+	Use:   "policy <profile> [file]",
+	Short: "Set or clear the safety policy of a profile",
+	Long: `Attach a safety policy file to a profile (see 'craft policy').
+
+Examples:
+  craft config policy agent ~/.craft-cli/agent-policy.yaml
+  craft config policy agent --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if clearPolicy {
+			if err := cfgManager.SetProfilePolicy(name, ""); err != nil {
+				return err
+			}
+			fmt.Printf("Policy cleared for profile '%s'\n", name)
+			return nil
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("policy file is required (or use --clear)")
+		}
+		path, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		if _, err := readPolicyFile(path); err != nil {
+			return err
+		}
+		if err := cfgManager.SetProfilePolicy(name, path); err != nil {
+			return err
+		}
+		fmt.Printf("Policy %s set for profile '%s'\n", path, name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	configCmd.AddCommand(configPolicyCmd)
+	configPolicyCmd.Flags().BoolVar(&clearPolicy, "clear", false, "Remove the profile's policy")
+}
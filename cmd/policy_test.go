@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// policyServer serves folder f1 (with subfolder f2) holding d1 and d2, and
+// f3 holding d3. It records every mutating request.
+type policyServer struct {
+	mu        sync.Mutex
+	mutations []string
+}
+
+func (s *policyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		s.mu.Lock()
+		s.mutations = append(s.mutations, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/folders":
+		w.Write([]byte(`{"items":[{"id":"f1","name":"Agent"},{"id":"f2","name":"Sub","parentId":"f1"},{"id":"f3","name":"Private"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/documents":
+		docs := map[string]string{"f1": "d1", "f2": "d2", "f3": "d3"}
+		w.Write([]byte(`{"items":[{"id":"` + docs[r.URL.Query().Get("folderId")] + `","title":"Doc"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/blocks":
+		id := r.URL.Query().Get("id")
+		w.Write([]byte(`{"id":"` + id + `","type":"page","markdown":"Doc","content":[
+			{"id":"` + id + `-b1","type":"text","markdown":"one"},
+			{"id":"` + id + `-b2","type":"text","markdown":"two"},
+			{"id":"` + id + `-b3","type":"text","markdown":"three"}]}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/documents":
+		w.Write([]byte(`{"items":[{"id":"new","title":"New"}]}`))
+	default:
+		w.Write([]byte(`{"items":[]}`))
+	}
+}
+
+func usePolicy(t *testing.T, policy string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(policyEnvVar, path)
+}
+
+func TestPolicyEnforcement(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		args    []string
+		denied  string // substring of the policy error; "" = allowed
+		changes int    // mutating requests that reached the server
+	}{
+		{"destructive command", "deny_destructive: true", []string{"delete", "d1"}, `destructive command "craft delete"`, 0},
+		{"destructive block delete", "deny_destructive: true", []string{"blocks", "delete", "d1-b1"}, `destructive command "craft blocks delete"`, 0},
+		{"replace deletes blocks", "deny_destructive: true", []string{"update", "d1", "--mode", "replace", "--markdown", "x"}, "DELETE /blocks is not allowed", 0},
+		{"append allowed", "deny_destructive: true", []string{"update", "d1", "--markdown", "x"}, "", 1},
+
+		{"confirmation missing", "require_confirmation: true", []string{"create", "--title", "T"}, "require --yes and --reason", 0},
+		{"confirmation without reason", "require_confirmation: true", []string{"create", "--title", "T", "--yes"}, "require --yes and --reason", 0},
+		{"confirmed", "require_confirmation: true", []string{"create", "--title", "T", "--yes", "--reason", "weekly report"}, "", 1},
+
+		{"block cap", "max_deleted_blocks: 2", []string{"clear", "d1"}, "exceed max_deleted_blocks", 0},
+		{"within block cap", "max_deleted_blocks: 3", []string{"clear", "d1"}, "", 1},
+		{"document counts its blocks", "max_deleted_blocks: 3", []string{"delete", "d1"}, "deleting 4 more block(s)", 0},
+		{"document within block cap", "max_deleted_blocks: 4", []string{"delete", "d1"}, "", 1},
+
+		{"block in allowed folder", "allowed_folders: [f1]", []string{"blocks", "update", "d1-b2", "--markdown", "x"}, "", 1},
+		{"block in subfolder", "allowed_folders: [f1]", []string{"blocks", "update", "d2-b1", "--markdown", "x"}, "", 1},
+		{"block outside", "allowed_folders: [f1]", []string{"blocks", "update", "d3-b1", "--markdown", "x"}, "d3-b1 is not in a document in the allowed folders", 0},
+		{"move within", "allowed_folders: [f1]", []string{"move", "d1", "--to-folder", "f2"}, "", 1},
+		{"move out", "allowed_folders: [f1]", []string{"move", "d1", "--to-folder", "f3"}, "folder f3 is outside", 0},
+		{"create", "allowed_folders: [f1]", []string{"create", "--title", "T"}, "new documents need --folder", 0},
+		{"create in allowed folder", "allowed_folders: [f1]", []string{"create", "--title", "T", "--folder", "f2", "--markdown", "x"}, "", 3},
+		{"create in other folder", "allowed_folders: [f1]", []string{"create", "--title", "T", "--folder", "f3"}, "folder f3 is outside", 0},
+		{"inbox task", "allowed_folders: [f1]", []string{"tasks", "add", "todo"}, "inbox tasks", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usePolicy(t, tt.policy)
+			ps := &policyServer{}
+			server := httptest.NewServer(ps)
+			defer server.Close()
+
+			_, err := runInProcess(append([]string{"--api-url", server.URL}, tt.args...), "")
+			switch {
+			case tt.denied == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.denied != "" && (err == nil || !strings.Contains(err.Error(), tt.denied)):
+				t.Errorf("error = %v, want %q", err, tt.denied)
+			case tt.denied != "" && categorizeError(err) != "POLICY_DENIED":
+				t.Errorf("category = %s, want POLICY_DENIED", categorizeError(err))
+			}
+			if len(ps.mutations) != tt.changes {
+				t.Errorf("mutations = %v, want %d", ps.mutations, tt.changes)
+			}
+		})
+	}
+}
+
+func TestPolicyCountsOnlySucceededDeletes(t *testing.T) {
+	g := &policyGuard{policy: &safetyPolicy{MaxDeletedBlocks: 2}}
+	del := api.Mutation{Method: http.MethodDelete, Path: "/blocks", Body: []byte(`{"blockIds":["a","b"]}`)}
+
+	for i := 0; i < 3; i++ {
+		if err := g.check(del); err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+		g.observe(api.Outcome{Mutation: del, Status: http.StatusInternalServerError, Err: errors.New("server error")})
+	}
+	if err := g.check(del); err != nil {
+		t.Fatal(err)
+	}
+	g.observe(api.Outcome{Mutation: del, Status: http.StatusOK})
+	one := api.Mutation{Method: http.MethodDelete, Path: "/blocks", Body: []byte(`{"blockIds":["c"]}`)}
+	if err := g.check(one); err == nil || !strings.Contains(err.Error(), "2 already deleted") {
+		t.Errorf("error = %v, want the cap reached", err)
+	}
+}
+
+func TestPolicyAllowsDryRun(t *testing.T) {
+	usePolicy(t, "deny_destructive: true")
+	server := httptest.NewServer(&policyServer{})
+	defer server.Close()
+	if _, err := runInProcess([]string{"--api-url", server.URL, "delete", "d1", "--dry-run"}, ""); err != nil {
+		t.Errorf("dry run denied: %v", err)
+	}
+}
+
+func TestReadPolicyFileRejectsUnknownRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	os.WriteFile(path, []byte("deny_destructve: true\n"), 0644)
+	if _, err := readPolicyFile(path); err == nil {
+		t.Error("expected an error for a misspelled rule")
+	}
+
+	os.WriteFile(path, []byte(`{"allowed_folders": ["f1"], "max_deleted_blocks": 5}`), 0644)
+	policy, err := readPolicyFile(path)
+	if err != nil || policy.MaxDeletedBlocks != 5 || policy.AllowedFolders[0] != "f1" {
+		t.Errorf("JSON policy = %+v, %v", policy, err)
+	}
+}
+
+func TestPolicyEnvAddsToProfilePolicy(t *testing.T) {
+	useTempConfig(t)
+	ps := &policyServer{}
+	server := httptest.NewServer(ps)
+	defer server.Close()
+	profilePolicy := filepath.Join(t.TempDir(), "profile.yaml")
+	os.WriteFile(profilePolicy, []byte("deny_destructive: true\nallowed_folders: [f1]\nmax_deleted_blocks: 10\n"), 0644)
+	cfgManager.AddProfile("work", server.URL)
+	cfgManager.SetProfilePolicy("work", profilePolicy)
+
+	// A permissive $CRAFT_POLICY does not lift the profile's rules.
+	usePolicy(t, "")
+	if _, err := runInProcess([]string{"delete", "d1"}, ""); err == nil || !isPolicyError(err) {
+		t.Errorf("delete with an empty $CRAFT_POLICY: %v", err)
+	}
+
+	usePolicy(t, "max_deleted_blocks: 3\nallowed_folders: [f2]\n")
+	policy, err := loadSafetyPolicy()
+	if err != nil || !policy.DenyDestructive || policy.MaxDeletedBlocks != 3 ||
+		len(policy.AllowedFolders) != 1 || len(policy.AlsoAllowedFolders) != 1 {
+		t.Fatalf("merged policy = %+v, %v", policy, err)
+	}
+	if _, err := runInProcess([]string{"blocks", "update", "d1-b2", "--markdown", "x"}, ""); err == nil || !strings.Contains(err.Error(), "not in a document in the allowed folders") {
+		t.Errorf("write outside the $CRAFT_POLICY folders: %v", err)
+	}
+	if _, err := runInProcess([]string{"blocks", "update", "d2-b1", "--markdown", "x"}, ""); err != nil {
+		t.Errorf("write inside both policies' folders: %v", err)
+	}
+}
+
+func TestCheckCommandPolicyUsesSafetyAnnotation(t *testing.T) {
+	usePolicy(t, "deny_destructive: true")
+	purge := &cobra.Command{Use: "purge", Annotations: map[string]string{safetyAnnotation: safetyDestructive}}
+	if err := checkCommandPolicy(purge); err == nil {
+		t.Error("a destructive command named purge was allowed")
+	}
+	for _, c := range []*cobra.Command{deleteCmd, clearCmd, blocksDeleteCmd, collectionsDeleteCmd, foldersDeleteCmd, tasksDeleteCmd, whiteboardDeleteCmd} {
+		if !commandSafety(c).Destructive {
+			t.Errorf("%s is not marked destructive", c.CommandPath())
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// profileDefault is a per-profile setting managed by 'craft config set'.
+type profileDefault struct {
+	Description string
+	// Flags maps command keys (as in commandOutputs) to the flag this
+	// setting provides the default for.
+	Flags map[string]string
+	// Parse validates a value and returns its canonical form.
+	Parse func(string) (string, error)
+}
+
+// profileDefaults lists the settings a profile can carry.
+var profileDefaults = map[string]profileDefault{
+	"folder": {
+		Description: "Folder ID that create moves new documents into",
+		Flags:       map[string]string{"create": "folder"},
+		Parse:       parseNonEmpty,
+	},
+	"format": {
+		Description: "Output format (" + strings.Join(ValidOutputFormats, ", ") + ")",
+		Parse:       parseOneOf(ValidOutputFormats),
+	},
+	"chunk-bytes": {
+		Description: "Max bytes per insert chunk when adding content",
+		Flags:       map[string]string{"update": "chunk-bytes"},
+		Parse:       parseIntRange(1000, 1<<20),
+	},
+	"timeout": {
+		Description: "Timeout of each API request (e.g. 30s, 2m)",
+		Parse:       parsePositiveDuration,
+	},
+	"retries": {
+		Description: "Retries of rate-limited requests and failed reads (0-10)",
+		Parse:       parseIntRange(0, 10),
+	},
+	"sandbox": {
+		Description: "Document ID that doctor adds and deletes a scratch block in",
+		Flags:       map[string]string{"doctor": "sandbox"},
+		Parse:       parseNonEmpty,
+	},
+	"read-only": {
+		Description: "Refuse every change made through this profile (true, false)",
+		Parse:       parseBoolValue,
+	},
+	"timezone": {
+		Description: "Time zone for today/yesterday/tomorrow daily-note dates (e.g. Europe/Berlin)",
+		Parse:       parseTimezone,
+	},
+}
+
+func parseNonEmpty(v string) (string, error) {
+	if strings.TrimSpace(v) == "" {
+		return "", fmt.Errorf("value must not be empty")
+	}
+	return strings.TrimSpace(v), nil
+}
+
+func parseOneOf(values []string) func(string) (string, error) {
+	return func(v string) (string, error) {
+		if !slices.Contains(values, v) {
+			return "", fmt.Errorf("must be one of: %s", strings.Join(values, ", "))
+		}
+		return v, nil
+	}
+}
+
+func parseIntRange(lo, hi int) func(string) (string, error) {
+	return func(v string) (string, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < lo || n > hi {
+			return "", fmt.Errorf("must be a whole number from %d to %d", lo, hi)
+		}
+		return strconv.Itoa(n), nil
+	}
+}
+
+func parsePositiveDuration(v string) (string, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return "", fmt.Errorf("must be a positive duration such as 30s or 2m")
+	}
+	return d.String(), nil
+}
+
+func parseBoolValue(v string) (string, error) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return "", fmt.Errorf("must be true or false")
+	}
+	return strconv.FormatBool(b), nil
+}
+
+func parseTimezone(v string) (string, error) {
+	if _, err := time.LoadLocation(v); err != nil || v == "" {
+		return "", fmt.Errorf("unknown time zone %q (use an IANA name such as America/New_York)", v)
+	}
+	return v, nil
+}
+
+// activeDefault returns a default of the profile in effect, or "".
+func activeDefault(key string) string {
+	if cfgManager == nil {
+		return ""
+	}
+	defaults, err := cfgManager.GetActiveDefaults()
+	if err != nil {
+		return ""
+	}
+	return defaults[key]
+}
+
+// applyProfileDefaults fills flags the profile has defaults for, unless they
+// were given on the command line.
+func applyProfileDefaults(cmd *cobra.Command) {
+	key := commandKey(cmd)
+	for name, d := range profileDefaults {
+		flag, ok := d.Flags[key]
+		if !ok {
+			continue
+		}
+		value := activeDefault(name)
+		if f := cmd.Flags().Lookup(flag); f != nil && !f.Changed && value != "" {
+			f.Value.Set(value)
+		}
+	}
+}
+
+// applyClientDefaults configures client from the profile's defaults.
+func applyClientDefaults(client *api.Client) {
+	if d, err := time.ParseDuration(activeDefault("timeout")); err == nil {
+		client.SetTimeout(d)
+	}
+	if n, err := strconv.Atoi(activeDefault("retries")); err == nil {
+		client.SetRetries(n)
+	}
+	if n, err := strconv.Atoi(activeDefault("chunk-bytes")); err == nil {
+		client.SetChunkBytes(n)
+	}
+}
+
+// resolveDay turns today, yesterday and tomorrow into a date in the
+// profile's time zone, so daily notes do not depend on where craft runs.
+// Other values, and all values without a time zone default, pass through.
+func resolveDay(day string) string {
+	tz := activeDefault("timezone")
+	if tz == "" {
+		return day
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return day
+	}
+	offset := map[string]int{"yesterday": -1, "today": 0, "tomorrow": 1}
+	n, ok := offset[strings.ToLower(day)]
+	if !ok {
+		return day
+	}
+	return time.Now().In(loc).AddDate(0, 0, n).Format("2006-01-02")
+}
+
+// profileDefaultKeys returns the setting names in order.
+func profileDefaultKeys() []string {
+	keys := make([]string, 0, len(profileDefaults))
+	for k := range profileDefaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func lookupProfileDefault(key string) (profileDefault, error) {
+	d, ok := profileDefaults[key]
+	if !ok {
+		return d, fmt.Errorf("unknown setting %q (valid: %s)", key, strings.Join(profileDefaultKeys(), ", "))
+	}
+	return d, nil
+}
+
+// profileSettingsHelp lists the settings for command help.
+func profileSettingsHelp() string {
+	var sb strings.Builder
+	for _, k := range profileDefaultKeys() {
+		fmt.Fprintf(&sb, "  %-12s %s\n", k, profileDefaults[k].Description)
+	}
+	return sb.String()
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <profile> <key> <value>",
+	Short: "Set a default of a profile",
+	Long: `Set a per-profile default. Flags, environment variables and .craft.yaml
+still take precedence.
+
+Settings:
+` + profileSettingsHelp() + `
+Examples:
+  craft config set work format table
+  craft config set agent read-only true
+  craft config set work timezone Europe/Berlin`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, key := args[0], args[1]
+		d, err := lookupProfileDefault(key)
+		if err != nil {
+			return err
+		}
+		value, err := d.Parse(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		if err := cfgManager.SetProfileDefault(name, key, value); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s=%s for profile '%s'\n", key, value, name)
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <profile> [key]",
+	Short: "Show the defaults of a profile",
+	Long: `Print one default of a profile, or all of them as key=value lines.
+Unset settings print nothing.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defaults, err := cfgManager.ProfileDefaults(args[0])
+		if err != nil {
+			return err
+		}
+		if len(args) == 2 {
+			if _, err := lookupProfileDefault(args[1]); err != nil {
+				return err
+			}
+			if v, ok := defaults[args[1]]; ok {
+				fmt.Println(v)
+			}
+			return nil
+		}
+		for _, k := range profileDefaultKeys() {
+			if v, ok := defaults[k]; ok {
+				fmt.Printf("%s=%s\n", k, v)
+			}
+		}
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <profile> <key>",
+	Short: "Remove a default of a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, key := args[0], args[1]
+		if _, err := lookupProfileDefault(key); err != nil {
+			return err
+		}
+		if err := cfgManager.SetProfileDefault(name, key, ""); err != nil {
+			return err
+		}
+		fmt.Printf("Unset %s for profile '%s'\n", key, name)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigSetValidatesDefaults(t *testing.T) {
+	useTempConfig(t)
+	if err := cfgManager.AddProfile("work", "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"work", "format", "yaml"},
+		{"work", "timezone", "Mars/Olympus"},
+		{"work", "retries", "11"},
+		{"work", "colour", "blue"},
+		{"nobody", "format", "json"},
+	} {
+		if _, err := runInProcess(append([]string{"config", "set"}, args...), ""); err == nil {
+			t.Errorf("config set %v: expected an error", args)
+		}
+	}
+
+	if _, err := runInProcess([]string{"config", "set", "work", "timeout", "90s"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runInProcess([]string{"config", "get", "work", "timeout"}, "")
+	if err != nil || strings.TrimSpace(out) != "1m30s" {
+		t.Errorf("config get = %q, %v; want the canonical 1m30s", out, err)
+	}
+	if _, err := runInProcess([]string{"config", "unset", "work", "timeout"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := runInProcess([]string{"config", "get", "work"}, ""); out != "" {
+		t.Errorf("defaults after unset = %q", out)
+	}
+}
+
+func TestProfileDefaultsApply(t *testing.T) {
+	useTempConfig(t)
+	ps := &policyServer{}
+	server := httptest.NewServer(ps)
+	defer server.Close()
+	if err := cfgManager.AddProfile("work", server.URL); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{"format": "table", "folder": "f1"} {
+		if err := cfgManager.SetProfileDefault("work", key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runInProcess([]string{"config", "explain"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "SETTING") || !strings.Contains(out, `profile "work"`) {
+		t.Errorf("format default not applied:\n%s", out)
+	}
+	if out, _ := runInProcess([]string{"config", "explain", "--format", "json"}, ""); !strings.HasPrefix(out, "[") {
+		t.Errorf("--format did not override the profile default:\n%s", out)
+	}
+
+	if _, err := runInProcess([]string{"create", "--title", "T"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(ps.mutations, ", "); got != "POST /documents, PUT /documents" {
+		t.Errorf("mutations = %s, want the new document moved to the default folder", got)
+	}
+
+	ps.mutations = nil
+	if _, err := runInProcess([]string{"create", "--title", "T", "--parent", "d1"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(ps.mutations) != 1 {
+		t.Errorf("mutations = %v; the folder default must not apply to nested pages", ps.mutations)
+	}
+}
+
+func TestReadOnlyProfile(t *testing.T) {
+	useTempConfig(t)
+	ps := &policyServer{}
+	server := httptest.NewServer(ps)
+	defer server.Close()
+	if err := cfgManager.AddProfile("agent", server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfgManager.SetProfileDefault("agent", "read-only", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runInProcess([]string{"update", "d1", "--markdown", "x"}, "")
+	if err == nil || !strings.Contains(err.Error(), `profile "agent" is read-only`) || categorizeError(err) != "POLICY_DENIED" {
+		t.Errorf("error = %v, want a POLICY_DENIED read-only error", err)
+	}
+	if len(ps.mutations) != 0 {
+		t.Errorf("mutations = %v, want none", ps.mutations)
+	}
+	if _, err := runInProcess([]string{"get", "d1"}, ""); err != nil {
+		t.Errorf("reads must still work: %v", err)
+	}
+}
+
+func TestResolveDayUsesProfileTimezone(t *testing.T) {
+	useTempConfig(t)
+	if err := cfgManager.AddProfile("work", "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := resolveDay("today"); got != "today" {
+		t.Errorf("without a time zone, resolveDay = %q", got)
+	}
+
+	if err := cfgManager.SetProfileDefault("work", "timezone", "Pacific/Kiritimati"); err != nil {
+		t.Fatal(err)
+	}
+	loc, _ := time.LoadLocation("Pacific/Kiritimati")
+	want := time.Now().In(loc).AddDate(0, 0, -1).Format("2006-01-02")
+	if got := resolveDay("Yesterday"); got != want {
+		t.Errorf("resolveDay(yesterday) = %q, want %q", got, want)
+	}
+	if got := resolveDay("2025-01-15"); got != "2025-01-15" {
+		t.Errorf("explicit dates must pass through, got %q", got)
+	}
+}
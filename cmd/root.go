@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
@@ -34,6 +35,13 @@ var (
 	idOnly     bool
 	dryRun     bool
 	yesFlag    bool
+
+	// Terminal output flags
+	colorMode string
+	noPager   bool
+
+	// streamOutput writes list results as NDJSON while they are decoded
+	streamOutput bool
 )
 
 // rootCmd represents the base command
@@ -48,19 +56,43 @@ Use --quiet to suppress status messages for cleaner piping.
 Use --json-errors for machine-readable error output.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFlagConstraints(cmd); err != nil {
+			return err
+		}
+		if err := applyConfigLayers(cmd); err != nil {
+			return err
+		}
+		applyProfileDefaults(cmd)
+		if err := checkFanOut(cmd); err != nil {
+			return err
+		}
+		if err := checkCommandPolicy(cmd); err != nil {
+			return err
+		}
+		if err := resolveIDParams(cmd, args); err != nil {
+			return err
+		}
+		auditCommand = auditCommandLine(cmd, args)
+
 		// Skip update check for upgrade, version, and help commands
 		cmdName := cmd.Name()
-		if cmdName == "upgrade" || cmdName == "version" || cmdName == "help" || cmdName == "completion" {
-			return
+		if inProcess || cmdName == "upgrade" || cmdName == "version" || cmdName == "help" || cmdName == "completion" {
+			return nil
 		}
 		// Check for updates in background (non-blocking)
 		go notifyUpdateAvailable()
+		return nil
 	},
 }
 
 // Execute runs the root command
 func Execute() {
+	if m, err := config.NewManager(); err == nil {
+		if cfg, err := m.Load(); err == nil {
+			registerUserCommands(cfg)
+		}
+	}
 	if err := rootCmd.Execute(); err != nil {
 		handleError(err)
 	}
@@ -107,6 +139,9 @@ Documentation:
 `)
 
 	// API and format flags
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Profile to use (overrides $CRAFT_PROFILE, .craft.yaml and the active profile)")
+	rootCmd.PersistentFlags().StringSliceVar(&fanOutProfiles, "profiles", nil, "Run a read command (list, search, tasks list) across these profiles and merge the results")
+	rootCmd.PersistentFlags().BoolVar(&allProfiles, "all-profiles", false, "Run a read command (list, search, tasks list) across every profile")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "Craft API URL (overrides config)")
 	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key for authentication (overrides config)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "", "Output format (json, compact=legacy JSON, table, markdown)")
@@ -120,6 +155,12 @@ Documentation:
 	rootCmd.PersistentFlags().BoolVar(&idOnly, "id-only", false, "Output only document IDs (shorthand for --output-only id)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what would happen without making changes")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().StringVar(&reasonFlag, "reason", "", "Why this change is made (required by policies with require_confirmation)")
+
+	// Terminal output flags
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", ColorAuto, "Colorize terminal output (auto, always, never); NO_COLOR disables auto")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Do not pipe long terminal output through $PAGER")
+	rootCmd.PersistentFlags().BoolVar(&streamOutput, "stream", false, "Stream results as NDJSON (one JSON object per line) as they arrive")
 }
 
 func initConfig() {
@@ -154,17 +195,34 @@ func getAPIClient() (*api.Client, error) {
 	// Get API key: flag > config > empty
 	key := apiKey
 	if key == "" {
-		var err error
-		key, err = cfgManager.GetActiveAPIKey()
-		if err != nil {
-			key = ""
-		}
+		key = profileKeyFor(url)
 	}
 
+	client := api.NewClient(url)
 	if key != "" {
-		return api.NewClientWithKey(url, key), nil
+		client = api.NewClientWithKey(url, key)
 	}
-	return api.NewClient(url), nil
+	applyClientDefaults(client)
+	if err := installPolicyGuard(client); err != nil {
+		return nil, err
+	}
+	installAuditLog(client)
+	return client, nil
+}
+
+// profileKeyFor returns the active profile's API key if url is the
+// profile's own URL. A URL from a flag, the environment or .craft.yaml
+// never gets the stored key, so a project file cannot send it elsewhere.
+func profileKeyFor(url string) string {
+	profileURL, err := cfgManager.GetActiveURL()
+	if err != nil || profileURL != url {
+		return ""
+	}
+	key, err := cfgManager.GetActiveAPIKey()
+	if err != nil {
+		return ""
+	}
+	return key
 }
 
 // getOutputFormat returns the output format to use
@@ -172,6 +230,9 @@ func getOutputFormat() string {
 	if outputFormat != "" {
 		return outputFormat
 	}
+	if format := activeDefault("format"); format != "" {
+		return format
+	}
 
 	cfg, err := cfgManager.Load()
 	if err != nil {
@@ -203,7 +264,8 @@ func handleError(err error) {
 		if hint := errorHint(code); hint != "" {
 			errObj["hint"] = hint
 		}
-		if apiErr, ok := err.(*api.APIError); ok {
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) {
 			errObj["status"] = apiErr.StatusCode
 		}
 		json.NewEncoder(os.Stderr).Encode(errObj)
@@ -226,7 +288,8 @@ func handleError(err error) {
 
 // categorizeError returns an error category for JSON output
 func categorizeError(err error) string {
-	if apiErr, ok := err.(*api.APIError); ok {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
 		switch apiErr.StatusCode {
 		case 401:
 			return "AUTH_ERROR"
@@ -246,6 +309,10 @@ func categorizeError(err error) string {
 		}
 	}
 
+	if isPolicyError(err) {
+		return "POLICY_DENIED"
+	}
+
 	errStr := err.Error()
 	switch {
 	case contains(errStr, "no active profile"), contains(errStr, "config"):
@@ -278,11 +345,13 @@ func errorHint(code string) string {
 	case "CONFIG_ERROR":
 		return "Run 'craft config list' or 'craft setup' to reconfigure. (not retryable)"
 	case "NOT_FOUND":
-		return "Check the ID is correct. Use 'craft list --id-only' to find valid IDs. (not retryable)"
+		return "Check the ID is correct. Use 'craft list --id-only' to find valid IDs, or name documents as @bookmark or title:\"...\". (not retryable)"
 	case "RATE_LIMIT":
 		return "Wait and retry. The API limits request frequency. (retryable)"
 	case "API_ERROR":
 		return "Server error. Retry in a few seconds. If persistent, check Craft status. (retryable)"
+	case "POLICY_DENIED":
+		return "The safety policy does not allow this; see 'craft policy'. (not retryable)"
 	case "USER_ERROR":
 		return "Check command usage with --help. (not retryable)"
 	default:
@@ -308,28 +377,6 @@ func isQuiet() bool {
 	return quietMode
 }
 
-// dryRunOutput prints structured dry-run info.
-// If JSON mode, outputs JSON to stdout. Otherwise prints human prose.
-func dryRunOutput(action string, target map[string]interface{}) error {
-	if getOutputFormat() == "json" || jsonErrors {
-		result := map[string]interface{}{
-			"dry_run": true,
-			"action":  action,
-			"target":  target,
-		}
-		return outputJSON(result)
-	}
-	fmt.Printf("[dry-run] Would %s", action)
-	if id, ok := target["id"]; ok {
-		fmt.Printf(" %v", id)
-	}
-	if title, ok := target["title"]; ok {
-		fmt.Printf(" (%v)", title)
-	}
-	fmt.Println()
-	return nil
-}
-
 // isDryRun returns whether dry-run mode is enabled
 func isDryRun() bool {
 	return dryRun
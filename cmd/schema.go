@@ -19,6 +19,10 @@ type CommandSchema struct {
 	Subcommands []CommandSchema `json:"subcommands,omitempty"`
 	Examples    []string        `json:"examples,omitempty"`
 	Safety      *SafetyInfo     `json:"safety,omitempty"`
+	// Expands lists the command lines an alias or macro runs.
+	Expands []string `json:"expands,omitempty"`
+	// OutputFormats lists the formats 'craft schema --output' can describe.
+	OutputFormats []string `json:"output_formats,omitempty"`
 }
 
 // FlagSchema describes a command flag
@@ -29,6 +33,13 @@ type FlagSchema struct {
 	Default  string `json:"default,omitempty"`
 	Required bool   `json:"required"`
 	Desc     string `json:"description"`
+
+	Enum          []string            `json:"enum,omitempty"`
+	EnumList      bool                `json:"enum_list,omitempty"` // comma-separated enum values
+	Format        string              `json:"format,omitempty"`
+	ConflictsWith []string            `json:"conflicts_with,omitempty"`
+	Requires      []string            `json:"requires,omitempty"`
+	RequiresWhen  map[string][]string `json:"requires_when,omitempty"`
 }
 
 // SafetyInfo describes the safety characteristics of a command
@@ -48,13 +59,38 @@ flags, types, and safety metadata. Designed for AI agent introspection.
 An agent can call 'craft schema' once to discover all available
 commands without parsing --help text.
 
+Use --output with --command to get the JSON Schema of what that command
+prints to stdout. It describes --format json unless --format is given.
+
 Examples:
   craft schema                    # Full schema as JSON
   craft schema --command list     # Schema for a specific command
-  craft schema --commands-only    # Just command names and descriptions`,
+  craft schema --commands-only    # Just command names and descriptions
+  craft schema --command get --output
+  craft schema --command "tasks list" --output --format compact`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		commandFilter, _ := cmd.Flags().GetString("command")
 		commandsOnly, _ := cmd.Flags().GetBool("commands-only")
+		output, _ := cmd.Flags().GetBool("output")
+
+		if output {
+			if commandFilter == "" {
+				return fmt.Errorf("--output requires --command")
+			}
+			target, _, err := rootCmd.Find(strings.Fields(commandFilter))
+			if err != nil || target == rootCmd {
+				return fmt.Errorf("unknown command: %s", commandFilter)
+			}
+			format := FormatJSON
+			if f := rootCmd.PersistentFlags().Lookup("format"); f != nil && f.Changed {
+				format = outputFormat
+			}
+			schema, err := buildOutputSchema(target, format)
+			if err != nil {
+				return err
+			}
+			return outputSchemaJSON(schema)
+		}
 
 		schema := buildSchema(rootCmd)
 
@@ -87,6 +123,7 @@ func init() {
 	rootCmd.AddCommand(schemaCmd)
 	schemaCmd.Flags().String("command", "", "Show schema for a specific command only")
 	schemaCmd.Flags().Bool("commands-only", false, "Output only command names and descriptions")
+	schemaCmd.Flags().Bool("output", false, "Output the JSON Schema of the --command's stdout")
 }
 
 func buildSchema(cmd *cobra.Command) CommandSchema {
@@ -117,11 +154,27 @@ func buildSchema(cmd *cobra.Command) CommandSchema {
 		if f.DefValue != "" && f.DefValue != "false" {
 			fs.Default = f.DefValue
 		}
+		fs.Required = isRequiredFlag(f)
+		if c, ok := lookupFlagConstraint(cmd, f.Name); ok {
+			fs.Enum = c.Enum
+			fs.EnumList = c.List
+			fs.Format = c.Format
+			for _, other := range c.Conflicts {
+				fs.ConflictsWith = append(fs.ConflictsWith, "--"+other)
+			}
+			fs.Requires = describeConditions(c.Requires)
+			fs.RequiresWhen = describeRequiresWhen(c.RequiresWhen)
+		}
 		schema.Flags = append(schema.Flags, fs)
 	})
 
-	// Add safety metadata based on command name
-	schema.Safety = inferSafety(cmd.Name())
+	schema.Safety = commandSafety(cmd)
+	if expands := cmd.Annotations[expandsAnnotation]; expands != "" {
+		for _, line := range strings.Split(expands, "\n") {
+			schema.Expands = append(schema.Expands, "craft "+line)
+		}
+	}
+	schema.OutputFormats = outputFormats(cmd)
 
 	// Collect subcommands
 	for _, sub := range cmd.Commands() {
@@ -134,16 +187,30 @@ func buildSchema(cmd *cobra.Command) CommandSchema {
 	return schema
 }
 
+// safetyAnnotation marks a command's safety class explicitly, so it does
+// not depend on the command's name.
+const (
+	safetyAnnotation  = "craft:safety"
+	safetyDestructive = "destructive"
+)
+
+// commandSafety returns the safety metadata of cmd: destructive if it is
+// annotated so, otherwise inferred from its name.
+func commandSafety(cmd *cobra.Command) *SafetyInfo {
+	if cmd.Annotations[safetyAnnotation] == safetyDestructive {
+		return &SafetyInfo{ReadOnly: false, Destructive: true, Idempotent: true, DryRun: true}
+	}
+	return inferSafety(cmd.Name())
+}
+
 func inferSafety(name string) *SafetyInfo {
 	switch name {
-	case "list", "get", "search", "info", "connection", "version", "folders", "tasks", "collections", "llm", "schema":
+	case "list", "get", "search", "info", "connection", "version", "folders", "tasks", "collections", "llm", "schema", "plan", "openapi", "context", "policy", "audit":
 		return &SafetyInfo{ReadOnly: true, Destructive: false, Idempotent: true, DryRun: false}
 	case "create":
 		return &SafetyInfo{ReadOnly: false, Destructive: false, Idempotent: false, DryRun: true}
 	case "update", "move":
 		return &SafetyInfo{ReadOnly: false, Destructive: false, Idempotent: true, DryRun: true}
-	case "delete", "clear":
-		return &SafetyInfo{ReadOnly: false, Destructive: true, Idempotent: true, DryRun: true}
 	default:
 		return &SafetyInfo{ReadOnly: false, Destructive: false, Idempotent: false, DryRun: true}
 	}
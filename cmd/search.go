@@ -37,6 +37,7 @@ Document search (default):
   craft search "project" --location daily_notes
   craft search "budget" --folder <folder-id> --metadata
   craft search --created-after 2024-01-01 --modified-before 2024-12-31 "report"
+  craft search "roadmap" --profiles work,personal   # Search several spaces at once
 
 Block search (with --document):
   craft search --document <doc-id> "keyword"
@@ -44,6 +45,10 @@ Block search (with --document):
   craft search --document <doc-id> "term" --context 10`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if isFanOut() {
+			return runFanOutSearch(args)
+		}
+
 		client, err := getAPIClient()
 		if err != nil {
 			return err
@@ -97,6 +102,15 @@ func runBlockSearch(client *api.Client, args []string, format string) error {
 		return err
 	}
 
+	if isStreaming() {
+		for _, item := range result.Items {
+			if err := writeNDJSON(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if len(result.Items) == 0 {
 		printStatus("No matching blocks found\n")
 		// Still output empty result in the requested format
@@ -109,25 +123,14 @@ func runBlockSearch(client *api.Client, args []string, format string) error {
 
 // runDocumentSearch executes an advanced document search.
 func runDocumentSearch(client *api.Client, args []string, format string) error {
-	query := ""
-	if len(args) > 0 {
-		query = args[0]
-	}
-
-	// If no query and no regex, require at least one
-	if query == "" && searchRegex == "" {
-		return fmt.Errorf("search requires a query argument or --regex pattern")
+	query, err := documentSearchQuery(args)
+	if err != nil {
+		return err
 	}
+	opts := documentSearchOptions()
 
-	opts := api.SearchOptions{
-		Regexps:             searchRegex,
-		Location:            searchLocation,
-		FolderIDs:           searchFolder,
-		FetchMetadata:       searchMetadata,
-		CreatedDateGte:      searchCreatedAfter,
-		CreatedDateLte:      searchCreatedBefore,
-		LastModifiedDateGte: searchModifiedAfter,
-		LastModifiedDateLte: searchModifiedBefore,
+	if isStreaming() {
+		return finishStream(client.StreamSearch(query, opts, ndjsonSink[models.SearchItem](searchLimit)))
 	}
 
 	result, err := client.SearchDocumentsAdvanced(query, opts)
@@ -151,6 +154,63 @@ func runDocumentSearch(client *api.Client, args []string, format string) error {
 	return outputSearchResults(result.Items, format)
 }
 
+// documentSearchQuery returns the query of a document search.
+func documentSearchQuery(args []string) (string, error) {
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	// If no query and no regex, require at least one
+	if query == "" && searchRegex == "" {
+		return "", fmt.Errorf("search requires a query argument or --regex pattern")
+	}
+	return query, nil
+}
+
+// documentSearchOptions returns the filters of a document search.
+func documentSearchOptions() api.SearchOptions {
+	return api.SearchOptions{
+		Regexps:             searchRegex,
+		Location:            searchLocation,
+		FolderIDs:           searchFolder,
+		FetchMetadata:       searchMetadata,
+		CreatedDateGte:      searchCreatedAfter,
+		CreatedDateLte:      searchCreatedBefore,
+		LastModifiedDateGte: searchModifiedAfter,
+		LastModifiedDateLte: searchModifiedBefore,
+	}
+}
+
+// runFanOutSearch runs a document search across several profiles. Block
+// search is not supported: a document ID belongs to a single space.
+func runFanOutSearch(args []string) error {
+	if searchDocument != "" {
+		return fmt.Errorf("--document cannot be combined with --profiles or --all-profiles")
+	}
+	query, err := documentSearchQuery(args)
+	if err != nil {
+		return err
+	}
+	opts := documentSearchOptions()
+	return runFanOut(func(client *api.Client) ([]models.SearchItem, error) {
+		result, err := client.SearchDocumentsAdvanced(query, opts)
+		if err != nil {
+			return nil, err
+		}
+		if searchLimit > 0 && len(result.Items) > searchLimit {
+			result.Items = result.Items[:searchLimit]
+		}
+		return result.Items, nil
+	}, fanOutView[models.SearchItem]{
+		Columns: []string{"DOCUMENT ID", "MATCH"},
+		Row: func(item models.SearchItem) []string {
+			return []string{item.DocumentID, truncate(item.Markdown, 80)}
+		},
+		Limit: searchLimit,
+	})
+}
+
 // --- Block search output functions ---
 
 // outputBlockSearchResults dispatches block search results to the appropriate formatter.
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Environment variables that override the config file.
+const (
+	envAPIURL  = "CRAFT_API_URL"
+	envAPIKey  = "CRAFT_API_KEY"
+	envProfile = "CRAFT_PROFILE"
+	envFormat  = "CRAFT_FORMAT"
+)
+
+// profileName is the --profile flag.
+var profileName string
+
+// setting is an effective configuration value and where it came from.
+type setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// layeredSettings are the values set above the config file: by flags,
+// environment variables or the project's .craft.yaml. Unset values are left
+// to the config file.
+type layeredSettings struct {
+	Profile, APIURL, APIKey, Format setting
+}
+
+// resolveLayers finds the highest-precedence source of each setting: flags,
+// then environment variables, then the nearest .craft.yaml.
+func resolveLayers(cmd *cobra.Command) (*layeredSettings, error) {
+	var project config.ProjectConfig
+	var projectPath string
+	if cwd, err := os.Getwd(); err == nil {
+		found, path, err := config.FindProjectConfig(cwd)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			project, projectPath = *found, path
+		}
+	}
+
+	pick := func(name, flag, env, projectValue string) setting {
+		if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+			return setting{Name: name, Value: f.Value.String(), Source: "flag --" + flag}
+		}
+		if v := os.Getenv(env); v != "" {
+			return setting{Name: name, Value: v, Source: "env " + env}
+		}
+		if projectValue != "" {
+			return setting{Name: name, Value: projectValue, Source: projectPath}
+		}
+		return setting{Name: name}
+	}
+	s := &layeredSettings{
+		Profile: pick("profile", "profile", envProfile, project.Profile),
+		APIURL:  pick("api_url", "api-url", envAPIURL, project.APIURL),
+		APIKey:  pick("api_key", "api-key", envAPIKey, ""),
+		Format:  pick("format", "format", envFormat, project.Format),
+	}
+	if s.Format.Value != "" && !slices.Contains(ValidOutputFormats, s.Format.Value) {
+		return nil, fmt.Errorf("invalid format %q from %s (valid: %v)", s.Format.Value, s.Format.Source, ValidOutputFormats)
+	}
+	return s, nil
+}
+
+// applyConfigLayers makes settings from flags, the environment and
+// .craft.yaml take effect over the config file.
+func applyConfigLayers(cmd *cobra.Command) error {
+	s, err := resolveLayers(cmd)
+	if err != nil {
+		return err
+	}
+	apiURL, apiKey, outputFormat = s.APIURL.Value, s.APIKey.Value, s.Format.Value
+	cfgManager.SetProfile(s.Profile.Value)
+	return nil
+}
+
+// explainSettings returns every effective setting with its source, falling
+// back to the config file and built-in defaults.
+func explainSettings(cmd *cobra.Command) ([]setting, error) {
+	s, err := resolveLayers(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := cfgManager.Load()
+	if err != nil {
+		return nil, err
+	}
+	cfgPath := cfgManager.Path()
+	if _, err := os.Stat(cfgPath); err != nil {
+		cfgPath = "default"
+	}
+
+	profile := s.Profile
+	if profile.Value == "" && cfg.ActiveProfile != "" {
+		profile = setting{Name: "profile", Value: cfg.ActiveProfile, Source: cfgPath}
+	}
+	fromProfile := fmt.Sprintf("profile %q in %s", profile.Value, cfgManager.Path())
+
+	url := s.APIURL
+	if url.Value == "" && profile.Value != "" {
+		v, err := cfgManager.GetActiveURL()
+		if err != nil {
+			return nil, err
+		}
+		url = setting{Name: "api_url", Value: v, Source: fromProfile}
+	}
+	key := s.APIKey
+	if key.Value == "" && profile.Value != "" {
+		if v := profileKeyFor(url.Value); v != "" {
+			key = setting{Name: "api_key", Value: v, Source: fromProfile}
+		}
+	}
+	if key.Value != "" {
+		key.Value = "(set)"
+	}
+	format := s.Format
+	if v := activeDefault("format"); format.Value == "" && v != "" {
+		format = setting{Name: "format", Value: v, Source: fromProfile}
+	}
+	if format.Value == "" {
+		format = setting{Name: "format", Value: cfg.DefaultFormat, Source: cfgPath}
+	}
+
+	settings := []setting{profile, url, key, format}
+	for i := range settings {
+		if settings[i].Source == "" {
+			settings[i].Source = "unset"
+		}
+	}
+	return settings, nil
+}
+
+var explainConfigCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show each effective setting and where it came from",
+	Long: `Show the effective profile, API URL, API key and output format, and the
+source of each. Sources take precedence in this order:
+
+  1. Flags:                 --profile, --api-url, --api-key, --format
+  2. Environment variables: CRAFT_PROFILE, CRAFT_API_URL, CRAFT_API_KEY, CRAFT_FORMAT
+  3. Project file:          the nearest .craft.yaml in the working directory or
+                            a parent (keys: profile, api_url, format)
+  4. Config file:           $XDG_CONFIG_HOME/craft-cli/config.json if that
+                            directory exists, else ~/.craft-cli/config.json
+
+The API key is never printed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := explainSettings(cmd)
+		if err != nil {
+			return err
+		}
+		if isJSONFormat(getOutputFormat()) {
+			return outputJSON(settings)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !hasNoHeaders() {
+			fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+			fmt.Fprintln(w, "-------\t-----\t------")
+		}
+		for _, s := range settings {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Value, s.Source)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(explainConfigCmd)
+}
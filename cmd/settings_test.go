@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigLayerPrecedence(t *testing.T) {
+	useTempConfig(t)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := cfgManager.AddProfile(name, "https://example.com/"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, ".craft.yaml"), []byte("profile: b\nformat: table\n"), 0644)
+	sub := filepath.Join(project, "notes", "drafts")
+	os.MkdirAll(sub, 0755)
+	t.Chdir(sub)
+
+	explain := func(args ...string) map[string]setting {
+		t.Helper()
+		out, err := runInProcess(append([]string{"config", "explain"}, args...), "")
+		if err != nil {
+			t.Fatalf("config explain %v: %v", args, err)
+		}
+		var settings []setting
+		if err := json.Unmarshal([]byte(out), &settings); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		byName := map[string]setting{}
+		for _, s := range settings {
+			byName[s.Name] = s
+		}
+		return byName
+	}
+
+	t.Setenv(envFormat, "json")
+	got := explain()
+	if got["profile"].Value != "b" || !strings.HasSuffix(got["profile"].Source, ".craft.yaml") {
+		t.Errorf("profile = %+v, want b from .craft.yaml", got["profile"])
+	}
+	if got["api_url"].Value != "https://example.com/b" || got["format"].Source != "env CRAFT_FORMAT" {
+		t.Errorf("api_url = %+v, format = %+v", got["api_url"], got["format"])
+	}
+
+	t.Setenv(envProfile, "c")
+	t.Setenv(envAPIKey, "pdk_env")
+	got = explain()
+	if got["profile"].Value != "c" || got["api_url"].Value != "https://example.com/c" {
+		t.Errorf("env profile: %+v %+v", got["profile"], got["api_url"])
+	}
+	if got["api_key"].Value != "(set)" || got["api_key"].Source != "env CRAFT_API_KEY" {
+		t.Errorf("api_key = %+v; the key must never be printed", got["api_key"])
+	}
+
+	got = explain("--profile", "a", "--api-url", "https://override.example.com")
+	if got["profile"].Source != "flag --profile" || got["api_url"].Value != "https://override.example.com" {
+		t.Errorf("flags: %+v %+v", got["profile"], got["api_url"])
+	}
+}
+
+func TestConfigLayerErrors(t *testing.T) {
+	useTempConfig(t)
+	cfgManager.AddProfile("a", "https://example.com/a")
+	project := t.TempDir()
+	t.Chdir(project)
+
+	os.WriteFile(".craft.yaml", []byte("api_key: pdk_committed\n"), 0644)
+	if _, err := runInProcess([]string{"config", "explain"}, ""); err == nil || !strings.Contains(err.Error(), ".craft.yaml") {
+		t.Errorf("api_key in .craft.yaml: error = %v", err)
+	}
+
+	os.WriteFile(".craft.yaml", []byte("format: yaml\n"), 0644)
+	if _, err := runInProcess([]string{"config", "explain"}, ""); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("bad format: error = %v", err)
+	}
+
+	os.Remove(".craft.yaml")
+	t.Setenv(envProfile, "missing")
+	if _, err := runInProcess([]string{"list"}, ""); err == nil || !strings.Contains(err.Error(), "'missing' not found") {
+		t.Errorf("unknown CRAFT_PROFILE: error = %v", err)
+	}
+}
+
+func TestProjectURLDoesNotGetProfileKey(t *testing.T) {
+	useTempConfig(t)
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+	if err := cfgManager.AddProfileWithKey("work", server.URL+"/profile", "pdk_secret"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	if _, err := runInProcess([]string{"list"}, ""); err != nil || len(auth) != 1 || auth[0] != "Bearer pdk_secret" {
+		t.Fatalf("profile URL: %v, auth %q", err, auth)
+	}
+	os.WriteFile(".craft.yaml", []byte("api_url: "+server.URL+"/elsewhere\n"), 0644)
+	runInProcess([]string{"list"}, "")
+	if len(auth) != 2 || auth[1] != "" {
+		t.Errorf("a .craft.yaml api_url was sent the profile key: %q", auth)
+	}
+	if got, _ := runInProcess([]string{"config", "explain"}, ""); strings.Contains(got, "(set)") {
+		t.Errorf("config explain reports a key for the project URL:\n%s", got)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	useTempConfig(t)
+	if err := cfgManager.AddProfile("work", "https://example.com/api/v1"); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := runInProcess([]string{"config", "validate", "--format", "table"}, ""); err != nil || !strings.Contains(out, "is valid") {
+		t.Fatalf("valid config: %q, %v", out, err)
+	}
+
+	cfgManager.SetProfileDefault("work", "format", "yaml")
+	data, _ := os.ReadFile(cfgManager.Path())
+	data = []byte(strings.Replace(string(data), `"profiles": {`, `"colour": "blue", "macros": {"Bad": {"steps": ["list"]}}, "profiles": {`, 1))
+	os.WriteFile(cfgManager.Path(), data, 0600)
+
+	out, err := runInProcess([]string{"config", "validate", "--format", "json"}, "")
+	if err == nil || !strings.Contains(err.Error(), "3 problem(s)") {
+		t.Errorf("error = %v, want 3 problems\n%s", err, out)
+	}
+	for _, want := range []string{"colour: unknown key", "macros.Bad: invalid name", "profiles.work.defaults.format"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -3,11 +3,15 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 
+	"github.com/ashrafali/craft-cli/internal/api"
+	"github.com/ashrafali/craft-cli/internal/config"
+	"github.com/ashrafali/craft-cli/internal/models"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +37,15 @@ const craftLogoAlt = `
     └─────────────┘
 `
 
+var (
+	setupNonInteractive bool
+	setupName           string
+	setupURL            string
+	setupKeyStdin       bool
+	setupImport         string
+	setupNoVerify       bool
+)
+
 var setupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Interactive setup for first-time users",
@@ -43,10 +56,32 @@ Guides you through:
   - Creating your first profile
   - Verifying the connection works
 
-Use --quiet to skip interactive prompts (for scripts).`,
+Before a profile is saved, setup connects to the API and shows the space ID
+and time zone. For provisioning scripts, --non-interactive takes the profile
+from flags and fails instead of saving a profile that cannot connect; the
+API key is read from stdin so it stays out of the process list.
+
+--import loads several profiles from a JSON file (- for stdin) in the format
+written by 'craft config export':
+
+  {"profiles": {"work": {"url": "...", "api_key": "...", "defaults": {"format": "table"}}}}
+
+Every profile is checked before any is saved. Existing profiles with the
+same name are replaced; use 'craft config import' to choose otherwise.
+--no-verify skips the connection check.`,
+	Example: `  craft setup
+  craft setup --non-interactive --name work --url https://connect.craft.do/links/LINK/api/v1
+  pass show craft/work | craft setup --non-interactive --name work --url URL --key-stdin
+  craft setup --import team-profiles.json`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if isQuiet() {
-			return fmt.Errorf("setup requires interactive mode. Remove --quiet flag")
+		switch {
+		case setupImport != "":
+			return importBundle(setupImport, config.ConflictOverwrite, !setupNoVerify)
+		case setupNonInteractive:
+			return runSetupNonInteractive()
+		case isQuiet():
+			return fmt.Errorf("setup requires interactive mode. Remove --quiet or use --non-interactive")
 		}
 		return runSetup()
 	},
@@ -54,6 +89,12 @@ Use --quiet to skip interactive prompts (for scripts).`,
 
 func init() {
 	rootCmd.AddCommand(setupCmd)
+	setupCmd.Flags().BoolVar(&setupNonInteractive, "non-interactive", false, "Create the profile from flags without prompting")
+	setupCmd.Flags().StringVar(&setupName, "name", "default", "Profile name (with --non-interactive)")
+	setupCmd.Flags().StringVar(&setupURL, "url", "", "Craft API URL (with --non-interactive)")
+	setupCmd.Flags().BoolVar(&setupKeyStdin, "key-stdin", false, "Read the API key from stdin (with --non-interactive)")
+	setupCmd.Flags().StringVar(&setupImport, "import", "", "Load profiles from a JSON file (- for stdin)")
+	setupCmd.Flags().BoolVar(&setupNoVerify, "no-verify", false, "Save without checking the connection")
 }
 
 func runSetup() error {
@@ -129,6 +170,19 @@ func runSetup() error {
 		fmt.Printf("  Using name: %s\n", profileName)
 	}
 
+	// Verify before saving
+	fmt.Println()
+	fmt.Println("Checking the connection...")
+	info, err := verifyConnection(apiURL, "")
+	if err != nil {
+		fmt.Printf("  ✗ %v\n\n", err)
+		if !promptYesNo(reader, "Save the profile anyway?", false) {
+			return err
+		}
+	} else {
+		fmt.Printf("  ✓ %s\n", describeConnection(info))
+	}
+
 	// Save profile
 	if err := cfgManager.AddProfile(profileName, apiURL); err != nil {
 		return fmt.Errorf("failed to save profile: %w", err)
@@ -140,6 +194,74 @@ func runSetup() error {
 	return nil
 }
 
+// runSetupNonInteractive saves the profile given by flags once its
+// connection is verified.
+func runSetupNonInteractive() error {
+	if setupURL == "" {
+		return fmt.Errorf("--url is required with --non-interactive")
+	}
+	name := strings.TrimSpace(setupName)
+	if name == "" {
+		return fmt.Errorf("--name cannot be empty")
+	}
+	var key string
+	if setupKeyStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read API key from stdin: %w", err)
+		}
+		if key = strings.TrimSpace(string(data)); key == "" {
+			return fmt.Errorf("no API key on stdin")
+		}
+	}
+
+	info, err := checkSetupProfile(setupURL, key)
+	if err != nil {
+		return err
+	}
+	if err := cfgManager.AddProfileWithKey(name, setupURL, key); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	if info != nil {
+		fmt.Printf("Profile '%s' saved (%s)\n", name, describeConnection(info))
+	} else {
+		fmt.Printf("Profile '%s' saved (not verified)\n", name)
+	}
+	return nil
+}
+
+// checkSetupProfile checks a profile's URL and verifies its connection
+// unless --no-verify is set, in which case it returns no connection info.
+func checkSetupProfile(url, key string) (*models.ConnectionInfo, error) {
+	if setupNoVerify {
+		if problem := craftURLProblem(url); problem != "" {
+			return nil, fmt.Errorf("%s", problem)
+		}
+		return nil, nil
+	}
+	return verifyConnection(url, key)
+}
+
+// verifyConnection checks that url is a Craft API URL and that it answers.
+func verifyConnection(url, key string) (*models.ConnectionInfo, error) {
+	if problem := craftURLProblem(url); problem != "" {
+		return nil, fmt.Errorf("%s", problem)
+	}
+	client := api.NewClient(url)
+	if key != "" {
+		client = api.NewClientWithKey(url, key)
+	}
+	info, err := client.GetConnection()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %w", hostOf(url), err)
+	}
+	return info, nil
+}
+
+func describeConnection(info *models.ConnectionInfo) string {
+	return fmt.Sprintf("space %s, time zone %s", info.Space.ID, info.Space.Timezone)
+}
+
 func printWelcome() {
 	fmt.Println()
 	fmt.Print(craftLogoAlt)
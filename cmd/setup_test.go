@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// connectionServer answers GET /connection for the space, requiring key
+// as the bearer token when it is set.
+func connectionServer(t *testing.T, space, key string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key != "" && r.Header.Get("Authorization") != "Bearer "+key {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/connection" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"space":{"id":"` + space + `","timezone":"Europe/Berlin"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/api/v1"
+}
+
+func TestSetupNonInteractive(t *testing.T) {
+	useTempConfig(t)
+	url := connectionServer(t, "space-1", "pdk_secret")
+
+	out, err := runInProcess([]string{"setup", "--non-interactive", "--name", "work", "--url", url, "--key-stdin"}, "pdk_secret\n")
+	if err != nil || !strings.Contains(out, "space space-1, time zone Europe/Berlin") {
+		t.Fatalf("setup = %q, %v", out, err)
+	}
+	if key, _ := cfgManager.GetActiveAPIKey(); key != "pdk_secret" {
+		t.Errorf("API key = %q", key)
+	}
+
+	// A profile that cannot connect is not saved.
+	if _, err := runInProcess([]string{"setup", "--non-interactive", "--name", "bad", "--url", url}, ""); err == nil {
+		t.Error("expected an error without the API key")
+	}
+	if _, err := runInProcess([]string{"setup", "--non-interactive", "--name", "bad", "--url", "https://example.com/links/x"}, ""); err == nil || !strings.Contains(err.Error(), "/api/v1") {
+		t.Errorf("malformed URL error = %v", err)
+	}
+	out, err = runInProcess([]string{"setup", "--non-interactive", "--name", "offline", "--url", "https://example.com/api/v1", "--no-verify"}, "")
+	if err != nil || !strings.Contains(out, "not verified") {
+		t.Errorf("--no-verify = %q, %v", out, err)
+	}
+	if _, err := runInProcess([]string{"setup", "--non-interactive", "--name", "offline2", "--url", "http://example.com/api/v1", "--no-verify"}, ""); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("--no-verify with a malformed URL error = %v", err)
+	}
+	profiles, _ := cfgManager.ListProfiles()
+	if len(profiles) != 2 {
+		t.Errorf("profiles = %+v, want work and offline", profiles)
+	}
+
+	if _, err := runInProcess([]string{"setup", "--url", url}, ""); err == nil {
+		t.Error("--url without --non-interactive should be rejected")
+	}
+}
+
+func TestSetupImport(t *testing.T) {
+	useTempConfig(t)
+	dir := t.TempDir()
+	team := filepath.Join(dir, "team.json")
+	os.WriteFile(team, []byte(`{"profiles": {
+  "docs": {"url": "`+connectionServer(t, "docs-space", "")+`", "defaults": {"format": "table"}},
+  "eng": {"url": "`+connectionServer(t, "eng-space", "pdk_eng")+`", "api_key": "pdk_eng"}
+}}`), 0600)
+
+	out, err := runInProcess([]string{"setup", "--import", team}, "")
+	if err != nil || !strings.Contains(out, "'docs' imported (space docs-space") || !strings.Contains(out, "'eng' imported (space eng-space") {
+		t.Fatalf("import = %q, %v", out, err)
+	}
+	if defaults, _ := cfgManager.ProfileDefaults("docs"); defaults["format"] != "table" {
+		t.Errorf("docs defaults = %v", defaults)
+	}
+
+	// One bad profile keeps the whole file from being imported.
+	os.WriteFile(team, []byte(`{"profiles": {
+  "new": {"url": "`+connectionServer(t, "new-space", "")+`"},
+  "broken": {"url": "`+connectionServer(t, "x", "")+`", "defaults": {"format": "yaml"}}
+}}`), 0600)
+	if _, err := runInProcess([]string{"setup", "--import", team}, ""); err == nil || !strings.Contains(err.Error(), "broken: invalid format") {
+		t.Errorf("error = %v, want the broken profile reported", err)
+	}
+	if profiles, _ := cfgManager.ListProfiles(); len(profiles) != 2 {
+		t.Errorf("profiles = %+v, want nothing imported", profiles)
+	}
+}
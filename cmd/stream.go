@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+// errStreamLimit stops a stream once --limit results have been written.
+var errStreamLimit = errors.New("stream limit reached")
+
+// isStreaming reports whether --stream (NDJSON output) is active.
+func isStreaming() bool {
+	return streamOutput
+}
+
+// writeNDJSON writes v as a single JSON line. stdout is unbuffered, so each
+// line reaches the consumer as soon as it is written.
+func writeNDJSON(v interface{}) error {
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// ndjsonSink returns a callback that writes each item as an NDJSON line and
+// stops the stream after limit items (0 = no limit).
+func ndjsonSink[T any](limit int) func(T) error {
+	written := 0
+	return func(item T) error {
+		if limit > 0 && written >= limit {
+			return errStreamLimit
+		}
+		written++
+		return writeNDJSON(item)
+	}
+}
+
+// finishStream treats reaching --limit as success.
+func finishStream(err error) error {
+	if errors.Is(err, errStreamLimit) {
+		return nil
+	}
+	return err
+}
+
+// streamBlock is a block without children, annotated with its position in
+// the tree so a flattened stream can be reassembled.
+type streamBlock struct {
+	models.Block
+	ParentID string `json:"parentId,omitempty"`
+	Depth    int    `json:"depth"`
+}
+
+// streamBlockTree writes a block tree depth-first, one block per line,
+// emitting each block before its children.
+func streamBlockTree(block *models.Block, parentID string, depth int) error {
+	line := streamBlock{Block: *block, ParentID: parentID, Depth: depth}
+	line.Content = nil
+	if err := writeNDJSON(line); err != nil {
+		return err
+	}
+	for i := range block.Content {
+		if err := streamBlockTree(&block.Content[i], block.ID, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+func TestNdjsonSinkLimit(t *testing.T) {
+	out := captureStdout(t, func() {
+		sink := ndjsonSink[models.Document](2)
+		var err error
+		for _, id := range []string{"a", "b", "c"} {
+			if err = sink(models.Document{ID: id}); err != nil {
+				break
+			}
+		}
+		if finishStream(err) != nil {
+			t.Errorf("finishStream(%v) should treat the limit as success", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), out)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &doc); err != nil || doc["id"] != "b" {
+		t.Errorf("second line = %q", lines[1])
+	}
+}
+
+func TestStreamBlockTree(t *testing.T) {
+	root := &models.Block{ID: "root", Type: "page", Markdown: "Doc", Content: []models.Block{
+		{ID: "c1", Type: "text", Markdown: "one", Content: []models.Block{{ID: "g1", Type: "text", Markdown: "nested"}}},
+		{ID: "c2", Type: "text", Markdown: "two"},
+	}}
+
+	out := captureStdout(t, func() {
+		if err := streamBlockTree(root, "", 0); err != nil {
+			t.Errorf("streamBlockTree() error = %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d: %q", len(lines), out)
+	}
+	var nested map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[2]), &nested); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if nested["id"] != "g1" || nested["parentId"] != "c1" || nested["depth"] != float64(2) {
+		t.Errorf("nested line = %v", nested)
+	}
+	if _, ok := nested["content"]; ok {
+		t.Error("streamed blocks should not include children")
+	}
+}
+
+func TestGetStreamHonorsMaxDepth(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"id":"d1","type":"page","markdown":"Doc","content":[]}`))
+	}))
+	defer server.Close()
+
+	if _, err := runInProcess([]string{"--api-url", server.URL, "get", "d1", "--stream", "--max-depth", "1"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "maxDepth=1") {
+		t.Errorf("query = %q, want maxDepth=1", query)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/ashrafali/craft-cli/internal/api"
 	"github.com/ashrafali/craft-cli/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -38,21 +39,36 @@ Scopes:
   active    - All active (not done/canceled) tasks
   upcoming  - Tasks with upcoming schedule dates
   inbox     - Tasks in the inbox
-  logbook   - Completed tasks`,
+  logbook   - Completed tasks
+
+Examples:
+  craft tasks list --scope active --profiles work,personal`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if isFanOut() {
+			return runFanOut(func(client *api.Client) ([]models.Task, error) {
+				tasks, err := listTasks(client)
+				if err != nil {
+					return nil, err
+				}
+				return tasks.Items, nil
+			}, fanOutView[models.Task]{
+				Columns: []string{"ID", "STATE", "DESCRIPTION", "SCHEDULE", "DEADLINE"},
+				Row: func(t models.Task) []string {
+					return []string{t.ID, t.State, truncate(t.Markdown, 40), t.ScheduleDate, t.DeadlineDate}
+				},
+			})
+		}
+
 		client, err := getAPIClient()
 		if err != nil {
 			return err
 		}
 
-		var tasks *models.TaskList
-
-		if taskDocumentID != "" {
-			tasks, err = client.GetDocumentTasks(taskDocumentID)
-		} else {
-			tasks, err = client.GetTasks(taskScope)
+		if isStreaming() {
+			return finishStream(client.StreamTasks(taskScope, taskDocumentID, ndjsonSink[models.Task](0)))
 		}
 
+		tasks, err := listTasks(client)
 		if err != nil {
 			return err
 		}
@@ -65,6 +81,14 @@ Scopes:
 	},
 }
 
+// listTasks fetches the tasks selected by the tasks list flags.
+func listTasks(client *api.Client) (*models.TaskList, error) {
+	if taskDocumentID != "" {
+		return client.GetDocumentTasks(taskDocumentID)
+	}
+	return client.GetTasks(taskScope)
+}
+
 var (
 	taskMarkdown     string
 	taskLocation     string
@@ -99,22 +123,25 @@ Examples:
 			taskLocation = "inbox"
 		}
 
+		if isDryRun() {
+			return dryRunOutput("tasks.add", []string{taskDocumentID}, map[string]interface{}{
+				"markdown": description, "location": taskLocation,
+				"schedule_date": taskScheduleDate, "deadline_date": taskDeadlineDate,
+			})
+		}
+
 		task, err := client.AddTask(description, taskLocation, taskDocumentID, taskScheduleDate, taskDeadlineDate)
 		if err != nil {
 			return err
 		}
 
-		if isQuiet() {
-			fmt.Println(task.ID)
+		res := newMutation("tasks.add", taskDocumentID)
+		res.CreatedIDs = []string{task.ID}
+		res.Result = task
+		return outputMutation(res, func() error {
+			fmt.Printf("Task created: %s (ID: %s)\n", task.Markdown, task.ID)
 			return nil
-		}
-
-		format := getOutputFormat()
-		if isJSONFormat(format) {
-			return outputJSON(task)
-		}
-		fmt.Printf("Task created: %s (ID: %s)\n", task.Markdown, task.ID)
-		return nil
+		})
 	},
 }
 
@@ -139,7 +166,9 @@ Examples:
 		}
 
 		if isDryRun() {
-			return dryRunOutput("update task", map[string]interface{}{"id": args[0]})
+			return dryRunOutput("tasks.update", []string{args[0]}, map[string]interface{}{
+				"state": taskState, "schedule_date": taskScheduleDate, "deadline_date": taskDeadlineDate,
+			})
 		}
 
 		client, err := getAPIClient()
@@ -152,22 +181,23 @@ Examples:
 			return err
 		}
 
-		if !isQuiet() {
+		return outputMutation(newMutation("tasks.update", taskID), func() error {
 			fmt.Printf("Task %s updated\n", taskID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
 var tasksDeleteCmd = &cobra.Command{
-	Use:   "delete [task-id]",
-	Short: "Delete a task",
-	Long:  "Delete a task by its ID",
-	Args:  cobra.ExactArgs(1),
+	Use:         "delete [task-id]",
+	Short:       "Delete a task",
+	Long:        "Delete a task by its ID",
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if isDryRun() {
-			return dryRunOutput("delete task", map[string]interface{}{
-				"id": args[0], "destructive": true,
+			return dryRunOutput("tasks.delete", []string{args[0]}, map[string]interface{}{
+				"destructive": true,
 			})
 		}
 
@@ -181,10 +211,12 @@ var tasksDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		if !isQuiet() {
+		res := newMutation("tasks.delete", taskID)
+		res.DeletedIDs = []string{taskID}
+		return outputMutation(res, func() error {
 			fmt.Printf("Task %s deleted\n", taskID)
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
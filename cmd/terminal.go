@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Color modes accepted by --color
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// defaultPager is used when $PAGER is unset. -F quits when the content fits
+// on one screen, -R passes ANSI colors through, -X keeps output on screen.
+const defaultPager = "less -FRX"
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal.
+// It is a variable so tests can override it.
+var stdoutIsTerminal = func() bool {
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a character device (a TTY).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEnabled reports whether ANSI escapes should be written to stdout.
+// --color=always wins over everything, --color=never and NO_COLOR disable
+// colors, and auto enables them only when stdout is a terminal.
+func colorEnabled() bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// stripANSI removes ANSI color/style escape sequences from s.
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// terminalHeight returns the number of rows of the terminal, using $LINES
+// when set and a conservative default otherwise.
+func terminalHeight() int {
+	if lines, err := strconv.Atoi(os.Getenv("LINES")); err == nil && lines > 0 {
+		return lines
+	}
+	return 24
+}
+
+// pagerCommand returns the pager to use, or "" when paging is disabled.
+func pagerCommand() string {
+	if noPager {
+		return ""
+	}
+	if pager, ok := os.LookupEnv("CRAFT_PAGER"); ok {
+		return strings.TrimSpace(pager)
+	}
+	if pager, ok := os.LookupEnv("PAGER"); ok {
+		return strings.TrimSpace(pager)
+	}
+	return defaultPager
+}
+
+// writeTerminal prints human-oriented output. ANSI escapes are stripped when
+// colors are disabled, and content taller than the terminal is sent through
+// the pager when stdout is a TTY. Piped output is never paged. Markdown
+// output goes through here only to be paged: it is the document's source,
+// printed as is so it can be copied from the terminal, and the rich format
+// is its highlighted view.
+func writeTerminal(content string) error {
+	if !colorEnabled() {
+		content = stripANSI(content)
+	}
+
+	if !stdoutIsTerminal() || strings.Count(content, "\n") < terminalHeight() {
+		fmt.Print(content)
+		return nil
+	}
+
+	pager := pagerCommand()
+	if pager == "" || pager == "cat" {
+		fmt.Print(content)
+		return nil
+	}
+
+	started, err := runPager(pager, content)
+	if !started {
+		// Fall back to plain output rather than losing the content
+		fmt.Print(content)
+		return nil
+	}
+	return err
+}
+
+// runPager pipes content into the given pager command. It reports whether
+// the pager started and, if so, the error of a pager that failed or exited
+// non-zero. Quitting early is not a failure: the pager exits zero and the
+// unread content is discarded.
+func runPager(pager, content string) (bool, error) {
+	parts := strings.Fields(pager)
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return false, nil
+	}
+	if err := cmd.Wait(); err != nil {
+		return true, fmt.Errorf("pager %q failed: %w", pager, err)
+	}
+	return true, nil
+}
+
+// hexToRGB parses a #RRGGBB color.
+func hexToRGB(hex string) (r, g, b int, ok bool) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), true
+}
+
+// craftGradientColors maps Craft's named gradient colors to representative hex values.
+var craftGradientColors = map[string]string{
+	"gradient-blue":   "#0064ff",
+	"gradient-orange": "#ff9500",
+	"gradient-red":    "#ff3b30",
+	"gradient-green":  "#34c759",
+	"gradient-purple": "#af52de",
+	"gradient-yellow": "#ffcc00",
+}
+
+// ansiForeground returns a 24-bit foreground escape for a Craft color, or ""
+// when the color cannot be parsed.
+func ansiForeground(color string) string {
+	if mapped, ok := craftGradientColors[strings.ToLower(color)]; ok {
+		color = mapped
+	}
+	r, g, b, ok := hexToRGB(color)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
+// ansiBackground returns a 24-bit background escape for a Craft color, or ""
+// when the color cannot be parsed. A contrasting foreground is included so
+// text stays readable on light and dark backgrounds.
+func ansiBackground(color string) string {
+	if mapped, ok := craftGradientColors[strings.ToLower(color)]; ok {
+		color = mapped
+	}
+	r, g, b, ok := hexToRGB(color)
+	if !ok {
+		return ""
+	}
+	fg := "\033[97m"
+	if (r*299+g*587+b*114)/1000 > 150 {
+		fg = "\033[30m"
+	}
+	return fmt.Sprintf("\033[48;2;%d;%d;%dm%s", r, g, b, fg)
+}
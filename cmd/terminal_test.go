@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	oldMode, oldTTY := colorMode, stdoutIsTerminal
+	defer func() { colorMode, stdoutIsTerminal = oldMode, oldTTY }()
+
+	tests := []struct {
+		name    string
+		mode    string
+		tty     bool
+		noColor bool
+		want    bool
+	}{
+		{"auto tty", ColorAuto, true, false, true},
+		{"auto piped", ColorAuto, false, false, false},
+		{"auto NO_COLOR", ColorAuto, true, true, false},
+		{"always piped", ColorAlways, false, false, true},
+		{"always NO_COLOR", ColorAlways, true, true, true},
+		{"never tty", ColorNever, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colorMode = tt.mode
+			tty := tt.tty
+			stdoutIsTerminal = func() bool { return tty }
+			t.Setenv("TERM", "xterm-256color")
+			if tt.noColor {
+				t.Setenv("NO_COLOR", "1")
+			}
+			if got := colorEnabled(); got != tt.want {
+				t.Errorf("colorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTerminalStripsEscapesWhenPiped(t *testing.T) {
+	oldMode, oldTTY := colorMode, stdoutIsTerminal
+	defer func() { colorMode, stdoutIsTerminal = oldMode, oldTTY }()
+	colorMode = ColorAuto
+	stdoutIsTerminal = func() bool { return false }
+
+	out := captureStdout(t, func() {
+		_ = writeTerminal(colorBold + "Title" + colorReset + "\n")
+	})
+	if out != "Title\n" {
+		t.Errorf("writeTerminal() = %q, want %q", out, "Title\n")
+	}
+}
+
+func TestAnsiColorsFromHex(t *testing.T) {
+	if got := ansiForeground("#ef052a"); got != "\033[38;2;239;5;42m" {
+		t.Errorf("ansiForeground() = %q", got)
+	}
+	if got := ansiBackground("#ffcc00"); !strings.HasPrefix(got, "\033[48;2;255;204;0m") {
+		t.Errorf("ansiBackground() = %q", got)
+	}
+	if got := ansiForeground("not-a-color"); got != "" {
+		t.Errorf("ansiForeground(invalid) = %q, want empty", got)
+	}
+}
+
+func TestHighlightCode(t *testing.T) {
+	out := highlightCode("func main() { return 42 } // done", "go")
+	if !strings.Contains(out, syntaxKeyword+"func"+colorReset) {
+		t.Errorf("keyword not highlighted: %q", out)
+	}
+	if !strings.Contains(out, syntaxNumber+"42"+colorReset) {
+		t.Errorf("number not highlighted: %q", out)
+	}
+	if !strings.Contains(out, syntaxComment+"// done"+colorReset) {
+		t.Errorf("comment not highlighted: %q", out)
+	}
+	if stripANSI(out) != "func main() { return 42 } // done" {
+		t.Errorf("highlighting changed the code: %q", stripANSI(out))
+	}
+}
+
+func TestRunPagerReportsStartAndFailure(t *testing.T) {
+	if started, err := runPager("craft-no-such-pager", "text\n"); started || err != nil {
+		t.Errorf("runPager(missing) = %v, %v; want not started", started, err)
+	}
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("no false command")
+	}
+	// A pager that exits non-zero has already run; its output must not be
+	// printed again, but the failure is reported.
+	started, err := runPager("false", "text\n")
+	if !started || err == nil || !strings.Contains(err.Error(), `pager "false" failed`) {
+		t.Errorf("runPager(false) = %v, %v; want started with an error", started, err)
+	}
+	if _, err := exec.LookPath("true"); err == nil {
+		if started, err := runPager("true", "text\n"); !started || err != nil {
+			t.Errorf("runPager(true) = %v, %v; want started without an error", started, err)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/ashrafali/craft-cli/internal/api"
-	"github.com/ashrafali/craft-cli/internal/models"
 	"github.com/spf13/cobra"
 )
 
@@ -39,7 +38,11 @@ Examples:
   craft update abc123 --mode replace --file content.md
   craft update abc123 --mode replace --section "Overview" --file overview.md
   echo "# Updated" | craft update abc123
-  cat doc.md | craft update abc123 --title "Updated Doc"`,
+  cat doc.md | craft update abc123 --title "Updated Doc"
+
+  # Safe to re-run: an interrupted chunked append resumes from the last
+  # inserted chunk, and a completed update is not applied twice
+  craft update abc123 --file notes.md --idempotency-key notes-2025-06-01`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := getAPIClient()
@@ -53,16 +56,8 @@ Examples:
 		if mode == "" {
 			mode = "append"
 		}
-		switch mode {
-		case "append", "replace":
-		default:
-			return fmt.Errorf("invalid --mode %q (expected append or replace)", mode)
-		}
 
 		if updateStdin {
-			if updateFile != "" {
-				return fmt.Errorf("--stdin cannot be used with --file")
-			}
 			updateFile = "-"
 		}
 
@@ -72,24 +67,20 @@ Examples:
 			return err
 		}
 
-		if updateSection != "" && mode != "replace" {
-			return fmt.Errorf("--section requires --mode replace")
-		}
-
 		if updateTitle == "" && strings.TrimSpace(content) == "" && updateSection == "" {
 			return fmt.Errorf("at least one of --title, --file, --markdown, or --section is required")
 		}
 
 		// Dry run mode
 		if isDryRun() {
-			fmt.Printf("Would update document %s:\n", docID)
+			details := map[string]interface{}{}
 			if updateTitle != "" {
-				fmt.Printf("  New title: %s\n", updateTitle)
+				details["new_title"] = updateTitle
 			}
 			if strings.TrimSpace(content) != "" || updateSection != "" {
-				fmt.Printf("  Mode: %s\n", mode)
+				details["mode"] = mode
 				if updateSection != "" {
-					fmt.Printf("  Section: %s\n", updateSection)
+					details["section"] = updateSection
 				}
 				chunkBytes := updateChunkBytes
 				if chunkBytes <= 0 {
@@ -109,8 +100,8 @@ Examples:
 				}
 				if strings.TrimSpace(planned) != "" {
 					chunks := api.SplitMarkdownIntoChunks(planned, chunkBytes)
-					fmt.Printf("  Chunk bytes: %d\n", chunkBytes)
-					fmt.Printf("  Chunks: %d\n", len(chunks))
+					details["chunk_bytes"] = chunkBytes
+					details["chunks"] = len(chunks)
 				}
 
 				preview := content
@@ -118,10 +109,10 @@ Examples:
 					preview = preview[:100] + "..."
 				}
 				if strings.TrimSpace(preview) != "" {
-					fmt.Printf("  Content preview: %s\n", preview)
+					details["content_preview"] = preview
 				}
 			}
-			return nil
+			return dryRunOutput("documents.update", []string{docID}, details)
 		}
 
 		chunkBytes := updateChunkBytes
@@ -129,6 +120,10 @@ Examples:
 			chunkBytes = 30000
 		}
 
+		if idempotencyKey != "" {
+			return runIdempotentUpdate(client, docID, mode, content, chunkBytes)
+		}
+
 		// Title update (root page block)
 		if updateTitle != "" {
 			if err := client.UpdateBlockMarkdown(docID, updateTitle); err != nil {
@@ -163,7 +158,14 @@ Examples:
 			}
 		}
 
-		return outputCreated(&models.Document{ID: docID, Title: updateTitle}, getOutputFormat())
+		res := newMutation("documents.update", docID)
+		if mode == "replace" && strings.TrimSpace(finalContent) != "" {
+			res.Details = map[string]interface{}{"mode": mode}
+		}
+		return outputMutation(res, func() error {
+			fmt.Printf("Document %s updated\n", docID)
+			return nil
+		})
 	},
 }
 
@@ -176,4 +178,101 @@ func init() {
 	updateCmd.Flags().StringVar(&updateMode, "mode", "append", "Update mode (append, replace)")
 	updateCmd.Flags().StringVar(&updateSection, "section", "", "Replace a section by heading (requires --mode replace)")
 	updateCmd.Flags().IntVar(&updateChunkBytes, "chunk-bytes", 30000, "Max bytes per insert chunk (helps avoid API payload limits)")
+	updateCmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "Key that makes re-runs resume or replay this update instead of repeating it")
+}
+
+// runIdempotentUpdate applies the update under idempotencyKey, recording each
+// step (title, clear, every appended chunk) so a re-run skips what already
+// succeeded. A re-run after success replays the original result.
+func runIdempotentUpdate(client *api.Client, docID, mode, content string, chunkBytes int) error {
+	ledger, err := loadIdempotencyLedger(idempotencyPath(), client.BaseURL())
+	if err != nil {
+		return err
+	}
+	entry, err := ledger.begin(idempotencyKey, "documents.update", requestFingerprint(map[string]interface{}{
+		"document":    docID,
+		"title":       updateTitle,
+		"mode":        mode,
+		"section":     updateSection,
+		"content":     content,
+		"chunk_bytes": chunkBytes,
+	}))
+	if err != nil {
+		return err
+	}
+
+	human := func() error {
+		fmt.Printf("Document %s updated\n", docID)
+		return nil
+	}
+	if entry.Complete {
+		res, err := entry.replayResult()
+		if err != nil {
+			return err
+		}
+		return outputMutation(res, human)
+	}
+	resumed := entry.started()
+
+	if updateTitle != "" && !entry.TitleDone {
+		if err := client.UpdateBlockMarkdown(docID, updateTitle); err != nil {
+			return err
+		}
+		if err := ledger.step(entry, func(e *idempotencyEntry) { e.TitleDone = true }); err != nil {
+			return err
+		}
+	}
+
+	finalContent := content
+	if updateSection != "" {
+		// The replacement is computed once from the original document; a
+		// resumed run must not recompute it from the partly rewritten one.
+		if entry.Pending == "" {
+			existing, err := client.GetDocumentContentMarkdown(docID)
+			if err != nil {
+				return err
+			}
+			updated, err := replaceSectionByHeading(existing, updateSection, content)
+			if err != nil {
+				return err
+			}
+			if err := ledger.step(entry, func(e *idempotencyEntry) { e.Pending = updated }); err != nil {
+				return err
+			}
+		}
+		finalContent = entry.Pending
+	}
+
+	if strings.TrimSpace(finalContent) != "" {
+		if mode == "replace" && !entry.Cleared {
+			if _, err := client.ClearDocumentContent(docID); err != nil {
+				return resumeHint(err, idempotencyKey)
+			}
+			if err := ledger.step(entry, func(e *idempotencyEntry) { e.Cleared = true }); err != nil {
+				return err
+			}
+		}
+		_, err := client.ResumeAppendMarkdown(docID, finalContent, chunkBytes, entry.ChunksDone, func(done int) error {
+			return ledger.step(entry, func(e *idempotencyEntry) { e.ChunksDone = done })
+		})
+		if err != nil {
+			return resumeHint(err, idempotencyKey)
+		}
+	}
+
+	res := newMutation("documents.update", docID)
+	res.Details = map[string]interface{}{}
+	if mode == "replace" && strings.TrimSpace(finalContent) != "" {
+		res.Details["mode"] = mode
+	}
+	if resumed {
+		res.Details["resumed"] = true
+	}
+	if len(res.Details) == 0 {
+		res.Details = nil
+	}
+	if err := ledger.complete(idempotencyKey, res); err != nil {
+		return err
+	}
+	return outputMutation(res, human)
 }
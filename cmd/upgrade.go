@@ -137,6 +137,29 @@ func saveUpdateCache(cache updateCheckCache) error {
 	return os.WriteFile(cachePath, data, 0644)
 }
 
+// checkLatestRelease looks up the newest release without the update cache
+// and reports whether it is newer than this build. Tests replace it.
+var checkLatestRelease = func(ctx context.Context) (latestVersion string, newer bool, err error) {
+	source, err := selfupdate.NewGitHubSource(selfupdate.GitHubConfig{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create update source: %w", err)
+	}
+
+	updater, err := selfupdate.NewUpdater(selfupdate.Config{Source: source})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create updater: %w", err)
+	}
+
+	latest, found, err := updater.DetectLatest(ctx, selfupdate.NewRepositorySlug(repoOwner, repoName))
+	if err != nil {
+		return "", false, err
+	}
+	if !found {
+		return "", false, fmt.Errorf("no releases found")
+	}
+	return latest.Version(), latest.GreaterThan(version), nil
+}
+
 var upgradeCmd = &cobra.Command{
 	Use:   "upgrade",
 	Short: "Upgrade craft-cli to the latest version",
@@ -74,20 +74,13 @@ Examples:
 
 		// Dry run mode
 		if isDryRun() {
-			fmt.Println("Would upload file:")
-			fmt.Printf("  File: %s\n", fileName)
-			fmt.Printf("  Size: %d bytes\n", len(fileData))
-			if uploadPageID != "" {
-				fmt.Printf("  Page: %s\n", uploadPageID)
+			details := map[string]interface{}{
+				"file": fileName, "size_bytes": len(fileData), "position": uploadPosition,
 			}
 			if uploadDate != "" {
-				fmt.Printf("  Date: %s\n", uploadDate)
+				details["date"] = uploadDate
 			}
-			if uploadSiblingID != "" {
-				fmt.Printf("  Sibling: %s\n", uploadSiblingID)
-			}
-			fmt.Printf("  Position: %s\n", uploadPosition)
-			return nil
+			return dryRunOutput("files.upload", []string{uploadTarget()}, details)
 		}
 
 		client, err := getAPIClient()
@@ -100,24 +93,28 @@ Examples:
 			return err
 		}
 
-		// Output based on mode
-		if isQuiet() {
-			fmt.Println(result.BlockID)
+		res := newMutation("files.upload", uploadTarget())
+		res.CreatedIDs = []string{result.BlockID}
+		res.Result = result
+		return outputMutation(res, func() error {
+			fmt.Printf("Block ID:  %s\n", result.BlockID)
+			fmt.Printf("Asset URL: %s\n", result.AssetURL)
 			return nil
-		}
-
-		format := getOutputFormat()
-		if isJSONFormat(format) {
-			return outputJSON(result)
-		}
-
-		// Default text output
-		fmt.Printf("Block ID:  %s\n", result.BlockID)
-		fmt.Printf("Asset URL: %s\n", result.AssetURL)
-		return nil
+		})
 	},
 }
 
+// uploadTarget returns the sibling, page, or daily note date an upload is placed at.
+func uploadTarget() string {
+	switch {
+	case uploadSiblingID != "":
+		return uploadSiblingID
+	case uploadPageID != "":
+		return uploadPageID
+	}
+	return uploadDate
+}
+
 func init() {
 	rootCmd.AddCommand(uploadCmd)
 	uploadCmd.Flags().StringVar(&uploadPageID, "page", "", "Target page ID for placement")
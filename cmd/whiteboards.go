@@ -39,9 +39,7 @@ Examples:
 			return err
 		}
 		if isDryRun() {
-			return dryRunOutput("create whiteboard", map[string]interface{}{
-				"page_id": args[0],
-			})
+			return dryRunOutput("whiteboards.create", []string{args[0]}, nil)
 		}
 
 		client, err := getAPIClient()
@@ -54,7 +52,14 @@ Examples:
 			return err
 		}
 
-		return outputJSON(result)
+		res := newMutation("whiteboards.create", args[0])
+		if id := firstStringField(result, "whiteboardId", "blockId", "id"); id != "" {
+			res.CreatedIDs = []string{id}
+		}
+		res.Result = result
+		return outputMutation(res, func() error {
+			return outputJSON(result)
+		})
 	},
 }
 
@@ -116,9 +121,7 @@ Examples:
 		}
 
 		if isDryRun() {
-			return dryRunOutput("add whiteboard elements", map[string]interface{}{
-				"whiteboard_id": args[0],
-			})
+			return dryRunOutput("whiteboards.add_elements", []string{args[0]}, nil)
 		}
 
 		var elements []map[string]interface{}
@@ -141,7 +144,20 @@ Examples:
 			return err
 		}
 
-		return outputJSON(result)
+		res := newMutation("whiteboards.add_elements", args[0])
+		if created, ok := result["elements"].([]interface{}); ok {
+			for _, el := range created {
+				if m, ok := el.(map[string]interface{}); ok {
+					if id := firstStringField(m, "id"); id != "" {
+						res.CreatedIDs = append(res.CreatedIDs, id)
+					}
+				}
+			}
+		}
+		res.Result = result
+		return outputMutation(res, func() error {
+			return outputJSON(result)
+		})
 	},
 }
 
@@ -170,9 +186,7 @@ Examples:
 		}
 
 		if isDryRun() {
-			return dryRunOutput("update whiteboard elements", map[string]interface{}{
-				"whiteboard_id": args[0],
-			})
+			return dryRunOutput("whiteboards.update_elements", []string{args[0]}, nil)
 		}
 
 		var elements []map[string]interface{}
@@ -193,10 +207,12 @@ Examples:
 			return err
 		}
 
-		if !quietMode {
+		res := newMutation("whiteboards.update_elements", args[0])
+		res.Details = map[string]interface{}{"count": len(elements)}
+		return outputMutation(res, func() error {
 			fmt.Println("Whiteboard elements updated successfully")
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
@@ -208,7 +224,8 @@ var whiteboardDeleteCmd = &cobra.Command{
 Examples:
   craft whiteboards delete WB_ID --ids "elem1,elem2"
   craft whiteboards delete WB_ID --ids "elem1" --dry-run`,
-	Args: cobra.ExactArgs(1),
+	Annotations: map[string]string{safetyAnnotation: safetyDestructive},
+	Args:        cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if whiteboardIDs == "" {
 			return fmt.Errorf("--ids is required: comma-separated element IDs to delete")
@@ -220,8 +237,8 @@ Examples:
 		}
 
 		if isDryRun() {
-			return dryRunOutput("delete whiteboard elements", map[string]interface{}{
-				"whiteboard_id": args[0], "element_ids": ids, "count": len(ids),
+			return dryRunOutput("whiteboards.delete_elements", []string{args[0]}, map[string]interface{}{
+				"element_ids": ids, "count": len(ids),
 			})
 		}
 
@@ -234,10 +251,12 @@ Examples:
 			return err
 		}
 
-		if !quietMode {
+		res := newMutation("whiteboards.delete_elements", args[0])
+		res.DeletedIDs = ids
+		return outputMutation(res, func() error {
 			fmt.Printf("Deleted %d elements from whiteboard\n", len(ids))
-		}
-		return nil
+			return nil
+		})
 	},
 }
 
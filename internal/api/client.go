@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ashrafali/craft-cli/internal/models"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -27,6 +29,8 @@ type APIError struct {
 	Err        string
 	Message    string
 	RawBody    string
+	// RetryAfter is the wait requested by the server's Retry-After header.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -48,6 +52,108 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	limiter    *rate.Limiter
+	guard      func(Mutation) error
+	observers  []func(Outcome)
+	retries    int
+	chunkBytes int
+	folder     string
+}
+
+// Mutation describes a request that changes data, as passed to a guard.
+type Mutation struct {
+	Method string
+	Path   string // relative to the base URL, without the query
+	Query  url.Values
+	Body   []byte
+	// Folder is the folder a new document will be moved into, as declared
+	// with InFolder; the API request itself cannot carry it.
+	Folder string
+}
+
+// Outcome is a finished mutating request, as passed to an observer.
+type Outcome struct {
+	Mutation
+	Status   int    // HTTP status; 0 if the request was never answered
+	Response []byte // response body, on success
+	Err      error
+}
+
+// AddObserver installs a callback that runs after every mutating request,
+// including ones stopped by the guard.
+func (c *Client) AddObserver(observer func(Outcome)) {
+	c.observers = append(c.observers, observer)
+}
+
+// SetGuard installs a check that runs before every mutating (non-GET)
+// request; an error from it stops the request from being sent.
+func (c *Client) SetGuard(guard func(Mutation) error) {
+	c.guard = guard
+}
+
+// BaseURL returns the API URL the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// InFolder returns a client whose mutations declare folder as the
+// destination of the documents they create, so a guard can check a create
+// that is followed by a move. It shares everything else with c.
+func (c *Client) InFolder(folder string) *Client {
+	if folder == "" {
+		return c
+	}
+	scoped := *c
+	scoped.folder = folder
+	return &scoped
+}
+
+// SetTimeout sets the timeout of each request. Zero or less keeps the default.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// SetRetries makes the client retry a request up to n times, with
+// exponential backoff, when it was rate limited (429) or, for GET requests,
+// when it failed with a server or network error. Other mutations are never
+// retried, since they may already have been applied.
+func (c *Client) SetRetries(n int) {
+	c.retries = n
+}
+
+// SetChunkBytes sets the insert chunk size used when a caller does not give
+// one. Zero or less restores the default.
+func (c *Client) SetChunkBytes(n int) {
+	c.chunkBytes = n
+}
+
+func (c *Client) insertChunkBytes() int {
+	if c.chunkBytes > 0 {
+		return c.chunkBytes
+	}
+	return defaultInsertChunkBytes
+}
+
+// retryBaseDelay is the wait before the first retry; it doubles each time.
+var retryBaseDelay = 500 * time.Millisecond
+
+// maxRetryDelay caps the wait between retries, including Retry-After.
+const maxRetryDelay = 30 * time.Second
+
+// SetRateLimit limits the client to perSecond requests per second, shared
+// by all goroutines using it. Zero or less removes the limit.
+func (c *Client) SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		c.limiter = nil
+		return
+	}
+	burst := int(perSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiter = rate.NewLimiter(rate.Limit(perSecond), burst)
 }
 
 // NewClient creates a new API client
@@ -73,6 +179,16 @@ func NewClientWithKey(baseURL, apiKey string) *Client {
 
 // doRequest performs an HTTP request and handles errors
 func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error) {
+	req, err := c.newJSONRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.exchange(req)
+}
+
+// newJSONRequest builds a request with an optional JSON body and auth header.
+func (c *Client) newJSONRequest(method, path string, body interface{}) (*http.Request, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -97,22 +213,148 @@ func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return req, nil
+}
+
+// exchange sends req and returns the response body. Mutating requests are
+// checked by the guard first and reported to the observer afterwards.
+func (c *Client) exchange(req *http.Request) ([]byte, error) {
+	if req.Method == http.MethodGet || (c.guard == nil && len(c.observers) == 0) {
+		_, body, err := c.roundTrip(req)
+		return body, err
+	}
+
+	m, err := c.mutation(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
+	}
+	if c.guard != nil {
+		if err := c.guard(m); err != nil {
+			c.observe(Outcome{Mutation: m, Err: err})
+			return nil, err
+		}
+	}
+	status, body, err := c.roundTrip(req)
+	c.observe(Outcome{Mutation: m, Status: status, Response: body, Err: err})
+	return body, err
+}
+
+// roundTrip sends req and reads the response body, returning the HTTP status.
+func (c *Client) roundTrip(req *http.Request) (int, []byte, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return apiErr.StatusCode, nil, err
+		}
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+func (c *Client) observe(o Outcome) {
+	for _, observer := range c.observers {
+		observer(o)
+	}
+}
+
+// send executes a request. Error statuses are converted to *APIError and the
+// body is closed; on success the caller owns resp.Body.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+			req.Body = body
+		}
+		resp, err := c.sendOnce(req)
+		delay, retry := c.retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(delay)
+	}
+}
+
+// retryDelay reports whether a failed attempt should be retried, and after how long.
+func (c *Client) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.retries || (req.Body != nil && req.GetBody == nil) {
+		return 0, false
+	}
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+	case req.Method == http.MethodGet && errors.As(err, &apiErr) && apiErr.StatusCode >= 500:
+	case req.Method == http.MethodGet && err != nil && !errors.As(err, &apiErr):
+	default:
+		return 0, false
+	}
+	delay := retryBaseDelay << attempt
+	if apiErr != nil && apiErr.RetryAfter > 0 {
+		delay = apiErr.RetryAfter
+	}
+	return min(delay, maxRetryDelay), true
+}
+
+// sendOnce executes one attempt of a request.
+func (c *Client) sendOnce(req *http.Request) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("rate limit wait: %w", err)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, c.handleErrorResponse(resp.StatusCode, respBody)
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		err = c.handleErrorResponse(resp.StatusCode, respBody)
+		if apiErr, ok := err.(*APIError); ok {
+			if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+				apiErr.RetryAfter = time.Duration(secs) * time.Second
+			}
+		}
+		return nil, err
 	}
 
-	return respBody, nil
+	return resp, nil
+}
+
+// mutation describes req for the guard and observer.
+func (c *Client) mutation(req *http.Request) (Mutation, error) {
+	m := Mutation{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query(), Folder: c.folder}
+	if base, err := url.Parse(c.baseURL); err == nil {
+		m.Path = strings.TrimPrefix(m.Path, strings.TrimRight(base.Path, "/"))
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return m, fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer body.Close()
+		if m.Body, err = io.ReadAll(body); err != nil {
+			return m, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	return m, nil
 }
 
 // handleErrorResponse converts HTTP errors to user-friendly messages
@@ -365,7 +607,7 @@ func (c *Client) CreateDocument(req *models.CreateDocumentRequest) (*models.Docu
 		content = req.Content
 	}
 	if strings.TrimSpace(content) != "" {
-		_, err := c.AppendMarkdown(doc.ID, content, defaultInsertChunkBytes)
+		_, err := c.AppendMarkdown(doc.ID, content, c.insertChunkBytes())
 		if err != nil {
 			return nil, err
 		}
@@ -414,7 +656,7 @@ func (c *Client) UpdateDocument(id string, req *models.UpdateDocumentRequest) (*
 		return &models.Document{ID: id, Title: req.Title}, nil
 	}
 
-	lastInserted, err := c.AppendMarkdown(id, req.Markdown, defaultInsertChunkBytes)
+	lastInserted, err := c.AppendMarkdown(id, req.Markdown, c.insertChunkBytes())
 	if err != nil {
 		return nil, err
 	}
@@ -434,11 +676,14 @@ type deleteBlocksResponse struct {
 	} `json:"items"`
 }
 
+// deleteDocumentsRequest is the request body for deleting documents
+type deleteDocumentsRequest struct {
+	DocumentIDs []string `json:"documentIds"`
+}
+
 // DeleteDocument soft-deletes a document by moving it to trash.
 func (c *Client) DeleteDocument(id string) error {
-	req := struct {
-		DocumentIDs []string `json:"documentIds"`
-	}{
+	req := deleteDocumentsRequest{
 		DocumentIDs: []string{id},
 	}
 
@@ -447,10 +692,11 @@ func (c *Client) DeleteDocument(id string) error {
 }
 
 // ClearDocumentContent deletes all content blocks within a document (does not delete the document itself).
-func (c *Client) ClearDocumentContent(id string) (int, error) {
+// It returns the IDs of the deleted blocks.
+func (c *Client) ClearDocumentContent(id string) ([]string, error) {
 	blocksResp, err := c.GetDocumentBlocks(id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get document blocks: %w", err)
+		return nil, fmt.Errorf("failed to get document blocks: %w", err)
 	}
 
 	var blockIDs []string
@@ -459,30 +705,33 @@ func (c *Client) ClearDocumentContent(id string) (int, error) {
 	}
 
 	if len(blockIDs) == 0 {
-		return 0, nil
+		return []string{}, nil
 	}
 
 	deleteReq := deleteBlocksRequest{BlockIDs: blockIDs}
 	_, err = c.doRequest("DELETE", "/blocks", deleteReq)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete blocks: %w", err)
+		return nil, fmt.Errorf("failed to delete blocks: %w", err)
 	}
 
-	return len(blockIDs), nil
+	return blockIDs, nil
+}
+
+// blockMarkdownUpdate sets the markdown of one block
+type blockMarkdownUpdate struct {
+	ID       string `json:"id"`
+	Markdown string `json:"markdown"`
+}
+
+// updateBlocksMarkdownRequest is the request body for updating block markdown
+type updateBlocksMarkdownRequest struct {
+	Blocks []blockMarkdownUpdate `json:"blocks"`
 }
 
 // UpdateBlockMarkdown updates a block (including the document root page) using PUT /blocks.
 func (c *Client) UpdateBlockMarkdown(blockID, markdown string) error {
-	req := struct {
-		Blocks []struct {
-			ID       string `json:"id"`
-			Markdown string `json:"markdown"`
-		} `json:"blocks"`
-	}{
-		Blocks: []struct {
-			ID       string `json:"id"`
-			Markdown string `json:"markdown"`
-		}{{ID: blockID, Markdown: markdown}},
+	req := updateBlocksMarkdownRequest{
+		Blocks: []blockMarkdownUpdate{{ID: blockID, Markdown: markdown}},
 	}
 
 	_, err := c.doRequest("PUT", "/blocks", req)
@@ -492,16 +741,28 @@ func (c *Client) UpdateBlockMarkdown(blockID, markdown string) error {
 // AppendMarkdown appends markdown to a document by inserting blocks at the end.
 // It automatically chunks large markdown to avoid API payload limits.
 func (c *Client) AppendMarkdown(docID, markdown string, chunkBytes int) (string, error) {
+	return c.ResumeAppendMarkdown(docID, markdown, chunkBytes, 0, nil)
+}
+
+// ResumeAppendMarkdown appends markdown like AppendMarkdown, skipping the first
+// skip chunks (already inserted by an earlier attempt with the same markdown
+// and chunkBytes). After each chunk is inserted, progress (if non-nil) is
+// called with the number of chunks done so far; an error from it stops the append.
+func (c *Client) ResumeAppendMarkdown(docID, markdown string, chunkBytes, skip int, progress func(done int) error) (string, error) {
 	if strings.TrimSpace(markdown) == "" {
 		return "", nil
 	}
 	if chunkBytes <= 0 {
-		chunkBytes = defaultInsertChunkBytes
+		chunkBytes = c.insertChunkBytes()
 	}
 
 	chunks := SplitMarkdownIntoChunks(markdown, chunkBytes)
+	if skip > len(chunks) {
+		return "", fmt.Errorf("cannot resume at chunk %d of %d", skip, len(chunks))
+	}
 	var last string
-	for _, chunk := range chunks {
+	for i := skip; i < len(chunks); i++ {
+		chunk := chunks[i]
 		if strings.TrimSpace(chunk) == "" {
 			continue
 		}
@@ -523,6 +784,11 @@ func (c *Client) AppendMarkdown(docID, markdown string, chunkBytes int) (string,
 		if len(resp.Items) > 0 {
 			last = resp.Items[len(resp.Items)-1].Markdown
 		}
+		if progress != nil {
+			if err := progress(i + 1); err != nil {
+				return last, err
+			}
+		}
 	}
 
 	return last, nil
@@ -1035,21 +1301,41 @@ func (c *Client) GetCollectionItems(collectionID string, maxDepth int) (*models.
 	return &result, nil
 }
 
+// collectionItemInput is a new collection item
+type collectionItemInput struct {
+	Title      string                 `json:"title"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// addCollectionItemsRequest is the request body for adding collection items
+type addCollectionItemsRequest struct {
+	Items                 []collectionItemInput `json:"items"`
+	AllowNewSelectOptions bool                  `json:"allowNewSelectOptions"`
+}
+
+// collectionItemUpdate sets properties on an existing collection item
+type collectionItemUpdate struct {
+	ID         string                 `json:"id"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// updateCollectionItemsRequest is the request body for updating collection items
+type updateCollectionItemsRequest struct {
+	ItemsToUpdate         []collectionItemUpdate `json:"itemsToUpdate"`
+	AllowNewSelectOptions bool                   `json:"allowNewSelectOptions"`
+}
+
+// deleteCollectionItemsRequest is the request body for deleting collection items
+type deleteCollectionItemsRequest struct {
+	IDsToDelete []string `json:"idsToDelete"`
+}
+
 // AddCollectionItem adds an item to a collection
 func (c *Client) AddCollectionItem(collectionID, title string, properties map[string]interface{}, allowNewOptions bool) (*models.CollectionItemList, error) {
 	path := fmt.Sprintf("/collections/%s/items", url.PathEscape(collectionID))
 
-	req := struct {
-		Items []struct {
-			Title      string                 `json:"title"`
-			Properties map[string]interface{} `json:"properties,omitempty"`
-		} `json:"items"`
-		AllowNewSelectOptions bool `json:"allowNewSelectOptions"`
-	}{
-		Items: []struct {
-			Title      string                 `json:"title"`
-			Properties map[string]interface{} `json:"properties,omitempty"`
-		}{{Title: title, Properties: properties}},
+	req := addCollectionItemsRequest{
+		Items:                 []collectionItemInput{{Title: title, Properties: properties}},
 		AllowNewSelectOptions: allowNewOptions,
 	}
 
@@ -1070,17 +1356,8 @@ func (c *Client) AddCollectionItem(collectionID, title string, properties map[st
 func (c *Client) UpdateCollectionItem(collectionID, itemID string, properties map[string]interface{}, allowNewOptions bool) error {
 	path := fmt.Sprintf("/collections/%s/items", url.PathEscape(collectionID))
 
-	req := struct {
-		ItemsToUpdate []struct {
-			ID         string                 `json:"id"`
-			Properties map[string]interface{} `json:"properties,omitempty"`
-		} `json:"itemsToUpdate"`
-		AllowNewSelectOptions bool `json:"allowNewSelectOptions"`
-	}{
-		ItemsToUpdate: []struct {
-			ID         string                 `json:"id"`
-			Properties map[string]interface{} `json:"properties,omitempty"`
-		}{{ID: itemID, Properties: properties}},
+	req := updateCollectionItemsRequest{
+		ItemsToUpdate:         []collectionItemUpdate{{ID: itemID, Properties: properties}},
 		AllowNewSelectOptions: allowNewOptions,
 	}
 
@@ -1092,9 +1369,7 @@ func (c *Client) UpdateCollectionItem(collectionID, itemID string, properties ma
 func (c *Client) DeleteCollectionItem(collectionID, itemID string) error {
 	path := fmt.Sprintf("/collections/%s/items", url.PathEscape(collectionID))
 
-	req := struct {
-		IDsToDelete []string `json:"idsToDelete"`
-	}{
+	req := deleteCollectionItemsRequest{
 		IDsToDelete: []string{itemID},
 	}
 
@@ -1198,6 +1473,21 @@ type SearchOptions struct {
 
 // SearchDocumentsAdvanced searches for documents with full option support.
 func (c *Client) SearchDocumentsAdvanced(query string, opts SearchOptions) (*models.SearchResult, error) {
+	data, err := c.doRequest("GET", searchPath(query, opts), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.SearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid response from API: %w", err)
+	}
+
+	return &result, nil
+}
+
+// searchPath builds the /documents/search path with query parameters.
+func searchPath(query string, opts SearchOptions) string {
 	params := url.Values{}
 	params.Set("include", query)
 
@@ -1235,19 +1525,7 @@ func (c *Client) SearchDocumentsAdvanced(query string, opts SearchOptions) (*mod
 		params.Set("dailyNoteDateLte", opts.DailyNoteDateLte)
 	}
 
-	path := "/documents/search?" + params.Encode()
-
-	data, err := c.doRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var result models.SearchResult
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("invalid response from API: %w", err)
-	}
-
-	return &result, nil
+	return "/documents/search?" + params.Encode()
 }
 
 // ========== Advanced Document Listing ==========
@@ -1267,6 +1545,21 @@ type ListDocumentsOptions struct {
 
 // GetDocumentsAdvanced retrieves documents with full option support.
 func (c *Client) GetDocumentsAdvanced(opts ListDocumentsOptions) (*models.DocumentList, error) {
+	data, err := c.doRequest("GET", documentsPath(opts), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.DocumentList
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid response from API: %w", err)
+	}
+
+	return &result, nil
+}
+
+// documentsPath builds the /documents path with query parameters for opts.
+func documentsPath(opts ListDocumentsOptions) string {
 	params := url.Values{}
 
 	if opts.FolderID != "" {
@@ -1301,18 +1594,7 @@ func (c *Client) GetDocumentsAdvanced(opts ListDocumentsOptions) (*models.Docume
 	if encoded := params.Encode(); encoded != "" {
 		path = path + "?" + encoded
 	}
-
-	data, err := c.doRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var result models.DocumentList
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("invalid response from API: %w", err)
-	}
-
-	return &result, nil
+	return path
 }
 
 // ========== Block by Date ==========
@@ -1406,22 +1688,7 @@ func (c *Client) doRequestRaw(method, path string, body []byte, contentType stri
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, c.handleErrorResponse(resp.StatusCode, respBody)
-	}
-
-	return respBody, nil
+	return c.exchange(req)
 }
 
 // UploadFile uploads a file as raw binary data.
@@ -1461,13 +1728,29 @@ func (c *Client) UploadFile(fileData []byte, pageID, date, siblingID, position s
 
 // ========== JSON Block Operations ==========
 
+// addBlocksJSONRequest is the request body for adding styled blocks
+type addBlocksJSONRequest struct {
+	Blocks   []map[string]interface{} `json:"blocks"`
+	Position map[string]interface{}   `json:"position"`
+}
+
+// blockItemsResponse is the response from adding styled blocks
+type blockItemsResponse struct {
+	Items []models.Block `json:"items"`
+}
+
+// updateBlocksJSONRequest is the request body for updating styled blocks
+type updateBlocksJSONRequest struct {
+	Blocks []map[string]interface{} `json:"blocks"`
+}
+
 // AddBlocksJSON adds blocks using raw JSON maps for full styling support.
 // blocks is an array of block maps (type, markdown, textStyle, color, etc.).
 // position specifies where to insert (pageId+position, siblingId+position, or date+position).
 func (c *Client) AddBlocksJSON(blocks []map[string]interface{}, position map[string]interface{}) ([]models.Block, error) {
-	req := map[string]interface{}{
-		"blocks":   blocks,
-		"position": position,
+	req := addBlocksJSONRequest{
+		Blocks:   blocks,
+		Position: position,
 	}
 
 	data, err := c.doRequest("POST", "/blocks", req)
@@ -1475,9 +1758,7 @@ func (c *Client) AddBlocksJSON(blocks []map[string]interface{}, position map[str
 		return nil, err
 	}
 
-	var resp struct {
-		Items []models.Block `json:"items"`
-	}
+	var resp blockItemsResponse
 	if err := json.Unmarshal(data, &resp); err != nil {
 		return nil, fmt.Errorf("invalid response from API: %w", err)
 	}
@@ -1488,8 +1769,8 @@ func (c *Client) AddBlocksJSON(blocks []map[string]interface{}, position map[str
 // UpdateBlocksJSON updates blocks using raw JSON maps for full styling support.
 // Each block map must include "id" and any fields to update.
 func (c *Client) UpdateBlocksJSON(blocks []map[string]interface{}) error {
-	req := map[string]interface{}{
-		"blocks": blocks,
+	req := updateBlocksJSONRequest{
+		Blocks: blocks,
 	}
 
 	_, err := c.doRequest("PUT", "/blocks", req)
@@ -1526,13 +1807,25 @@ func (c *Client) GetBlockWithOptions(blockID string, maxDepth int, fetchMetadata
 
 // ========== Whiteboards ==========
 
+// createWhiteboardRequest is the request body for creating a whiteboard
+type createWhiteboardRequest struct {
+	Position blockPosition `json:"position"`
+}
+
+// whiteboardElementsRequest is the request body for adding or updating whiteboard elements
+type whiteboardElementsRequest struct {
+	Elements []map[string]interface{} `json:"elements"`
+}
+
+// deleteWhiteboardElementsRequest is the request body for deleting whiteboard elements
+type deleteWhiteboardElementsRequest struct {
+	ElementIDs []string `json:"elementIds"`
+}
+
 // CreateWhiteboard creates a new whiteboard block inside a page.
 func (c *Client) CreateWhiteboard(pageID string) (map[string]interface{}, error) {
-	req := map[string]interface{}{
-		"position": map[string]interface{}{
-			"pageId":   pageID,
-			"position": "end",
-		},
+	req := createWhiteboardRequest{
+		Position: blockPosition{PageID: pageID, Position: "end"},
 	}
 
 	data, err := c.doRequest("POST", "/whiteboards", req)
@@ -1567,8 +1860,8 @@ func (c *Client) GetWhiteboardElements(whiteboardID string) (map[string]interfac
 
 // AddWhiteboardElements appends elements to a whiteboard.
 func (c *Client) AddWhiteboardElements(whiteboardID string, elements []map[string]interface{}) (map[string]interface{}, error) {
-	req := map[string]interface{}{
-		"elements": elements,
+	req := whiteboardElementsRequest{
+		Elements: elements,
 	}
 
 	path := fmt.Sprintf("/whiteboards/%s/elements", url.PathEscape(whiteboardID))
@@ -1587,8 +1880,8 @@ func (c *Client) AddWhiteboardElements(whiteboardID string, elements []map[strin
 
 // UpdateWhiteboardElements updates specific whiteboard elements.
 func (c *Client) UpdateWhiteboardElements(whiteboardID string, elements []map[string]interface{}) error {
-	req := map[string]interface{}{
-		"elements": elements,
+	req := whiteboardElementsRequest{
+		Elements: elements,
 	}
 
 	path := fmt.Sprintf("/whiteboards/%s/elements", url.PathEscape(whiteboardID))
@@ -1598,8 +1891,8 @@ func (c *Client) UpdateWhiteboardElements(whiteboardID string, elements []map[st
 
 // DeleteWhiteboardElements removes elements from a whiteboard.
 func (c *Client) DeleteWhiteboardElements(whiteboardID string, elementIDs []string) error {
-	req := map[string]interface{}{
-		"elementIds": elementIDs,
+	req := deleteWhiteboardElementsRequest{
+		ElementIDs: elementIDs,
 	}
 
 	path := fmt.Sprintf("/whiteboards/%s/elements", url.PathEscape(whiteboardID))
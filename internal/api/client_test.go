@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -449,12 +451,12 @@ func TestClient_ClearDocumentContent(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(server.URL)
-		count, err := client.ClearDocumentContent("doc1")
+		deleted, err := client.ClearDocumentContent("doc1")
 		if err != nil {
 			t.Fatalf("ClearDocumentContent() error = %v", err)
 		}
-		if count != 2 {
-			t.Errorf("Expected 2 deleted blocks, got %d", count)
+		if len(deleted) != 2 {
+			t.Errorf("Expected 2 deleted blocks, got %v", deleted)
 		}
 	})
 
@@ -466,12 +468,12 @@ func TestClient_ClearDocumentContent(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(server.URL)
-		count, err := client.ClearDocumentContent("doc1")
+		deleted, err := client.ClearDocumentContent("doc1")
 		if err != nil {
 			t.Fatalf("Expected no error for empty document, got: %v", err)
 		}
-		if count != 0 {
-			t.Fatalf("Expected 0 deleted blocks, got %d", count)
+		if len(deleted) != 0 {
+			t.Fatalf("Expected 0 deleted blocks, got %v", deleted)
 		}
 	})
 }
@@ -735,3 +737,102 @@ func TestClient_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SetRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[],"total":0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetRateLimit(10)
+
+	start := time.Now()
+	for i := 0; i < 13; i++ {
+		if _, err := client.GetDocuments(); err != nil {
+			t.Fatalf("GetDocuments() error = %v", err)
+		}
+	}
+	// 10 requests fit the initial burst; the remaining 3 wait ~100ms each
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("13 requests at 10/s took %v, expected rate limiting", elapsed)
+	}
+
+	client.SetRateLimit(0)
+	if client.limiter != nil {
+		t.Error("SetRateLimit(0) should remove the limit")
+	}
+}
+
+func TestGuardSeesMutationsRelativeToBaseURL(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"items":[{"id":"d1","title":"T"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL + "/links/abc/api/v1")
+	var seen []Mutation
+	client.SetGuard(func(m Mutation) error {
+		seen = append(seen, m)
+		if m.Method == http.MethodDelete {
+			return fmt.Errorf("no deletes")
+		}
+		return nil
+	})
+
+	if _, err := client.GetDocuments(); err != nil {
+		t.Fatalf("GetDocuments() error = %v", err)
+	}
+	if err := client.MoveDocument("d1", "f1", ""); err != nil {
+		t.Fatalf("MoveDocument() error = %v", err)
+	}
+	if err := client.DeleteDocument("d1"); err == nil || err.Error() != "no deletes" {
+		t.Fatalf("DeleteDocument() error = %v, want guard error", err)
+	}
+
+	if len(seen) != 2 || seen[0].Path != "/documents" || !strings.Contains(string(seen[0].Body), `"folderId":"f1"`) {
+		t.Errorf("guard saw %+v", seen)
+	}
+	if len(sent) != 2 || sent[1] != "PUT /links/abc/api/v1/documents" {
+		t.Errorf("server received %v; the denied delete must not be sent", sent)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	oldDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = oldDelay }()
+
+	var gets, posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+			if gets <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"items":[]}`))
+			return
+		}
+		posts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetRetries(2)
+	if _, err := client.GetDocuments(); err != nil || gets != 3 {
+		t.Errorf("GetDocuments() error = %v after %d attempts, want success on the third", err, gets)
+	}
+	if _, err := client.CreateDocument(&models.CreateDocumentRequest{Title: "T"}); err == nil || posts != 1 {
+		t.Errorf("CreateDocument() error = %v after %d attempts; a failed POST must not be retried", err, posts)
+	}
+
+	gets = 0
+	client.SetRetries(1)
+	if _, err := client.GetDocuments(); err == nil || gets != 2 {
+		t.Errorf("with 1 retry: error = %v after %d attempts, want failure after 2", err, gets)
+	}
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ashrafali/craft-cli/internal/jsonschema"
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+// OpenAPIVersion is the version of the generated OpenAPI document. Bump it
+// when the endpoint table changes.
+const OpenAPIVersion = "1.0.0"
+
+// queryParam is a query string parameter accepted by an endpoint.
+type queryParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// endpoint describes one Craft API operation used by the client.
+type endpoint struct {
+	Method  string
+	Path    string
+	Summary string
+	Query   []queryParam
+	// Requests lists the JSON body shapes the client sends; more than one
+	// becomes anyOf. RawRequest is set instead for binary uploads.
+	Requests   []interface{}
+	RawRequest string
+	// Response is the JSON body the client decodes, or nil if it ignores it.
+	Response interface{}
+}
+
+var (
+	dateRangeParams = []queryParam{
+		{"createdDateGte", "string", "Created on or after (YYYY-MM-DD)"},
+		{"createdDateLte", "string", "Created on or before (YYYY-MM-DD)"},
+		{"lastModifiedDateGte", "string", "Modified on or after (YYYY-MM-DD)"},
+		{"lastModifiedDateLte", "string", "Modified on or before (YYYY-MM-DD)"},
+		{"dailyNoteDateGte", "string", "Daily note on or after (YYYY-MM-DD)"},
+		{"dailyNoteDateLte", "string", "Daily note on or before (YYYY-MM-DD)"},
+	}
+	fetchMetadataParam = queryParam{"fetchMetadata", "boolean", "Include block metadata"}
+	maxDepthParam      = queryParam{"maxDepth", "integer", "Maximum nesting depth to return"}
+)
+
+// endpoints is every Craft API operation the client calls.
+var endpoints = []endpoint{
+	{
+		Method: http.MethodGet, Path: "/documents", Summary: "List documents",
+		Query: append([]queryParam{
+			{"folderId", "string", "Only documents in this folder"},
+			{"location", "string", "Only documents in this location (unsorted, trash, templates, daily_notes)"},
+			fetchMetadataParam,
+		}, dateRangeParams...),
+		Response: models.DocumentList{},
+	},
+	{
+		Method: http.MethodPost, Path: "/documents", Summary: "Create documents",
+		Requests: []interface{}{createDocumentsRequest{}},
+		Response: createDocumentsResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/documents", Summary: "Move documents",
+		Requests: []interface{}{moveDocumentRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/documents", Summary: "Move documents to trash",
+		Requests: []interface{}{deleteDocumentsRequest{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/documents/search", Summary: "Search documents",
+		Query: append([]queryParam{
+			{"include", "string", "Text to search for"},
+			{"regexps", "string", "Regular expressions to match"},
+			{"location", "string", "Only documents in this location"},
+			{"folderIDs", "string", "Comma-separated folder IDs"},
+			{"documentIDs", "string", "Comma-separated document IDs"},
+			fetchMetadataParam,
+		}, dateRangeParams...),
+		Response: models.SearchResult{},
+	},
+	{
+		Method: http.MethodGet, Path: "/blocks", Summary: "Get a block tree by ID or daily note date",
+		Query: []queryParam{
+			{"id", "string", "Block or document ID"},
+			{"date", "string", "Daily note date (YYYY-MM-DD, today, yesterday, tomorrow)"},
+			maxDepthParam,
+			fetchMetadataParam,
+		},
+		Response: models.Block{},
+	},
+	{
+		Method: http.MethodPost, Path: "/blocks", Summary: "Insert blocks",
+		Requests: []interface{}{addBlockRequest{}, addBlockExtendedRequest{}, addBlockToDateRequest{}, addBlocksJSONRequest{}},
+		Response: blockItemsResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/blocks", Summary: "Update or move blocks",
+		Requests: []interface{}{updateBlocksMarkdownRequest{}, moveBlockRequest{}, updateBlocksJSONRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/blocks", Summary: "Delete blocks",
+		Requests: []interface{}{deleteBlocksRequest{}},
+		Response: deleteBlocksResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/blocks/search", Summary: "Search blocks within a document",
+		Query: []queryParam{
+			{"blockId", "string", "Document or block to search in"},
+			{"pattern", "string", "Regular expression to match"},
+			{"caseSensitive", "boolean", "Match case"},
+			{"beforeBlockCount", "integer", "Context blocks before each match"},
+			{"afterBlockCount", "integer", "Context blocks after each match"},
+		},
+		Response: models.BlockSearchResultList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/folders", Summary: "List folders",
+		Response: models.FolderList{},
+	},
+	{
+		Method: http.MethodPost, Path: "/folders", Summary: "Create folders",
+		Requests: []interface{}{createFolderRequest{}},
+		Response: createFolderResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/folders", Summary: "Move folders",
+		Requests: []interface{}{moveFolderRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/folders", Summary: "Delete folders",
+		Requests: []interface{}{deleteFolderRequest{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks", Summary: "List tasks",
+		Query: []queryParam{
+			{"scope", "string", "active, upcoming, inbox or logbook"},
+			{"documentId", "string", "Only tasks in this document"},
+		},
+		Response: models.TaskList{},
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks", Summary: "Create tasks",
+		Requests: []interface{}{addTaskRequest{}},
+		Response: addTaskResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks", Summary: "Update tasks",
+		Requests: []interface{}{updateTaskRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks", Summary: "Delete tasks",
+		Requests: []interface{}{deleteTaskRequest{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/collections", Summary: "List collections",
+		Query:    []queryParam{{"documentIds", "string", "Comma-separated document IDs"}},
+		Response: models.CollectionList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/collections/{collectionId}/schema", Summary: "Get a collection schema",
+		Query:    []queryParam{{"format", "string", "Schema format (schema or json-schema-items)"}},
+		Response: models.CollectionSchema{},
+	},
+	{
+		Method: http.MethodGet, Path: "/collections/{collectionId}/items", Summary: "List collection items",
+		Query:    []queryParam{maxDepthParam},
+		Response: models.CollectionItemList{},
+	},
+	{
+		Method: http.MethodPost, Path: "/collections/{collectionId}/items", Summary: "Add collection items",
+		Requests: []interface{}{addCollectionItemsRequest{}},
+		Response: models.CollectionItemList{},
+	},
+	{
+		Method: http.MethodPut, Path: "/collections/{collectionId}/items", Summary: "Update collection items",
+		Requests: []interface{}{updateCollectionItemsRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/collections/{collectionId}/items", Summary: "Delete collection items",
+		Requests: []interface{}{deleteCollectionItemsRequest{}},
+	},
+	{
+		Method: http.MethodGet, Path: "/connection", Summary: "Get space and connection info",
+		Response: models.ConnectionInfo{},
+	},
+	{
+		Method: http.MethodPost, Path: "/comments", Summary: "Add comments to blocks",
+		Requests: []interface{}{addCommentRequest{}},
+		Response: models.CommentResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/upload", Summary: "Upload a file as a new block",
+		Query: []queryParam{
+			{"pageId", "string", "Page to insert into"},
+			{"date", "string", "Daily note to insert into"},
+			{"siblingId", "string", "Block to insert next to"},
+			{"position", "string", "start, end, before or after"},
+		},
+		RawRequest: "application/octet-stream",
+		Response:   models.UploadResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/whiteboards", Summary: "Create a whiteboard",
+		Requests: []interface{}{createWhiteboardRequest{}},
+		Response: map[string]interface{}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/whiteboards/{whiteboardId}/elements", Summary: "List whiteboard elements",
+		Response: map[string]interface{}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/whiteboards/{whiteboardId}/elements", Summary: "Add whiteboard elements",
+		Requests: []interface{}{whiteboardElementsRequest{}},
+		Response: map[string]interface{}{},
+	},
+	{
+		Method: http.MethodPut, Path: "/whiteboards/{whiteboardId}/elements", Summary: "Update whiteboard elements",
+		Requests: []interface{}{whiteboardElementsRequest{}},
+	},
+	{
+		Method: http.MethodDelete, Path: "/whiteboards/{whiteboardId}/elements", Summary: "Delete whiteboard elements",
+		Requests: []interface{}{deleteWhiteboardElementsRequest{}},
+	},
+}
+
+// OpenAPIDocument is an OpenAPI 3.1 document.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers"`
+	Security   []map[string][]string                   `json:"security"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo is the document's info object.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIServer is a server URL template.
+type OpenAPIServer struct {
+	URL         string                           `json:"url"`
+	Variables   map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+	Description string                           `json:"description,omitempty"`
+}
+
+// OpenAPIServerVariable is a substitution in a server URL.
+type OpenAPIServerVariable struct {
+	Default     string `json:"default"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIOperation is a single method on a path.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter is a path or query parameter.
+type OpenAPIParameter struct {
+	Name        string             `json:"name"`
+	In          string             `json:"in"`
+	Required    bool               `json:"required,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema"`
+}
+
+// OpenAPIBody is a request body.
+type OpenAPIBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is a response for one status code.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema for one content type.
+type OpenAPIMediaType struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+// OpenAPIComponents holds reusable schemas and security schemes.
+type OpenAPIComponents struct {
+	Schemas         map[string]*jsonschema.Schema    `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes how requests authenticate.
+type OpenAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPISpec generates the OpenAPI document for the endpoints the client uses.
+// Request bodies are strict (no unlisted properties) so they can check the
+// client's own payloads; response bodies stay open since the API may add fields.
+func OpenAPISpec() *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:       "Craft Connect API (as used by craft-cli)",
+			Version:     OpenAPIVersion,
+			Description: "Endpoints and payloads sent and decoded by craft-cli, generated from internal/api and internal/models.",
+		},
+		Servers: []OpenAPIServer{{
+			URL: "https://connect.craft.do/links/{linkId}/api/v1",
+			Variables: map[string]OpenAPIServerVariable{
+				"linkId": {Default: "LINK", Description: "Connect link ID from the Craft API settings"},
+			},
+		}},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+		Paths:    map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	reflector := jsonschema.NewReflector("#/components/schemas/")
+	ops := make([]*OpenAPIOperation, len(endpoints))
+	for i, ep := range endpoints {
+		op := &OpenAPIOperation{
+			OperationID: operationID(ep.Method, ep.Path),
+			Summary:     ep.Summary,
+			Responses:   map[string]*OpenAPIResponse{},
+		}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(ep.Path, -1) {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name: m[1], In: "path", Required: true,
+				Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}},
+			})
+		}
+		for _, q := range ep.Query {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name: q.Name, In: "query", Description: q.Description,
+				Schema: &jsonschema.Schema{Type: jsonschema.Types{q.Type}},
+			})
+		}
+
+		switch {
+		case ep.RawRequest != "":
+			op.RequestBody = &OpenAPIBody{Required: true, Content: map[string]OpenAPIMediaType{
+				ep.RawRequest: {Schema: &jsonschema.Schema{Type: jsonschema.Types{"string"}, Format: "binary"}},
+			}}
+		case len(ep.Requests) == 1:
+			op.RequestBody = jsonBody(reflector.Reflect(ep.Requests[0]))
+		case len(ep.Requests) > 1:
+			s := &jsonschema.Schema{}
+			for _, r := range ep.Requests {
+				s.AnyOf = append(s.AnyOf, reflector.Reflect(r))
+			}
+			op.RequestBody = jsonBody(s)
+		}
+		ops[i] = op
+	}
+
+	// Reflect responses after all requests so shared types keep strict request schemas.
+	reflector.Open = true
+	for i, ep := range endpoints {
+		resp := &OpenAPIResponse{Description: "Success"}
+		if ep.Response != nil {
+			resp.Content = map[string]OpenAPIMediaType{
+				"application/json": {Schema: reflector.Reflect(ep.Response)},
+			}
+		}
+		ops[i].Responses["200"] = resp
+		ops[i].Responses["default"] = &OpenAPIResponse{
+			Description: "Error",
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: reflector.Reflect(models.ErrorResponse{})},
+			},
+		}
+
+		if doc.Paths[ep.Path] == nil {
+			doc.Paths[ep.Path] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[ep.Path][strings.ToLower(ep.Method)] = ops[i]
+	}
+
+	doc.Components.Schemas = reflector.Defs
+	return doc
+}
+
+func jsonBody(s *jsonschema.Schema) *OpenAPIBody {
+	return &OpenAPIBody{Required: true, Content: map[string]OpenAPIMediaType{
+		"application/json": {Schema: s},
+	}}
+}
+
+// operationID derives a stable ID such as "postCollectionsItems" from an endpoint.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" || strings.HasPrefix(seg, "{") {
+			continue
+		}
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	return b.String()
+}
+
+// MatchOperation finds the operation in doc for a request method and URL path,
+// returning the path template and the operation.
+func (doc *OpenAPIDocument) MatchOperation(method, path string) (string, *OpenAPIOperation) {
+	templates := make([]string, 0, len(doc.Paths))
+	for t := range doc.Paths {
+		templates = append(templates, t)
+	}
+	sort.Strings(templates)
+
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for _, t := range templates {
+		tsegs := strings.Split(strings.Trim(t, "/"), "/")
+		if len(tsegs) != len(segs) {
+			continue
+		}
+		match := true
+		for i := range tsegs {
+			if !strings.HasPrefix(tsegs[i], "{") && tsegs[i] != segs[i] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if op, ok := doc.Paths[t][strings.ToLower(method)]; ok {
+			return t, op
+		}
+	}
+	return "", nil
+}
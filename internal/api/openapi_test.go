@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/jsonschema"
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+type recordedRequest struct {
+	method, path, contentType string
+	query                     []string
+	body                      []byte
+}
+
+// TestOpenAPIContract drives every client method against a recording server
+// and checks each request against the generated spec: the operation exists,
+// query parameters are declared, and JSON bodies validate.
+func TestOpenAPIContract(t *testing.T) {
+	var mu sync.Mutex
+	var recorded []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var query []string
+		for k := range r.URL.Query() {
+			query = append(query, k)
+		}
+		mu.Lock()
+		recorded = append(recorded, recordedRequest{r.Method, r.URL.Path, r.Header.Get("Content-Type"), query, body})
+		mu.Unlock()
+		// One body that every client decoder accepts.
+		w.Write([]byte(`{"id":"d1","type":"page","markdown":"Doc","content":[{"id":"b1","type":"text","markdown":"x"}],
+			"items":[{"id":"x1","title":"T","type":"text","markdown":"x"}],"total":1}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL)
+	props := map[string]interface{}{"status": "Done"}
+	calls := map[string]func() error{
+		"GetDocumentsAdvanced": func() error {
+			_, err := c.GetDocumentsAdvanced(ListDocumentsOptions{FolderID: "f", Location: "unsorted", FetchMetadata: true, CreatedDateGte: "2024-01-01"})
+			return err
+		},
+		"GetDocumentsFiltered": func() error { _, err := c.GetDocumentsFiltered("f", "trash"); return err },
+		"GetDocument":          func() error { _, err := c.GetDocument("d1"); return err },
+		"GetDocumentBlocksWithDepth": func() error {
+			_, err := c.GetDocumentBlocksWithDepth("d1", 2)
+			return err
+		},
+		"SearchDocuments": func() error { _, err := c.SearchDocuments("q"); return err },
+		"SearchDocumentsAdvanced": func() error {
+			_, err := c.SearchDocumentsAdvanced("q", SearchOptions{Regexps: "a.*", FolderIDs: "f", DocumentIDs: "d", DailyNoteDateLte: "2024-01-01"})
+			return err
+		},
+		"CreateDocument": func() error {
+			_, err := c.CreateDocument(&models.CreateDocumentRequest{Title: "T", Markdown: "body", ParentID: "f"})
+			return err
+		},
+		"UpdateDocument": func() error {
+			_, err := c.UpdateDocument("d1", &models.UpdateDocumentRequest{Title: "New", Markdown: "more"})
+			return err
+		},
+		"DeleteDocument":         func() error { return c.DeleteDocument("d1") },
+		"ClearDocumentContent":   func() error { _, err := c.ClearDocumentContent("d1"); return err },
+		"ReplaceDocumentContent": func() error { return c.ReplaceDocumentContent("d1", "new", 0) },
+		"MoveDocument":           func() error { return c.MoveDocument("d1", "f", "") },
+		"DeleteBlock":            func() error { return c.DeleteBlock("b1") },
+		"GetBlockWithOptions":    func() error { _, err := c.GetBlockWithOptions("b1", 1, true); return err },
+		"GetBlockByDate":         func() error { _, err := c.GetBlockByDate("today", -1, true); return err },
+		"AddBlock":               func() error { _, err := c.AddBlock("d1", "x", "end"); return err },
+		"AddBlockRelative":       func() error { _, err := c.AddBlockRelative("b1", "x", "after"); return err },
+		"AddBlockToDate":         func() error { _, err := c.AddBlockToDate("today", "x", "end"); return err },
+		"MoveBlock":              func() error { return c.MoveBlock("b1", "d2", "start") },
+		"AddBlocksJSON": func() error {
+			_, err := c.AddBlocksJSON([]map[string]interface{}{{"type": "text", "markdown": "x"}}, map[string]interface{}{"pageId": "d1", "position": "end"})
+			return err
+		},
+		"UpdateBlocksJSON": func() error {
+			return c.UpdateBlocksJSON([]map[string]interface{}{{"id": "b1", "color": "#ff0000"}})
+		},
+		"SearchBlocks":  func() error { _, err := c.SearchBlocks("d1", "x", true, 1, 1); return err },
+		"GetFolders":    func() error { _, err := c.GetFolders(); return err },
+		"CreateFolder":  func() error { _, err := c.CreateFolder("F", "p"); return err },
+		"MoveFolder":    func() error { return c.MoveFolder("f", "root") },
+		"DeleteFolder":  func() error { return c.DeleteFolder("f") },
+		"GetTasks":      func() error { _, err := c.GetTasks("active"); return err },
+		"GetDocTasks":   func() error { _, err := c.GetDocumentTasks("d1"); return err },
+		"AddTask":       func() error { _, err := c.AddTask("t", "document", "d1", "2024-01-01", ""); return err },
+		"UpdateTask":    func() error { return c.UpdateTask("t1", "done", "", "2024-02-01") },
+		"DeleteTask":    func() error { return c.DeleteTask("t1") },
+		"GetConnection": func() error { _, err := c.GetConnection(); return err },
+		"AddComment":    func() error { _, err := c.AddComment("b1", "hi"); return err },
+		"UploadFile":    func() error { _, err := c.UploadFile([]byte("data"), "d1", "", "", "end"); return err },
+		"GetCollections": func() error {
+			_, err := c.GetCollections("d1")
+			return err
+		},
+		"GetCollectionSchema": func() error { _, err := c.GetCollectionSchema("c1", "schema"); return err },
+		"GetCollectionItems":  func() error { _, err := c.GetCollectionItems("c1", 2); return err },
+		"AddCollectionItem": func() error {
+			_, err := c.AddCollectionItem("c1", "Row", props, true)
+			return err
+		},
+		"UpdateCollectionItem": func() error { return c.UpdateCollectionItem("c1", "i1", props, false) },
+		"DeleteCollectionItem": func() error { return c.DeleteCollectionItem("c1", "i1") },
+		"CreateWhiteboard":     func() error { _, err := c.CreateWhiteboard("d1"); return err },
+		"GetWhiteboardElements": func() error {
+			_, err := c.GetWhiteboardElements("w1")
+			return err
+		},
+		"AddWhiteboardElements": func() error {
+			_, err := c.AddWhiteboardElements("w1", []map[string]interface{}{{"type": "rectangle"}})
+			return err
+		},
+		"UpdateWhiteboardElements": func() error {
+			return c.UpdateWhiteboardElements("w1", []map[string]interface{}{{"id": "e1"}})
+		},
+		"DeleteWhiteboardElements": func() error { return c.DeleteWhiteboardElements("w1", []string{"e1"}) },
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+	}
+
+	spec := OpenAPISpec()
+	covered := map[string]bool{}
+	for _, req := range recorded {
+		tmpl, op := spec.MatchOperation(req.method, req.path)
+		if op == nil {
+			t.Errorf("%s %s is not in the OpenAPI spec", req.method, req.path)
+			continue
+		}
+		covered[req.method+" "+tmpl] = true
+
+		declared := map[string]bool{}
+		for _, p := range op.Parameters {
+			declared[p.Name] = true
+		}
+		for _, q := range req.query {
+			if !declared[q] {
+				t.Errorf("%s %s: query parameter %q is not declared", req.method, tmpl, q)
+			}
+		}
+
+		if op.RequestBody == nil {
+			if len(req.body) > 0 {
+				t.Errorf("%s %s: spec has no request body but client sent %s", req.method, tmpl, req.body)
+			}
+			continue
+		}
+		media, ok := op.RequestBody.Content[req.contentType]
+		if !ok {
+			t.Errorf("%s %s: content type %q is not in the spec", req.method, tmpl, req.contentType)
+			continue
+		}
+		if req.contentType != "application/json" {
+			continue
+		}
+		var body interface{}
+		if err := json.Unmarshal(req.body, &body); err != nil {
+			t.Errorf("%s %s: invalid JSON body: %v", req.method, tmpl, err)
+			continue
+		}
+		if err := jsonschema.Validate(media.Schema, spec.Components.Schemas, body); err != nil {
+			t.Errorf("%s %s: body %s does not match spec: %v", req.method, tmpl, req.body, err)
+		}
+	}
+
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			key := strings.ToUpper(method) + " " + path
+			if !covered[key] {
+				t.Errorf("%s is in the spec but no client call exercised it", key)
+			}
+		}
+	}
+}
+
+func TestOpenAPISpec_RejectsUnknownFields(t *testing.T) {
+	spec := OpenAPISpec()
+	_, op := spec.MatchOperation("DELETE", "/tasks")
+	schema := op.RequestBody.Content["application/json"].Schema
+
+	var body interface{}
+	json.Unmarshal([]byte(`{"taskIds":["t1"],"force":true}`), &body)
+	if err := jsonschema.Validate(schema, spec.Components.Schemas, body); err == nil {
+		t.Error("expected unknown request field to fail validation")
+	}
+}
+
+func TestOpenAPISpec_Stable(t *testing.T) {
+	a, _ := json.Marshal(OpenAPISpec())
+	b, _ := json.Marshal(OpenAPISpec())
+	if string(a) != string(b) {
+		t.Error("OpenAPISpec() output is not deterministic")
+	}
+
+	var doc map[string]interface{}
+	json.Unmarshal(a, &doc)
+	if doc["openapi"] != "3.1.0" {
+		t.Errorf("openapi = %v", doc["openapi"])
+	}
+	for _, ref := range []string{"CreateDocumentsRequest", "Block", "AddTaskRequest", "ErrorResponse"} {
+		if OpenAPISpec().Components.Schemas[ref] == nil {
+			t.Errorf("missing component schema %s", ref)
+		}
+	}
+}
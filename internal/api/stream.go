@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+// ========== Streaming ==========
+
+// streamItems performs a GET request and calls fn for each element of the
+// response's top-level "items" array as soon as it is decoded, without
+// buffering the whole payload. Returning an error from fn stops decoding
+// and closes the connection; that error is returned unchanged.
+func streamItems[T any](c *Client, path string, fn func(T) error) error {
+	req, err := c.newJSONRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid response from API: %w", err)
+		}
+		key, _ := tok.(string)
+		if key != "items" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("invalid response from API: %w", err)
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var item T
+			if err := dec.Decode(&item); err != nil {
+				return fmt.Errorf("invalid response from API: %w", err)
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token and checks that it is the given delimiter.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid response from API: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("invalid response from API: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// StreamDocuments lists documents, calling fn for each one as it arrives.
+func (c *Client) StreamDocuments(opts ListDocumentsOptions, fn func(models.Document) error) error {
+	return streamItems(c, documentsPath(opts), fn)
+}
+
+// StreamSearch searches documents, calling fn for each result as it arrives.
+func (c *Client) StreamSearch(query string, opts SearchOptions, fn func(models.SearchItem) error) error {
+	return streamItems(c, searchPath(query, opts), fn)
+}
+
+// StreamTasks lists tasks for a scope or document, calling fn for each task as it arrives.
+func (c *Client) StreamTasks(scope, docID string, fn func(models.Task) error) error {
+	path := "/tasks"
+	if docID != "" {
+		path = fmt.Sprintf("/tasks?documentId=%s", url.QueryEscape(docID))
+	} else if scope != "" {
+		path = fmt.Sprintf("/tasks?scope=%s", url.QueryEscape(scope))
+	}
+	return streamItems(c, path, fn)
+}
+
+// StreamFolders lists top-level folders (with nested subfolders), calling fn for each.
+func (c *Client) StreamFolders(fn func(models.Folder) error) error {
+	return streamItems(c, "/folders", fn)
+}
+
+// StreamCollections lists collections, calling fn for each one as it arrives.
+func (c *Client) StreamCollections(documentIDs string, fn func(models.Collection) error) error {
+	path := "/collections"
+	if documentIDs != "" {
+		path = fmt.Sprintf("/collections?documentIds=%s", url.QueryEscape(documentIDs))
+	}
+	return streamItems(c, path, fn)
+}
+
+// StreamCollectionItems lists collection items, calling fn for each one as it arrives.
+func (c *Client) StreamCollectionItems(collectionID string, maxDepth int, fn func(models.CollectionItem) error) error {
+	path := fmt.Sprintf("/collections/%s/items", url.PathEscape(collectionID))
+	if maxDepth > 0 {
+		path = fmt.Sprintf("%s?maxDepth=%d", path, maxDepth)
+	}
+	return streamItems(c, path, fn)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashrafali/craft-cli/internal/models"
+)
+
+func TestClient_StreamDocuments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/documents" {
+			t.Errorf("Expected path /documents, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("folderId"); got != "f1" {
+			t.Errorf("folderId = %q, want f1", got)
+		}
+		fmt.Fprint(w, `{"total":3,"items":[{"id":"a","title":"A"},{"id":"b","title":"B"},{"id":"c","title":"C"}],"extra":{"x":1}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	var ids []string
+	err := client.StreamDocuments(ListDocumentsOptions{FolderID: "f1"}, func(doc models.Document) error {
+		ids = append(ids, doc.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamDocuments() error = %v", err)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[2] != "c" {
+		t.Errorf("ids = %v, want [a b c]", ids)
+	}
+}
+
+func TestClient_StreamStopsOnCallbackError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"id":"t1"},{"id":"t2"},{"id":"t3"}]}`)
+	}))
+	defer server.Close()
+
+	stop := errors.New("stop")
+	count := 0
+	err := NewClient(server.URL).StreamTasks("active", "", func(task models.Task) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("StreamTasks() error = %v, want stop", err)
+	}
+	if count != 2 {
+		t.Errorf("callback called %d times, want 2", count)
+	}
+}
+
+func TestClient_StreamAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"not found"}`)
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).StreamFolders(func(models.Folder) error { return nil })
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
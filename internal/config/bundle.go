@@ -0,0 +1,128 @@
+package config
+
+import "fmt"
+
+// How ImportProfile handles a profile name that already exists.
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Bundle is a set of profiles shared between machines, as written by
+// craft config export and read by craft config import.
+type Bundle struct {
+	Profiles map[string]BundleProfile `json:"profiles"`
+}
+
+// BundleProfile is a profile with its secrets resolved, or left out.
+type BundleProfile struct {
+	URL    string `json:"url"`
+	APIKey string `json:"api_key,omitempty"`
+	// NeedsAPIKey marks a profile exported without its API key.
+	NeedsAPIKey bool              `json:"needs_api_key,omitempty"`
+	Policy      string            `json:"policy,omitempty"`
+	Defaults    map[string]string `json:"defaults,omitempty"`
+	Bookmarks   map[string]string `json:"bookmarks,omitempty"`
+}
+
+// Export returns the named profiles (all if names is empty) with their
+// URLs and API keys read from the secret store. Without secrets, API keys
+// are left out and the profiles that had one are marked NeedsAPIKey.
+func (m *Manager) Export(names []string, withSecrets bool) (*Bundle, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		names = sortedNames(cfg.Profiles)
+	}
+
+	bundle := &Bundle{Profiles: map[string]BundleProfile{}}
+	for _, name := range names {
+		profile, exists := cfg.Profiles[name]
+		if !exists {
+			return nil, fmt.Errorf("profile '%s' not found", name)
+		}
+		url, err := m.resolveSecret(cfg, profile.URL, profile.URLRef)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		bp := BundleProfile{URL: url, Policy: profile.Policy, Defaults: profile.Defaults, Bookmarks: profile.Bookmarks}
+		if withSecrets {
+			if bp.APIKey, err = m.resolveSecret(cfg, profile.APIKey, profile.APIKeyRef); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+		} else {
+			bp.NeedsAPIKey = profile.APIKey != "" || profile.APIKeyRef != ""
+		}
+		bundle.Profiles[name] = bp
+	}
+	return bundle, nil
+}
+
+// BackendAPIKey looks up the API key of profile name in the configured
+// secret backend, under the name profiles' keys are stored as. It returns
+// "" when no backend is configured.
+func (m *Manager) BackendAPIKey(name string) (string, error) {
+	cfg, err := m.Load()
+	if err != nil || cfg.Secrets == nil {
+		return "", err
+	}
+	store, err := m.secretStore(cfg.Secrets.Backend, cfg.Secrets)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(name + "/api_key")
+}
+
+// ImportProfile saves p as profile name with its URL, API key, policy,
+// defaults and bookmarks. If the name is taken, onConflict decides: skip leaves the
+// existing profile alone, overwrite replaces it (keeping its API key if p
+// has none), and rename saves p as name-2 (or the next free number). It returns the name p was saved as, or
+// "" if it was skipped.
+func (m *Manager) ImportProfile(name string, p BundleProfile, onConflict string) (string, error) {
+	saved := name
+	err := m.update(func(cfg *Config) error {
+		if _, exists := cfg.Profiles[name]; exists {
+			switch onConflict {
+			case ConflictSkip:
+				saved = ""
+				return nil
+			case ConflictOverwrite:
+			case ConflictRename:
+				for i := 2; ; i++ {
+					saved = fmt.Sprintf("%s-%d", name, i)
+					if _, taken := cfg.Profiles[saved]; !taken {
+						break
+					}
+				}
+			default:
+				return fmt.Errorf("unknown conflict mode %q", onConflict)
+			}
+		}
+
+		// A bundle exported without secrets keeps the working local key.
+		existing, keep := cfg.Profiles[saved]
+		keep = keep && p.APIKey == ""
+		if keep {
+			cfg.Profiles[saved] = Profile{URL: existing.URL, URLRef: existing.URLRef}
+		}
+		if err := m.setProfile(cfg, saved, p.URL, p.APIKey); err != nil {
+			return err
+		}
+		profile := cfg.Profiles[saved]
+		if keep {
+			profile.APIKey, profile.APIKeyRef = existing.APIKey, existing.APIKeyRef
+		}
+		profile.Policy = p.Policy
+		profile.Defaults = p.Defaults
+		profile.Bookmarks = p.Bookmarks
+		cfg.Profiles[saved] = profile
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return saved, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import "testing"
+
+func TestManager_ExportWithoutSecrets(t *testing.T) {
+	t.Setenv(SecretPassphraseEnv, "")
+	mgr := newTestManager(t.TempDir())
+	mgr.AddProfileWithKey("work", "https://connect.craft.do/links/WORK/api/v1", "pdk_work")
+	mgr.AddProfile("home", "https://connect.craft.do/links/HOME/api/v1")
+	mgr.SetProfileDefault("work", "format", "table")
+	mgr.SetBookmark("work", "roadmap", "doc-1")
+	mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile})
+
+	bundle, err := mgr.Export(nil, false)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	work := bundle.Profiles["work"]
+	if work.URL != "https://connect.craft.do/links/WORK/api/v1" || work.APIKey != "" || !work.NeedsAPIKey || work.Defaults["format"] != "table" || work.Bookmarks["roadmap"] != "doc-1" {
+		t.Errorf("work = %+v", work)
+	}
+	if home := bundle.Profiles["home"]; home.NeedsAPIKey {
+		t.Errorf("home = %+v, needs no key", home)
+	}
+
+	if bundle, _ = mgr.Export([]string{"work"}, true); len(bundle.Profiles) != 1 || bundle.Profiles["work"].APIKey != "pdk_work" {
+		t.Errorf("Export(work, secrets) = %+v", bundle)
+	}
+	if key, err := mgr.BackendAPIKey("work"); key != "pdk_work" {
+		t.Errorf("BackendAPIKey() = %q, %v", key, err)
+	}
+	if _, err := mgr.Export([]string{"nope"}, false); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestManager_ImportProfileConflicts(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	mgr.AddProfile("work", "https://example.com/old")
+	mgr.SetProfileDefault("work", "format", "table")
+	p := BundleProfile{URL: "https://example.com/new", Defaults: map[string]string{"timeout": "10s"}}
+
+	for _, tt := range []struct {
+		mode, want string
+	}{
+		{ConflictSkip, ""},
+		{ConflictRename, "work-2"},
+		{ConflictRename, "work-3"},
+		{ConflictOverwrite, "work"},
+	} {
+		saved, err := mgr.ImportProfile("work", p, tt.mode)
+		if err != nil || saved != tt.want {
+			t.Errorf("ImportProfile(%s) = %q, %v; want %q", tt.mode, saved, err, tt.want)
+		}
+	}
+
+	cfg, _ := mgr.Load()
+	work := cfg.Profiles["work"]
+	if work.URL != p.URL || work.Defaults["format"] != "" || work.Defaults["timeout"] != "10s" {
+		t.Errorf("overwritten work = %+v", work)
+	}
+	if cfg.Profiles["work-2"].URL != p.URL || len(cfg.Profiles) != 3 {
+		t.Errorf("profiles = %+v", cfg.Profiles)
+	}
+	if _, err := mgr.ImportProfile("work", p, "merge"); err == nil {
+		t.Error("expected an error for an unknown conflict mode")
+	}
+}
+
+func TestManager_ImportOverwriteKeepsAPIKey(t *testing.T) {
+	t.Setenv(SecretPassphraseEnv, "")
+	tmpDir := t.TempDir()
+	mgr := newTestManager(tmpDir)
+	mgr.AddProfileWithKey("work", "https://example.com/old", "pdk_local")
+	mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile})
+
+	if _, err := mgr.ImportProfile("work", BundleProfile{URL: "https://example.com/new", NeedsAPIKey: true}, ConflictOverwrite); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := newTestManager(tmpDir).GetActiveAPIKey(); err != nil || key != "pdk_local" {
+		t.Errorf("API key after overwrite = %q, %v; want the local key kept", key, err)
+	}
+	if url, _ := mgr.GetActiveURL(); url != "https://example.com/new" {
+		t.Errorf("URL = %q", url)
+	}
+
+	if _, err := mgr.ImportProfile("work", BundleProfile{URL: "https://example.com/new", APIKey: "pdk_bundle"}, ConflictOverwrite); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := newTestManager(tmpDir).GetActiveAPIKey(); key != "pdk_bundle" {
+		t.Errorf("API key = %q, want the bundle's", key)
+	}
+}
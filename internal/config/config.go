@@ -1,38 +1,75 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 const (
 	ConfigDirName  = ".craft-cli"
 	ConfigFileName = "config.json"
+	// XDGDirName is the config directory under $XDG_CONFIG_HOME, used instead
+	// of ConfigDirName when it exists.
+	XDGDirName = "craft-cli"
 )
 
 // Profile represents a named API configuration
 type Profile struct {
-	URL    string `json:"url"`
+	URL    string `json:"url,omitempty"`
 	APIKey string `json:"api_key,omitempty"`
+	// URLRef and APIKeyRef point into the secret store when the URL (itself
+	// a bearer secret for public links) and API key are kept out of this file.
+	URLRef    string `json:"url_ref,omitempty"`
+	APIKeyRef string `json:"api_key_ref,omitempty"`
+	// Policy is the path of a safety policy file applied to this profile.
+	Policy string `json:"policy,omitempty"`
+	// Defaults are per-profile settings such as output format or timeout,
+	// keyed by name. They are validated by the CLI before being stored.
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// Bookmarks map a name, used as @name in place of an ID, to an ID in
+	// this profile's space.
+	Bookmarks map[string]string `json:"bookmarks,omitempty"`
 }
 
 // Config represents the application configuration
 type Config struct {
+	// Version is the format of the file; see CurrentVersion.
+	Version       int                `json:"version"`
 	DefaultFormat string             `json:"default_format"`
 	ActiveProfile string             `json:"active_profile,omitempty"`
 	Profiles      map[string]Profile `json:"profiles,omitempty"`
+	// Secrets, when set, stores profile URLs and API keys outside this file.
+	Secrets *SecretsConfig `json:"secrets,omitempty"`
+	// Aliases map a command name to a craft command line. Arguments given
+	// to the alias are appended to it.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Macros map a command name to several craft command lines.
+	Macros map[string]Macro `json:"macros,omitempty"`
+}
+
+// Macro is a user-defined command that runs craft command lines in order.
+// Steps may refer to the macro's arguments as $1..$9, or to all of them as $@.
+type Macro struct {
+	Description string   `json:"description,omitempty"`
+	Steps       []string `json:"steps"`
 }
 
 // Manager handles configuration operations
 type Manager struct {
-	configDir  string
-	configPath string
+	configDir   string
+	configPath  string
+	fileSecrets *fileSecretStore
+	// profile overrides the config's active profile for this process.
+	profile string
 }
 
-// NewManager creates a new configuration manager
+// NewManager creates a new configuration manager. The config directory is
+// $XDG_CONFIG_HOME/craft-cli (default ~/.config/craft-cli) if it exists,
+// else ~/.craft-cli. An XDG directory without a config file is first
+// filled from ~/.craft-cli, so creating it does not lose any profiles.
 func NewManager() (*Manager, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -40,6 +77,17 @@ func NewManager() (*Manager, error) {
 	}
 
 	configDir := filepath.Join(homeDir, ConfigDirName)
+	xdgHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgHome == "" {
+		xdgHome = filepath.Join(homeDir, ".config")
+	}
+	if info, err := os.Stat(filepath.Join(xdgHome, XDGDirName)); err == nil && info.IsDir() {
+		xdgDir := filepath.Join(xdgHome, XDGDirName)
+		if err := migrateLegacyDir(configDir, xdgDir); err != nil {
+			return nil, fmt.Errorf("failed to move %s to %s: %w", configDir, xdgDir, err)
+		}
+		configDir = xdgDir
+	}
 	configPath := filepath.Join(configDir, ConfigFileName)
 
 	return &Manager{
@@ -48,52 +96,57 @@ func NewManager() (*Manager, error) {
 	}, nil
 }
 
-// Load reads the configuration file
-func (m *Manager) Load() (*Config, error) {
-	data, err := os.ReadFile(m.configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &Config{
-				DefaultFormat: "json",
-				Profiles:      make(map[string]Profile),
-			}, nil
-		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
+// Dir returns the directory holding the config file and other CLI state
+func (m *Manager) Dir() string {
+	return m.configDir
+}
 
-	// Set defaults
-	if cfg.DefaultFormat == "" {
-		cfg.DefaultFormat = "json"
-	}
-	if cfg.Profiles == nil {
-		cfg.Profiles = make(map[string]Profile)
-	}
+// Path returns the location of the config file
+func (m *Manager) Path() string {
+	return m.configPath
+}
 
-	return &cfg, nil
+// SetProfile makes name the active profile for this process without saving
+// it; an empty name restores the config's active profile.
+func (m *Manager) SetProfile(name string) {
+	m.profile = name
 }
 
-// Save writes the configuration file
-func (m *Manager) Save(cfg *Config) error {
-	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(m.configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// activeProfile returns the profile in effect for cfg.
+func (m *Manager) activeProfile(cfg *Config) string {
+	if m.profile != "" {
+		return m.profile
 	}
+	return cfg.ActiveProfile
+}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+// ActiveProfile returns the name of the profile in effect (may be empty)
+func (m *Manager) ActiveProfile() (string, error) {
+	cfg, err := m.Load()
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return "", err
 	}
+	return m.activeProfile(cfg), nil
+}
 
-	if err := os.WriteFile(m.configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+// Load reads the configuration file. A file written by an older version is
+// upgraded in place, keeping a backup of the original.
+func (m *Manager) Load() (*Config, error) {
+	cfg, from, _, err := m.read()
+	if err != nil || from == CurrentVersion {
+		return cfg, err
 	}
+	err = m.withLock(func() error {
+		cfg, err = m.upgrade()
+		return err
+	})
+	return cfg, err
+}
 
-	return nil
+// Save writes the configuration file atomically, with mode 0600 since it may
+// hold API keys.
+func (m *Manager) Save(cfg *Config) error {
+	return m.withLock(func() error { return m.write(cfg) })
 }
 
 // AddProfile adds or updates a named profile
@@ -103,55 +156,64 @@ func (m *Manager) AddProfile(name, url string) error {
 
 // AddProfileWithKey adds or updates a named profile with an optional API key
 func (m *Manager) AddProfileWithKey(name, url, apiKey string) error {
-	cfg, err := m.Load()
-	if err != nil {
-		return err
-	}
+	return m.update(func(cfg *Config) error {
+		return m.setProfile(cfg, name, url, apiKey)
+	})
+}
 
-	cfg.Profiles[name] = Profile{URL: url, APIKey: apiKey}
+// setProfile adds or updates the URL and API key of a profile in cfg,
+// keeping them in the secret backend when one is configured.
+func (m *Manager) setProfile(cfg *Config, name, url, apiKey string) error {
+	profile := cfg.Profiles[name]
+	m.deleteSecret(cfg, profile.URLRef)
+	m.deleteSecret(cfg, profile.APIKeyRef)
+	profile.URL, profile.URLRef = url, ""
+	profile.APIKey, profile.APIKeyRef = apiKey, ""
+	if cfg.Secrets != nil {
+		var err error
+		if profile, err = m.moveProfileSecrets(cfg, name, profile); err != nil {
+			return err
+		}
+	}
+	cfg.Profiles[name] = profile
 
 	// If this is the first profile, make it active
 	if len(cfg.Profiles) == 1 {
 		cfg.ActiveProfile = name
 	}
-
-	return m.Save(cfg)
+	return nil
 }
 
 // RemoveProfile deletes a named profile
 func (m *Manager) RemoveProfile(name string) error {
-	cfg, err := m.Load()
-	if err != nil {
-		return err
-	}
-
-	if _, exists := cfg.Profiles[name]; !exists {
-		return fmt.Errorf("profile '%s' not found", name)
-	}
+	return m.update(func(cfg *Config) error {
+		profile, exists := cfg.Profiles[name]
+		if !exists {
+			return fmt.Errorf("profile '%s' not found", name)
+		}
 
-	delete(cfg.Profiles, name)
+		m.deleteSecret(cfg, profile.URLRef)
+		m.deleteSecret(cfg, profile.APIKeyRef)
+		delete(cfg.Profiles, name)
 
-	// Clear active profile if it was the removed one
-	if cfg.ActiveProfile == name {
-		cfg.ActiveProfile = ""
-	}
-
-	return m.Save(cfg)
+		// Clear active profile if it was the removed one
+		if cfg.ActiveProfile == name {
+			cfg.ActiveProfile = ""
+		}
+		return nil
+	})
 }
 
 // UseProfile sets the active profile
 func (m *Manager) UseProfile(name string) error {
-	cfg, err := m.Load()
-	if err != nil {
-		return err
-	}
-
-	if _, exists := cfg.Profiles[name]; !exists {
-		return fmt.Errorf("profile '%s' not found", name)
-	}
+	return m.update(func(cfg *Config) error {
+		if _, exists := cfg.Profiles[name]; !exists {
+			return fmt.Errorf("profile '%s' not found", name)
+		}
 
-	cfg.ActiveProfile = name
-	return m.Save(cfg)
+		cfg.ActiveProfile = name
+		return nil
+	})
 }
 
 // ListProfiles returns all profiles with the active one marked
@@ -166,8 +228,10 @@ func (m *Manager) ListProfiles() ([]ProfileInfo, error) {
 		profiles = append(profiles, ProfileInfo{
 			Name:      name,
 			URL:       profile.URL,
-			Active:    name == cfg.ActiveProfile,
-			HasAPIKey: profile.APIKey != "",
+			Active:    name == m.activeProfile(cfg),
+			HasAPIKey: profile.APIKey != "" || profile.APIKeyRef != "",
+			Secret:    profile.URLRef != "" || profile.APIKeyRef != "",
+			Policy:    profile.Policy,
 		})
 	}
 
@@ -185,6 +249,10 @@ type ProfileInfo struct {
 	URL       string
 	Active    bool
 	HasAPIKey bool
+	// Secret is set when the URL or API key is kept in the secret store;
+	// URL is then empty.
+	Secret bool
+	Policy string
 }
 
 // GetActiveURL returns the URL of the active profile
@@ -194,16 +262,17 @@ func (m *Manager) GetActiveURL() (string, error) {
 		return "", err
 	}
 
-	if cfg.ActiveProfile == "" {
+	active := m.activeProfile(cfg)
+	if active == "" {
 		return "", fmt.Errorf("no active profile. Run 'craft config add <name> <url>' first")
 	}
 
-	profile, exists := cfg.Profiles[cfg.ActiveProfile]
+	profile, exists := cfg.Profiles[active]
 	if !exists {
-		return "", fmt.Errorf("active profile '%s' not found. Run 'craft config add <name> <url>' first", cfg.ActiveProfile)
+		return "", fmt.Errorf("active profile '%s' not found. Run 'craft config add <name> <url>' first", active)
 	}
 
-	return profile.URL, nil
+	return m.resolveSecret(cfg, profile.URL, profile.URLRef)
 }
 
 // GetActiveAPIKey returns the API key of the active profile (may be empty)
@@ -213,16 +282,232 @@ func (m *Manager) GetActiveAPIKey() (string, error) {
 		return "", err
 	}
 
-	if cfg.ActiveProfile == "" {
+	profile, exists := cfg.Profiles[m.activeProfile(cfg)]
+	if !exists {
 		return "", nil
 	}
 
-	profile, exists := cfg.Profiles[cfg.ActiveProfile]
+	return m.resolveSecret(cfg, profile.APIKey, profile.APIKeyRef)
+}
+
+// MigrateSecrets moves the URLs and API keys of all profiles into the secret
+// backend sc, which is also used for profiles added later. Secrets already in
+// another backend are moved too. It returns the number of profiles changed.
+func (m *Manager) MigrateSecrets(sc SecretsConfig) (int, error) {
+	if err := sc.Validate(); err != nil {
+		return 0, err
+	}
+	var old *SecretsConfig
+	moved := 0
+	var stale []string
+	err := m.update(func(cfg *Config) error {
+		// Read every secret before switching backends.
+		old = cfg.Secrets
+		type plain struct{ url, key string }
+		values := map[string]plain{}
+		for name, profile := range cfg.Profiles {
+			url, err := m.resolveSecret(cfg, profile.URL, profile.URLRef)
+			if err != nil {
+				return err
+			}
+			key, err := m.resolveSecret(cfg, profile.APIKey, profile.APIKeyRef)
+			if err != nil {
+				return err
+			}
+			values[name] = plain{url, key}
+		}
+
+		cfg.Secrets = &sc
+		inBackend := func(ref string) bool {
+			return ref == "" || strings.HasPrefix(ref, secretRef(sc.Backend, ""))
+		}
+		for name, profile := range cfg.Profiles {
+			if profile.URL == "" && profile.APIKey == "" && inBackend(profile.URLRef) && inBackend(profile.APIKeyRef) {
+				continue
+			}
+			for _, ref := range []string{profile.URLRef, profile.APIKeyRef} {
+				if !inBackend(ref) {
+					stale = append(stale, ref)
+				}
+			}
+			profile.URL, profile.APIKey = values[name].url, values[name].key
+			var err error
+			if profile, err = m.moveProfileSecrets(cfg, name, profile); err != nil {
+				return err
+			}
+			cfg.Profiles[name] = profile
+			moved++
+		}
+		return nil
+	})
+	if err != nil {
+		return moved, err
+	}
+
+	// Only drop secrets from the old backend once the config points elsewhere.
+	oldCfg := &Config{Secrets: old}
+	for _, ref := range stale {
+		m.deleteSecret(oldCfg, ref)
+	}
+	return moved, nil
+}
+
+// moveProfileSecrets stores the plaintext URL and API key of profile in the
+// configured secret backend and replaces them with references.
+func (m *Manager) moveProfileSecrets(cfg *Config, name string, profile Profile) (Profile, error) {
+	urlRef, err := m.storeSecret(cfg, name+"/url", profile.URL)
+	if err != nil {
+		return profile, err
+	}
+	keyRef, err := m.storeSecret(cfg, name+"/api_key", profile.APIKey)
+	if err != nil {
+		return profile, err
+	}
+	profile.URL, profile.URLRef = "", urlRef
+	profile.APIKey, profile.APIKeyRef = "", keyRef
+	return profile, nil
+}
+
+// SetProfilePolicy sets (or, with an empty path, clears) the policy file of a profile
+func (m *Manager) SetProfilePolicy(name, path string) error {
+	return m.update(func(cfg *Config) error {
+		profile, exists := cfg.Profiles[name]
+		if !exists {
+			return fmt.Errorf("profile '%s' not found", name)
+		}
+
+		profile.Policy = path
+		cfg.Profiles[name] = profile
+		return nil
+	})
+}
+
+// SetProfileDefault sets (or, with an empty value, removes) a default of a profile
+func (m *Manager) SetProfileDefault(name, key, value string) error {
+	return m.update(func(cfg *Config) error {
+		profile, exists := cfg.Profiles[name]
+		if !exists {
+			return fmt.Errorf("profile '%s' not found", name)
+		}
+
+		if value == "" {
+			delete(profile.Defaults, key)
+		} else {
+			if profile.Defaults == nil {
+				profile.Defaults = map[string]string{}
+			}
+			profile.Defaults[key] = value
+		}
+		cfg.Profiles[name] = profile
+		return nil
+	})
+}
+
+// ProfileDefaults returns the defaults of a profile
+func (m *Manager) ProfileDefaults(name string) (map[string]string, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	profile, exists := cfg.Profiles[name]
 	if !exists {
-		return "", nil
+		return nil, fmt.Errorf("profile '%s' not found", name)
+	}
+	return profile.Defaults, nil
+}
+
+// GetActiveDefaults returns the defaults of the active profile (may be empty)
+func (m *Manager) GetActiveDefaults() (map[string]string, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Profiles[m.activeProfile(cfg)].Defaults, nil
+}
+
+// GetActivePolicy returns the policy file path of the active profile (may be empty)
+func (m *Manager) GetActivePolicy() (string, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.Profiles[m.activeProfile(cfg)].Policy, nil
+}
+
+// SetBookmark adds or replaces a bookmark of a profile
+func (m *Manager) SetBookmark(profileName, name, id string) error {
+	return m.update(func(cfg *Config) error {
+		profile, exists := cfg.Profiles[profileName]
+		if !exists {
+			return fmt.Errorf("profile '%s' not found", profileName)
+		}
+
+		if profile.Bookmarks == nil {
+			profile.Bookmarks = map[string]string{}
+		}
+		profile.Bookmarks[name] = id
+		cfg.Profiles[profileName] = profile
+		return nil
+	})
+}
+
+// RemoveBookmark removes a bookmark of a profile
+func (m *Manager) RemoveBookmark(profileName, name string) error {
+	return m.update(func(cfg *Config) error {
+		profile, exists := cfg.Profiles[profileName]
+		if !exists {
+			return fmt.Errorf("profile '%s' not found", profileName)
+		}
+		if _, exists := profile.Bookmarks[name]; !exists {
+			return fmt.Errorf("bookmark '%s' not found", name)
+		}
+
+		delete(profile.Bookmarks, name)
+		cfg.Profiles[profileName] = profile
+		return nil
+	})
+}
+
+// Bookmarks returns the bookmarks of a profile
+func (m *Manager) Bookmarks(profileName string) (map[string]string, error) {
+	cfg, err := m.Load()
+	if err != nil {
+		return nil, err
 	}
 
-	return profile.APIKey, nil
+	profile, exists := cfg.Profiles[profileName]
+	if !exists {
+		return nil, fmt.Errorf("profile '%s' not found", profileName)
+	}
+	return profile.Bookmarks, nil
+}
+
+// SetAlias adds or replaces an alias
+func (m *Manager) SetAlias(name, expansion string) error {
+	return m.update(func(cfg *Config) error {
+		if _, exists := cfg.Macros[name]; exists {
+			return fmt.Errorf("'%s' is already a macro", name)
+		}
+		if cfg.Aliases == nil {
+			cfg.Aliases = map[string]string{}
+		}
+		cfg.Aliases[name] = expansion
+		return nil
+	})
+}
+
+// RemoveAlias removes an alias
+func (m *Manager) RemoveAlias(name string) error {
+	return m.update(func(cfg *Config) error {
+		if _, exists := cfg.Aliases[name]; !exists {
+			return fmt.Errorf("alias '%s' not found", name)
+		}
+		delete(cfg.Aliases, name)
+		return nil
+	})
 }
 
 // Reset clears the configuration
@@ -0,0 +1,457 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CurrentVersion is the config file format written by this build. Bump it
+// together with a new entry in migrations whenever the format changes.
+const CurrentVersion = 1
+
+// migrations upgrade a decoded config file one version at a time:
+// migrations[i] turns version i into version i+1.
+var migrations = []func(raw map[string]interface{}) error{
+	// 0 → 1: files written before the version field existed. The layout
+	// is unchanged; an empty default_format meant json.
+	func(raw map[string]interface{}) error {
+		if f, _ := raw["default_format"].(string); f == "" {
+			raw["default_format"] = "json"
+		}
+		return nil
+	},
+}
+
+// Lock timing. Holders refresh their lock, so one older than lockStale is
+// left over from a crashed process and is taken over.
+var (
+	lockWait  = 5 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// decodeConfig parses a config file of any supported version, migrating it
+// in memory. It returns the version the file was written in.
+func decodeConfig(data []byte) (*Config, int, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+	from, err := rawVersion(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	for v := from; v < CurrentVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, from, fmt.Errorf("failed to migrate config file from version %d: %w", v, err)
+		}
+	}
+	raw["version"] = CurrentVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, from, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return nil, from, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if cfg.DefaultFormat == "" {
+		cfg.DefaultFormat = "json"
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]Profile)
+	}
+	return &cfg, from, nil
+}
+
+func rawVersion(raw map[string]interface{}) (int, error) {
+	v, ok := raw["version"]
+	if !ok {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return 0, fmt.Errorf("invalid config file version %v", v)
+	}
+	if int(f) > CurrentVersion {
+		return 0, fmt.Errorf("config file version %d is newer than this craft supports (%d); run 'craft upgrade'", int(f), CurrentVersion)
+	}
+	return int(f), nil
+}
+
+// read loads the config file, migrated in memory, and the version it was
+// written in. A missing file yields the default config.
+func (m *Manager) read() (*Config, int, []byte, error) {
+	data, err := os.ReadFile(m.configPath)
+	if os.IsNotExist(err) {
+		return &Config{
+			Version:       CurrentVersion,
+			DefaultFormat: "json",
+			Profiles:      make(map[string]Profile),
+		}, CurrentVersion, nil, nil
+	}
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg, from, err := decodeConfig(data)
+	return cfg, from, data, err
+}
+
+// upgrade reads the config file and, if it is an older version, rewrites it
+// in the current one, keeping the original as config.json.v<N>.bak. The
+// caller holds the lock.
+func (m *Manager) upgrade() (*Config, error) {
+	cfg, from, original, err := m.read()
+	if err != nil || from == CurrentVersion {
+		return cfg, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", m.configPath, from)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := WriteFileAtomic(backup, original); err != nil {
+			return nil, fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+	if err := m.write(cfg); err != nil {
+		return nil, fmt.Errorf("failed to upgrade config file: %w", err)
+	}
+	return cfg, nil
+}
+
+// update applies fn to the config and saves it, holding the lock
+// throughout so concurrent processes cannot lose each other's changes.
+func (m *Manager) update(fn func(cfg *Config) error) error {
+	return m.withLock(func() error {
+		cfg, err := m.upgrade()
+		if err != nil {
+			return err
+		}
+		if err := fn(cfg); err != nil {
+			return err
+		}
+		return m.write(cfg)
+	})
+}
+
+// write saves cfg in the current version. The caller holds the lock.
+func (m *Manager) write(cfg *Config) error {
+	cfg.Version = CurrentVersion
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := WriteFileAtomic(m.configPath, data); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic replaces path with data (mode 0600) through a temporary
+// file and a rename, so readers never see a partial file.
+func WriteFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// migrateLegacyDir copies the files in legacyDir into xdgDir when xdgDir
+// has no config file yet and legacyDir does. Secrets encrypted with this
+// machine's key are re-encrypted, since the key depends on the directory.
+// The config file is copied last, so an interrupted copy is retried.
+func migrateLegacyDir(legacyDir, xdgDir string) error {
+	legacyPath := filepath.Join(legacyDir, ConfigFileName)
+	if _, err := os.Stat(filepath.Join(xdgDir, ConfigFileName)); !os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(legacyPath); err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || name == ConfigFileName || strings.HasSuffix(name, ".lock") || strings.HasSuffix(name, ".stale") || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(xdgDir, name)); err == nil {
+			continue
+		}
+		if name == SecretsFileName {
+			from := &Manager{configDir: legacyDir}
+			to := &Manager{configDir: xdgDir}
+			src := &fileSecretStore{path: filepath.Join(legacyDir, name), keySource: from.osKeyMaterial}
+			dst := &fileSecretStore{path: filepath.Join(xdgDir, name), keySource: to.osKeyMaterial}
+			if err := src.copyTo(dst); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := copyFile(filepath.Join(legacyDir, name), filepath.Join(xdgDir, name)); err != nil {
+			return err
+		}
+	}
+	return copyFile(legacyPath, filepath.Join(xdgDir, ConfigFileName))
+}
+
+func copyFile(from, to string) error {
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(to, data)
+}
+
+// withLock runs fn while holding config.json.lock.
+func (m *Manager) withLock(fn func() error) error {
+	if err := os.MkdirAll(m.configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return WithFileLock(m.configPath, fn)
+}
+
+// WithFileLock runs fn while holding path+".lock", so craft processes
+// updating the same file take turns. The lock is a file created
+// exclusively, which works the same on every platform. While fn runs the
+// lock's mtime is refreshed so it is never judged stale.
+func WithFileLock(path string, fn func() error) error {
+	lock := path + ".lock"
+	// The time makes each lock's content unique, see releaseLock.
+	token := []byte(fmt.Sprintf("%d %d\n", os.Getpid(), time.Now().UnixNano()))
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, werr := f.Write(token)
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				os.Remove(lock)
+				return fmt.Errorf("failed to lock %s: %w", path, werr)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if takeOverLock(lock) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s is locked by another craft process (remove %s if none is running)", path, lock)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(lockStale / 3)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(lock, now, now)
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		releaseLock(lock, token)
+	}()
+	return fn()
+}
+
+// takeOverLock removes lock if it is older than lockStale and reports
+// whether it did.
+func takeOverLock(lock string) bool {
+	info, err := os.Stat(lock)
+	if err != nil || time.Since(info.ModTime()) <= lockStale {
+		return false
+	}
+	content, err := os.ReadFile(lock)
+	if err != nil {
+		return false
+	}
+	return releaseLock(lock, content)
+}
+
+// releaseLock removes lock if it still holds content and reports whether
+// it did. The lock is renamed aside first, which only one process can do;
+// if what was renamed is not the expected lock (another process replaced
+// it in between), it is put back.
+func releaseLock(lock string, content []byte) bool {
+	aside := fmt.Sprintf("%s.%d-%d.stale", lock, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(lock, aside); err != nil {
+		return false
+	}
+	defer os.Remove(aside)
+	if moved, err := os.ReadFile(aside); err != nil || !bytes.Equal(moved, content) {
+		// Link fails if yet another lock was created meanwhile.
+		os.Link(aside, lock)
+		return false
+	}
+	return true
+}
+
+// ValidationReport is the result of Manager.Validate.
+type ValidationReport struct {
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Version int    `json:"version"`
+	// Issues are unknown keys and invalid values, as "path: problem".
+	Issues []string `json:"issues"`
+}
+
+// Validate checks the config file for unknown keys and invalid values
+// without changing it. Values that only the CLI can judge, such as output
+// formats and profile defaults, are left to the caller; the returned config
+// (nil if the file cannot be parsed) lets it check them.
+func (m *Manager) Validate() (*ValidationReport, *Config, error) {
+	report := &ValidationReport{Path: m.configPath, Version: CurrentVersion, Issues: []string{}}
+	data, err := os.ReadFile(m.configPath)
+	if os.IsNotExist(err) {
+		return report, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	report.Exists = true
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("file: %v", err))
+		return report, nil, nil
+	}
+	report.Version, _ = rawVersion(raw)
+	cfg, _, err := decodeConfig(data)
+	if err != nil {
+		report.Issues = append(report.Issues, err.Error())
+		return report, nil, nil
+	}
+	report.Issues = append(report.Issues, unknownKeys(raw, reflect.TypeOf(Config{}), "")...)
+
+	if cfg.ActiveProfile != "" {
+		if _, ok := cfg.Profiles[cfg.ActiveProfile]; !ok {
+			report.Issues = append(report.Issues, fmt.Sprintf("active_profile: profile %q does not exist", cfg.ActiveProfile))
+		}
+	}
+	if cfg.Secrets != nil {
+		if err := cfg.Secrets.Validate(); err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("secrets: %v", err))
+		}
+	}
+	for _, name := range sortedNames(cfg.Profiles) {
+		p := cfg.Profiles[name]
+		at := "profiles." + name
+		if p.URL == "" && p.URLRef == "" {
+			report.Issues = append(report.Issues, at+": url is missing")
+		}
+		if p.URL != "" && p.URLRef != "" {
+			report.Issues = append(report.Issues, at+": both url and url_ref are set")
+		}
+		for field, ref := range map[string]string{"url_ref": p.URLRef, "api_key_ref": p.APIKeyRef} {
+			if ref == "" {
+				continue
+			}
+			if backend, _, err := parseSecretRef(ref); err != nil {
+				report.Issues = append(report.Issues, fmt.Sprintf("%s.%s: %v", at, field, err))
+			} else if backend == SecretBackendCommand && (cfg.Secrets == nil || cfg.Secrets.Backend != SecretBackendCommand) {
+				report.Issues = append(report.Issues, fmt.Sprintf("%s.%s: the command secret backend is not configured", at, field))
+			}
+		}
+	}
+	for _, name := range sortedNames(cfg.Macros) {
+		if len(cfg.Macros[name].Steps) == 0 {
+			report.Issues = append(report.Issues, "macros."+name+": steps are missing")
+		}
+	}
+	sort.Strings(report.Issues)
+	return report, cfg, nil
+}
+
+// unknownKeys lists the keys of raw that t, a struct type, has no JSON
+// field for, descending into nested structs and maps of structs.
+func unknownKeys(raw map[string]interface{}, t reflect.Type, path string) []string {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+
+	var issues []string
+	for key, value := range raw {
+		at := strings.TrimPrefix(path+"."+key, ".")
+		ft, ok := fields[key]
+		if !ok {
+			issues = append(issues, at+": unknown key")
+			continue
+		}
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			if obj, ok := value.(map[string]interface{}); ok {
+				issues = append(issues, unknownKeys(obj, ft, at)...)
+			}
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			if obj, ok := value.(map[string]interface{}); ok {
+				for name, v := range obj {
+					if entry, ok := v.(map[string]interface{}); ok {
+						issues = append(issues, unknownKeys(entry, ft.Elem(), at+"."+name)...)
+					}
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadUpgradesOldConfig(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	old := `{"active_profile":"work","profiles":{"work":{"url":"https://example.com/api/v1"}}}`
+	if err := os.WriteFile(mgr.configPath, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.DefaultFormat != "json" || cfg.Profiles["work"].URL != "https://example.com/api/v1" {
+		t.Errorf("migrated config = %+v", cfg)
+	}
+
+	backup, err := os.ReadFile(mgr.configPath + ".v0.bak")
+	if err != nil || string(backup) != old {
+		t.Errorf("backup = %q, %v; want the original file", backup, err)
+	}
+	data, _ := os.ReadFile(mgr.configPath)
+	if !strings.Contains(string(data), `"version": 1`) {
+		t.Errorf("config file was not rewritten:\n%s", data)
+	}
+	if _, err := os.Stat(mgr.configPath + ".lock"); !os.IsNotExist(err) {
+		t.Error("lock file left behind")
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	os.WriteFile(mgr.configPath, []byte(`{"version":99}`), 0600)
+	if _, err := mgr.Load(); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Load() error = %v, want a version error", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	os.WriteFile(mgr.configPath, []byte(`{
+  "version": 1,
+  "active_profile": "gone",
+  "colour": "blue",
+  "profiles": {
+    "work": {"url": "https://example.com/api/v1", "api_kee": "x"},
+    "empty": {}
+  },
+  "macros": {"noop": {"description": "does nothing"}}
+}`), 0600)
+
+	report, cfg, err := mgr.Validate()
+	if err != nil || cfg == nil {
+		t.Fatalf("Validate() = %v, %v", cfg, err)
+	}
+	want := []string{
+		`active_profile: profile "gone" does not exist`,
+		"colour: unknown key",
+		"macros.noop: steps are missing",
+		"profiles.empty: url is missing",
+		"profiles.work.api_kee: unknown key",
+	}
+	if strings.Join(report.Issues, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(report.Issues, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestConcurrentUpdatesKeepEveryChange(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := mgr.AddProfile(fmt.Sprintf("p%d", i), "https://example.com"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != 20 {
+		t.Errorf("got %d profiles, want 20", len(cfg.Profiles))
+	}
+}
+
+func TestStaleLockIsTakenOver(t *testing.T) {
+	mgr := newTestManager(t.TempDir())
+	lock := mgr.configPath + ".lock"
+	os.WriteFile(lock, []byte("1\n"), 0600)
+
+	orig := lockWait
+	lockWait = 50 * time.Millisecond
+	err := mgr.AddProfile("work", "https://example.com")
+	lockWait = orig
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("AddProfile() error = %v, want a lock error", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lock, old, old)
+	if err := mgr.AddProfile("work", "https://example.com"); err != nil {
+		t.Errorf("AddProfile() with a stale lock: %v", err)
+	}
+
+	// Of several processes finding the same stale lock, each gets its turn.
+	os.WriteFile(lock, []byte("1\n"), 0600)
+	os.Chtimes(lock, old, old)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := mgr.AddProfile(fmt.Sprintf("p%d", i), "https://example.com"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if cfg, _ := mgr.Load(); len(cfg.Profiles) != 11 {
+		t.Errorf("got %d profiles, want 11", len(cfg.Profiles))
+	}
+	if leftovers, _ := filepath.Glob(lock + "*"); len(leftovers) != 0 {
+		t.Errorf("lock files left behind: %v", leftovers)
+	}
+}
+
+func TestLockIsKeptFreshAndReleasedOnlyByItsOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	lock := path + ".lock"
+	orig := lockStale
+	lockStale = 60 * time.Millisecond
+	defer func() { lockStale = orig }()
+
+	err := WithFileLock(path, func() error {
+		time.Sleep(3 * lockStale)
+		if takeOverLock(lock) {
+			t.Error("a lock held past lockStale was taken over")
+		}
+		// Another process replaces the lock; its owner must not remove it.
+		os.Remove(lock)
+		return os.WriteFile(lock, []byte("other\n"), 0600)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(lock); err != nil || string(data) != "other\n" {
+		t.Errorf("lock = %q, %v; want the other owner's lock kept", data, err)
+	}
+}
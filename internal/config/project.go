@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the project-local config, found by walking up from the
+// working directory.
+const ProjectFileName = ".craft.yaml"
+
+// ProjectConfig holds per-directory defaults. It deliberately has no API key
+// field: project files are meant to be committed.
+type ProjectConfig struct {
+	Profile string `yaml:"profile"`
+	APIURL  string `yaml:"api_url"`
+	Format  string `yaml:"format"`
+}
+
+// FindProjectConfig returns the nearest ProjectFileName in dir or one of its
+// parents, and its path. It returns a nil config when there is none.
+func FindProjectConfig(dir string) (*ProjectConfig, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if data, err := os.ReadFile(path); err == nil {
+			project, err := parseProjectConfig(data)
+			if err != nil {
+				return nil, path, fmt.Errorf("invalid %s: %w", path, err)
+			}
+			return project, path, nil
+		} else if !os.IsNotExist(err) {
+			return nil, path, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, "", nil
+		}
+		dir = parent
+	}
+}
+
+func parseProjectConfig(data []byte) (*ProjectConfig, error) {
+	var project ProjectConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&project); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &project, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	os.MkdirAll(sub, 0755)
+
+	project, path, err := FindProjectConfig(sub)
+	if err != nil || project != nil {
+		t.Fatalf("no project file: got %+v, %q, %v", project, path, err)
+	}
+
+	os.WriteFile(filepath.Join(root, ProjectFileName), []byte("profile: work\nformat: table\n"), 0644)
+	project, path, err = FindProjectConfig(sub)
+	if err != nil || project == nil || project.Profile != "work" || project.Format != "table" {
+		t.Fatalf("FindProjectConfig() = %+v, %v", project, err)
+	}
+	if path != filepath.Join(root, ProjectFileName) {
+		t.Errorf("path = %q", path)
+	}
+
+	os.WriteFile(filepath.Join(root, "a", ProjectFileName), []byte(""), 0644)
+	if project, _, err := FindProjectConfig(sub); err != nil || project == nil || project.Profile != "" {
+		t.Errorf("nearest (empty) file should win: %+v, %v", project, err)
+	}
+
+	os.WriteFile(filepath.Join(sub, ProjectFileName), []byte("api_key: secret\n"), 0644)
+	if _, _, err := FindProjectConfig(sub); err == nil {
+		t.Error("unknown keys such as api_key should be rejected")
+	}
+}
+
+func TestNewManagerPrefersXDG(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	mgr, _ := NewManager()
+	if mgr.Dir() != filepath.Join(home, ConfigDirName) {
+		t.Errorf("without an XDG dir: Dir() = %q, want the legacy dir", mgr.Dir())
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	os.MkdirAll(filepath.Join(xdg, XDGDirName), 0755)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	mgr, _ = NewManager()
+	if mgr.Path() != filepath.Join(xdg, XDGDirName, ConfigFileName) {
+		t.Errorf("Path() = %q, want the XDG config", mgr.Path())
+	}
+}
+
+func TestNewManagerMigratesLegacyDir(t *testing.T) {
+	t.Setenv(SecretPassphraseEnv, "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	legacy := newTestManager(filepath.Join(home, ConfigDirName))
+	legacy.AddProfileWithKey("work", "https://example.com/api/v1", "pdk_work")
+	if _, err := legacy.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(legacy.Dir(), "audit.jsonl"), []byte("{}\n"), 0600)
+
+	os.MkdirAll(filepath.Join(home, "xdg", XDGDirName), 0755)
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetProfile("work")
+	if key, err := mgr.GetActiveAPIKey(); err != nil || key != "pdk_work" {
+		t.Errorf("migrated API key = %q, %v", key, err)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.Dir(), "audit.jsonl")); err != nil {
+		t.Errorf("audit log not copied: %v", err)
+	}
+
+	// Once the XDG config exists, the legacy one is no longer read.
+	legacy.AddProfile("later", "https://example.com/api/v1")
+	mgr, _ = NewManager()
+	if cfg, _ := mgr.Load(); cfg.Profiles["later"].URL != "" {
+		t.Error("legacy config was copied again")
+	}
+}
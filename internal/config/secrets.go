@@ -0,0 +1,407 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SecretsFileName is the encrypted secret store of the file backend.
+	SecretsFileName = "secrets.enc"
+	// SecretPassphraseEnv supplies the passphrase of the file backend. When
+	// unset, the key is derived from the machine and user instead.
+	SecretPassphraseEnv = "CRAFT_SECRET_PASSPHRASE"
+
+	// Secret backends.
+	SecretBackendFile    = "file"
+	SecretBackendCommand = "command"
+
+	secretsFileVersion = 1
+	secretsKDFRounds   = 210000
+)
+
+// SecretsConfig selects where profile URLs and API keys are stored. Get, Set
+// and Delete are command lines used by the command backend, in which {ref}
+// is replaced by the secret's name. Set receives the secret on stdin.
+type SecretsConfig struct {
+	Backend string `json:"backend"`
+	Get     string `json:"get,omitempty"`
+	Set     string `json:"set,omitempty"`
+	Delete  string `json:"delete,omitempty"`
+}
+
+// Validate checks that the backend is known and fully configured.
+func (sc SecretsConfig) Validate() error {
+	switch sc.Backend {
+	case SecretBackendFile:
+		return nil
+	case SecretBackendCommand:
+		if strings.TrimSpace(sc.Get) == "" || strings.TrimSpace(sc.Set) == "" {
+			return fmt.Errorf("the command secret backend needs get and set commands")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown secret backend %q (use file or command)", sc.Backend)
+	}
+}
+
+// SecretStore stores named secrets.
+type SecretStore interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+}
+
+// secretRef names a secret in a backend, as stored in a profile ("file:work/api_key").
+func secretRef(backend, name string) string {
+	return backend + ":" + name
+}
+
+func parseSecretRef(ref string) (backend, name string, err error) {
+	backend, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+	return backend, name, nil
+}
+
+// secretStore returns the store for backend, configured by sc.
+func (m *Manager) secretStore(backend string, sc *SecretsConfig) (SecretStore, error) {
+	switch backend {
+	case SecretBackendFile:
+		if m.fileSecrets == nil {
+			m.fileSecrets = &fileSecretStore{path: filepath.Join(m.configDir, SecretsFileName), keySource: m.osKeyMaterial}
+		}
+		return m.fileSecrets, nil
+	case SecretBackendCommand:
+		if sc == nil || sc.Backend != SecretBackendCommand {
+			return nil, fmt.Errorf("secret backend 'command' is referenced but not configured")
+		}
+		return &commandSecretStore{get: sc.Get, set: sc.Set, del: sc.Delete}, nil
+	default:
+		return nil, fmt.Errorf("unknown secret backend %q", backend)
+	}
+}
+
+// resolveSecret returns the value of plain, or of ref when set.
+func (m *Manager) resolveSecret(cfg *Config, plain, ref string) (string, error) {
+	if ref == "" {
+		return plain, nil
+	}
+	backend, name, err := parseSecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	store, err := m.secretStore(backend, cfg.Secrets)
+	if err != nil {
+		return "", err
+	}
+	value, err := store.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", ref, err)
+	}
+	return value, nil
+}
+
+// storeSecret saves value under name in the configured backend and returns
+// its reference. An empty value stores nothing.
+func (m *Manager) storeSecret(cfg *Config, name, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	store, err := m.secretStore(cfg.Secrets.Backend, cfg.Secrets)
+	if err != nil {
+		return "", err
+	}
+	if err := store.Set(name, value); err != nil {
+		return "", fmt.Errorf("failed to store secret %s: %w", name, err)
+	}
+	return secretRef(cfg.Secrets.Backend, name), nil
+}
+
+// deleteSecret removes the secret behind ref, if any. Errors are ignored: a
+// secret that cannot be deleted is orphaned, not exposed.
+func (m *Manager) deleteSecret(cfg *Config, ref string) {
+	if ref == "" {
+		return
+	}
+	backend, name, err := parseSecretRef(ref)
+	if err != nil {
+		return
+	}
+	if store, err := m.secretStore(backend, cfg.Secrets); err == nil {
+		store.Delete(name)
+	}
+}
+
+// osKeyMaterial identifies this machine and user, so a secrets file copied
+// elsewhere cannot be decrypted without the passphrase.
+func (m *Manager) osKeyMaterial() string {
+	machine := ""
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(data)) > 0 {
+			machine = string(bytes.TrimSpace(data))
+			break
+		}
+	}
+	if machine == "" {
+		machine, _ = os.Hostname()
+	}
+	uid := ""
+	if u, err := user.Current(); err == nil {
+		uid = u.Uid
+	}
+	return "craft-cli:" + machine + ":" + uid + ":" + m.configDir
+}
+
+// secretsFile is the on-disk form of the file backend: a JSON map of
+// secrets, encrypted with AES-256-GCM under a PBKDF2-derived key.
+type secretsFile struct {
+	Version int    `json:"version"`
+	KeyFrom string `json:"key_from"` // "passphrase" or "os"
+	Rounds  int    `json:"rounds"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// fileSecretStore keeps secrets in an encrypted file. The file is decrypted
+// once and cached for the life of the process.
+type fileSecretStore struct {
+	path      string
+	keySource func() string
+	loaded    bool
+	secrets   map[string]string
+	gcm       cipher.AEAD
+	header    secretsFile
+}
+
+func (s *fileSecretStore) Get(name string) (string, error) {
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	value, ok := s.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in %s", name, s.path)
+	}
+	return value, nil
+}
+
+func (s *fileSecretStore) Set(name, value string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.secrets[name] = value
+	return s.save()
+}
+
+func (s *fileSecretStore) Delete(name string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[name]; !ok {
+		return nil
+	}
+	delete(s.secrets, name)
+	return s.save()
+}
+
+// copyTo writes the secrets in s to dst. A file encrypted with a
+// passphrase is copied as is; one encrypted with the machine key is
+// re-encrypted with dst's key.
+func (s *fileSecretStore) copyTo(dst *fileSecretStore) error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.header); err != nil {
+		return fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if s.header.KeyFrom == "passphrase" {
+		return WriteFileAtomic(dst.path, data)
+	}
+	if err := s.load(); err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	dst.header = secretsFile{Version: secretsFileVersion, KeyFrom: "os", Rounds: s.header.Rounds, Salt: salt}
+	if dst.gcm, err = dst.deriveCipher(dst.keySource(), salt, dst.header.Rounds); err != nil {
+		return err
+	}
+	dst.secrets, dst.loaded = s.secrets, true
+	return dst.save()
+}
+
+// passphrase returns the key material and where it came from.
+func (s *fileSecretStore) passphrase() (string, string) {
+	if p := os.Getenv(SecretPassphraseEnv); p != "" {
+		return p, "passphrase"
+	}
+	return s.keySource(), "os"
+}
+
+func (s *fileSecretStore) deriveCipher(secret string, salt []byte, rounds int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, secret, salt, rounds, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileSecretStore) load() error {
+	if s.loaded {
+		return nil
+	}
+	secret, keyFrom := s.passphrase()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		s.header = secretsFile{Version: secretsFileVersion, KeyFrom: keyFrom, Rounds: secretsKDFRounds, Salt: salt}
+		if s.gcm, err = s.deriveCipher(secret, salt, secretsKDFRounds); err != nil {
+			return err
+		}
+		s.secrets = map[string]string{}
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.header); err != nil {
+		return fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if s.header.Version != secretsFileVersion {
+		return fmt.Errorf("unsupported secrets file version %d", s.header.Version)
+	}
+	// The key source is fixed when the file is created.
+	switch {
+	case s.header.KeyFrom == "passphrase" && keyFrom != "passphrase":
+		return fmt.Errorf("%s is encrypted with a passphrase; set %s", s.path, SecretPassphraseEnv)
+	case s.header.KeyFrom == "os":
+		secret = s.keySource()
+	}
+	if s.gcm, err = s.deriveCipher(secret, s.header.Salt, s.header.Rounds); err != nil {
+		return err
+	}
+	plain, err := s.gcm.Open(nil, s.header.Nonce, s.header.Data, nil)
+	if err != nil {
+		if s.header.KeyFrom == "passphrase" {
+			return fmt.Errorf("failed to decrypt %s: wrong %s", s.path, SecretPassphraseEnv)
+		}
+		return fmt.Errorf("failed to decrypt %s: it was created on another machine or by another user", s.path)
+	}
+	if err := json.Unmarshal(plain, &s.secrets); err != nil {
+		return fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if s.secrets == nil {
+		s.secrets = map[string]string{}
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *fileSecretStore) save() error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	s.header.Nonce = nonce
+	s.header.Data = s.gcm.Seal(nil, nonce, plain, nil)
+
+	data, err := json.MarshalIndent(s.header, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
+
+// commandSecretStore delegates to external commands such as pass or secret-tool.
+type commandSecretStore struct {
+	get, set, del string
+}
+
+func (s *commandSecretStore) Get(name string) (string, error) {
+	out, err := runSecretCommand(s.get, name, "")
+	if err != nil {
+		return "", err
+	}
+	// pass prints the secret on the first line, secret-tool without a newline.
+	line, _, _ := strings.Cut(out, "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// Set writes the value to stdin as is, so stores that keep stdin verbatim
+// (secret-tool, pass insert -m) don't keep a trailing newline.
+func (s *commandSecretStore) Set(name, value string) error {
+	_, err := runSecretCommand(s.set, name, value)
+	return err
+}
+
+func (s *commandSecretStore) Delete(name string) error {
+	if s.del == "" {
+		return nil
+	}
+	_, err := runSecretCommand(s.del, name, "")
+	return err
+}
+
+// runSecretCommand runs a command line without a shell, replacing {ref} in
+// its arguments with name.
+func runSecretCommand(line, name, stdin string) (string, error) {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return "", fmt.Errorf("secret command is not configured")
+	}
+	for i := range args {
+		args[i] = strings.ReplaceAll(args[i], "{ref}", name)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+	return string(out), nil
+}
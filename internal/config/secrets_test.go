@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestManager(dir string) *Manager {
+	return &Manager{configDir: dir, configPath: filepath.Join(dir, ConfigFileName)}
+}
+
+func TestManager_MigrateSecretsToFile(t *testing.T) {
+	t.Setenv(SecretPassphraseEnv, "")
+	tmpDir := t.TempDir()
+	mgr := newTestManager(tmpDir)
+	mgr.AddProfileWithKey("work", "https://connect.craft.do/links/SECRETLINK/api/v1", "pdk_secretkey")
+	mgr.AddProfile("home", "https://connect.craft.do/links/HOMELINK/api/v1")
+
+	moved, err := mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile})
+	if err != nil || moved != 2 {
+		t.Fatalf("MigrateSecrets() = %d, %v; want 2 profiles", moved, err)
+	}
+
+	data, _ := os.ReadFile(mgr.configPath)
+	for _, secret := range []string{"SECRETLINK", "pdk_secretkey", "HOMELINK"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("config.json still contains %s:\n%s", secret, data)
+		}
+	}
+	if info, _ := os.Stat(mgr.configPath); info.Mode().Perm() != 0600 {
+		t.Errorf("config mode = %v, want 0600", info.Mode().Perm())
+	}
+	enc, _ := os.ReadFile(filepath.Join(tmpDir, SecretsFileName))
+	if strings.Contains(string(enc), "SECRETLINK") {
+		t.Error("secrets file is not encrypted")
+	}
+
+	// A fresh manager decrypts the file again.
+	mgr = newTestManager(tmpDir)
+	if url, err := mgr.GetActiveURL(); err != nil || !strings.Contains(url, "SECRETLINK") {
+		t.Errorf("GetActiveURL() = %q, %v", url, err)
+	}
+	if key, err := mgr.GetActiveAPIKey(); err != nil || key != "pdk_secretkey" {
+		t.Errorf("GetActiveAPIKey() = %q, %v", key, err)
+	}
+
+	// Profiles added after the migration stay out of the config too.
+	mgr.AddProfileWithKey("new", "https://connect.craft.do/links/NEWLINK/api/v1", "pdk_new")
+	data, _ = os.ReadFile(mgr.configPath)
+	if strings.Contains(string(data), "NEWLINK") || strings.Contains(string(data), "pdk_new") {
+		t.Errorf("new profile stored in plaintext:\n%s", data)
+	}
+	profiles, _ := mgr.ListProfiles()
+	for _, p := range profiles {
+		if !p.Secret || p.URL != "" {
+			t.Errorf("profile %s = %+v, want a secret reference", p.Name, p)
+		}
+	}
+
+	if moved, err := mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile}); err != nil || moved != 0 {
+		t.Errorf("second MigrateSecrets() = %d, %v; want nothing to move", moved, err)
+	}
+}
+
+func TestManager_FileSecretsPassphrase(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv(SecretPassphraseEnv, "correct horse")
+	mgr := newTestManager(tmpDir)
+	mgr.AddProfileWithKey("work", "https://example.com/api", "pdk_key")
+	if _, err := mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile}); err != nil {
+		t.Fatalf("MigrateSecrets() error = %v", err)
+	}
+
+	t.Setenv(SecretPassphraseEnv, "")
+	if _, err := newTestManager(tmpDir).GetActiveAPIKey(); err == nil || !strings.Contains(err.Error(), SecretPassphraseEnv) {
+		t.Errorf("without passphrase: error = %v", err)
+	}
+	t.Setenv(SecretPassphraseEnv, "wrong")
+	if _, err := newTestManager(tmpDir).GetActiveAPIKey(); err == nil {
+		t.Error("wrong passphrase should fail to decrypt")
+	}
+	t.Setenv(SecretPassphraseEnv, "correct horse")
+	if key, err := newTestManager(tmpDir).GetActiveAPIKey(); err != nil || key != "pdk_key" {
+		t.Errorf("GetActiveAPIKey() = %q, %v", key, err)
+	}
+}
+
+func TestManager_CommandSecretBackend(t *testing.T) {
+	t.Setenv(SecretPassphraseEnv, "")
+	tmpDir := t.TempDir()
+	storeDir := filepath.Join(tmpDir, "store")
+	script := filepath.Join(tmpDir, "store.sh")
+	os.WriteFile(script, []byte(`#!/bin/sh
+f="`+storeDir+`/$(echo "$2" | tr / _)"
+case "$1" in
+get) cat "$f" ;;
+set) mkdir -p "`+storeDir+`" && cat > "$f" ;;
+delete) rm -f "$f" ;;
+esac
+`), 0755)
+
+	mgr := newTestManager(tmpDir)
+	mgr.AddProfileWithKey("work", "https://example.com/api", "pdk_key")
+	mgr.MigrateSecrets(SecretsConfig{Backend: SecretBackendFile})
+
+	sc := SecretsConfig{Backend: SecretBackendCommand, Get: script + " get {ref}", Set: script + " set {ref}", Delete: script + " delete {ref}"}
+	if moved, err := mgr.MigrateSecrets(sc); err != nil || moved != 1 {
+		t.Fatalf("MigrateSecrets() = %d, %v", moved, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(storeDir, "work_api_key")); err != nil || string(data) != "pdk_key" {
+		t.Errorf("command store holds %q, %v", data, err)
+	}
+	if key, err := newTestManager(tmpDir).GetActiveAPIKey(); err != nil || key != "pdk_key" {
+		t.Errorf("GetActiveAPIKey() = %q, %v", key, err)
+	}
+	fileStore, _ := newTestManager(tmpDir).secretStore(SecretBackendFile, nil)
+	if _, err := fileStore.Get("work/api_key"); err == nil {
+		t.Error("secret left behind in the file backend")
+	}
+
+	if err := mgr.RemoveProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "work_api_key")); !os.IsNotExist(err) {
+		t.Error("removing the profile should delete its secrets")
+	}
+}
+
+func TestSecretsConfigValidate(t *testing.T) {
+	if err := (SecretsConfig{Backend: "vault"}).Validate(); err == nil {
+		t.Error("unknown backend should be rejected")
+	}
+	if err := (SecretsConfig{Backend: SecretBackendCommand, Get: "pass show {ref}"}).Validate(); err == nil {
+		t.Error("command backend without set should be rejected")
+	}
+}
@@ -0,0 +1,383 @@
+// Package jsonschema generates JSON Schemas from Go types and validates
+// decoded JSON values against them. It covers the subset of JSON Schema
+// (2020-12) needed to describe encoding/json output: objects, arrays,
+// scalars, enums, $ref and anyOf.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema node.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// closed marks an object that allows no properties beyond Properties.
+	// It is written as "additionalProperties": false.
+	closed bool
+}
+
+// Types is a JSON Schema "type" keyword: a single name or a list.
+type Types []string
+
+// MarshalJSON writes a single type as a string and several as an array.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts a type name or a list of names.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// MarshalJSON writes the schema, emitting "additionalProperties": false for
+// closed objects.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.closed {
+		return json.Marshal((*plain)(s))
+	}
+	data, err := json.Marshal((*plain)(s))
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "{}" {
+		return []byte(`{"additionalProperties":false}`), nil
+	}
+	return append(data[:len(data)-1], []byte(`,"additionalProperties":false}`)...), nil
+}
+
+// UnmarshalJSON reads a schema, including "additionalProperties": false.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	var raw struct {
+		plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Schema(raw.plain)
+	switch strings.TrimSpace(string(raw.AdditionalProperties)) {
+	case "":
+	case "false":
+		s.closed = true
+	case "true":
+		s.AdditionalProperties = &Schema{}
+	default:
+		s.AdditionalProperties = &Schema{}
+		if err := json.Unmarshal(raw.AdditionalProperties, s.AdditionalProperties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Closed reports whether the schema forbids unlisted properties.
+func (s *Schema) Closed() bool {
+	return s.closed
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Reflector builds schemas from Go types. Named struct types are emitted
+// once into Defs and referenced as RefPrefix+name.
+type Reflector struct {
+	// RefPrefix is prepended to definition names in $ref values,
+	// e.g. "#/$defs/" or "#/components/schemas/".
+	RefPrefix string
+	// Defs collects the definitions of named struct types.
+	Defs map[string]*Schema
+	// Open leaves struct schemas open to unlisted properties, for payloads
+	// another party may extend.
+	Open bool
+
+	names map[reflect.Type]string
+}
+
+// NewReflector returns a Reflector that references definitions under prefix.
+func NewReflector(prefix string) *Reflector {
+	return &Reflector{RefPrefix: prefix, Defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// Reflect returns the schema for the type of v.
+func (r *Reflector) Reflect(v interface{}) *Schema {
+	return r.ReflectType(reflect.TypeOf(v))
+}
+
+// ReflectType returns the schema for t.
+func (r *Reflector) ReflectType(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Ptr {
+		return r.ReflectType(t.Elem())
+	}
+	if t == timeType {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+		// A nil slice encodes as null
+		return &Schema{Type: Types{"array", "null"}, Items: r.ReflectType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: r.ReflectType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name, ok := r.names[t]
+		if !ok {
+			name = r.defName(t)
+			r.names[t] = name
+			r.Defs[name] = nil // placeholder for recursive types
+			r.Defs[name] = r.structSchema(t)
+		}
+		return &Schema{Ref: r.RefPrefix + name}
+	}
+	return &Schema{}
+}
+
+// defName returns the definition name for a named type, capitalized so
+// unexported request types read naturally in generated documents. Types
+// that share a name with one already defined are qualified by package.
+func (r *Reflector) defName(t reflect.Type) string {
+	name := t.Name()
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if _, taken := r.Defs[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return name
+}
+
+// structSchema describes a struct's JSON fields. Fields without omitempty
+// are required; unless r.Open, no other properties are allowed.
+func (r *Reflector) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}, closed: !r.Open}
+	r.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds t's fields to s, flattening embedded structs as encoding/json does.
+func (r *Reflector) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := r.ReflectType(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+			if f.Type.Kind() == reflect.Ptr {
+				prop = nullable(prop) // a nil pointer encodes as null
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// nullable returns s extended to also accept null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) > 0:
+		for _, t := range s.Type {
+			if t == "null" {
+				return s
+			}
+		}
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
+
+// Validate checks value (as produced by json.Unmarshal into interface{})
+// against schema. defs resolves $ref values by their last path segment.
+func Validate(schema *Schema, defs map[string]*Schema, value interface{}) error {
+	return validate(schema, defs, value, "$")
+}
+
+func validate(s *Schema, defs map[string]*Schema, v interface{}, path string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		def, ok := defs[name]
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", path, s.Ref)
+		}
+		return validate(def, defs, v, path)
+	}
+
+	if len(s.AnyOf) > 0 {
+		var errs []string
+		for _, alt := range s.AnyOf {
+			err := validate(alt, defs, v, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("%s: matches none of anyOf: %s", path, strings.Join(errs, "; "))
+	}
+
+	if len(s.Type) > 0 {
+		actual := typeOf(v)
+		ok := false
+		for _, want := range s.Type {
+			if want == actual || (want == "number" && actual == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), actual)
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, v, s.Enum)
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if prop, ok := s.Properties[k]; ok {
+				if err := validate(prop, defs, val[k], child); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.closed {
+				return fmt.Errorf("%s: unexpected property", child)
+			}
+			if err := validate(s.AdditionalProperties, defs, val[k], child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			if err := validate(s.Items, defs, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeOf returns the JSON Schema type name of a decoded JSON value.
+func typeOf(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == float64(int64(val)) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if strings.ContainsAny(val.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
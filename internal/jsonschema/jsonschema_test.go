@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Name string `json:"name"`
+}
+
+type sample struct {
+	ID      string                 `json:"id"`
+	Count   int                    `json:"count,omitempty"`
+	Tags    []string               `json:"tags"`
+	When    time.Time              `json:"when"`
+	Child   *inner                 `json:"child,omitempty"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+	Skipped string                 `json:"-"`
+	inner
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestReflectStruct(t *testing.T) {
+	r := NewReflector("#/$defs/")
+	ref := r.Reflect(sample{})
+	if ref.Ref != "#/$defs/Sample" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+	s := r.Defs["Sample"]
+	if got := strings.Join(s.Required, ","); got != "id,name,tags,when" {
+		t.Errorf("required = %s", got)
+	}
+	if _, ok := s.Properties["Skipped"]; ok {
+		t.Error("json:\"-\" field should be omitted")
+	}
+	if s.Properties["when"].Format != "date-time" {
+		t.Errorf("time.Time format = %q", s.Properties["when"].Format)
+	}
+	if r.Defs["Inner"] == nil {
+		t.Error("pointer to named struct should be a definition")
+	}
+
+	data, _ := json.Marshal(s)
+	if !strings.Contains(string(data), `"additionalProperties":false`) {
+		t.Errorf("strict struct should be closed: %s", data)
+	}
+	var back Schema
+	if err := json.Unmarshal(data, &back); err != nil || !back.Closed() {
+		t.Errorf("round trip lost additionalProperties: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	r := NewReflector("#/$defs/")
+	root := r.Reflect(sample{})
+
+	tests := []struct {
+		name, input, wantErr string
+	}{
+		{"valid", `{"id":"a","name":"n","tags":null,"when":"2024-01-01T00:00:00Z","child":{"name":"c"}}`, ""},
+		{"missing required", `{"id":"a","tags":[],"when":"x"}`, `missing required property "name"`},
+		{"wrong type", `{"id":1,"name":"n","tags":[],"when":"x"}`, "$.id: expected string"},
+		{"nested item", `{"id":"a","name":"n","tags":[1],"when":"x"}`, "$.tags[0]"},
+		{"unknown property", `{"id":"a","name":"n","tags":[],"when":"x","bogus":1}`, "$.bogus: unexpected property"},
+		{"open map", `{"id":"a","name":"n","tags":[],"when":"x","extra":{"any":[1,"b"]}}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(root, r.Defs, decode(t, tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAnyOfAndEnum(t *testing.T) {
+	s := &Schema{AnyOf: []*Schema{
+		{Type: Types{"integer"}},
+		{Type: Types{"string"}, Enum: []interface{}{"a", "b"}},
+	}}
+	for _, ok := range []string{`3`, `"a"`} {
+		if err := Validate(s, nil, decode(t, ok)); err != nil {
+			t.Errorf("%s: unexpected error %v", ok, err)
+		}
+	}
+	for _, bad := range []string{`1.5`, `"c"`} {
+		if err := Validate(s, nil, decode(t, bad)); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestOpenReflector(t *testing.T) {
+	r := NewReflector("#/$defs/")
+	r.Open = true
+	root := r.Reflect(inner{})
+	if err := Validate(root, r.Defs, decode(t, `{"name":"n","added":true}`)); err != nil {
+		t.Errorf("open schema rejected extra property: %v", err)
+	}
+}